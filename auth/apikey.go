@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const apiKeyHashPrefix = "sha256:"
+
+// APIKey is a static API key. Only the hash of the key is stored.
+type APIKey struct {
+	ID     string   `json:"id"`
+	Hash   string   `json:"hash"`
+	Scopes []string `json:"scopes"`
+}
+
+// HashAPIKey returns the stored form of a raw API key
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return apiKeyHashPrefix + hex.EncodeToString(sum[:])
+}
+
+// LoadAPIKeys reads a JSON array of API keys from path
+func LoadAPIKeys(path string) ([]APIKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("parsing API keys file: %w", err)
+	}
+
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, fmt.Errorf("API key without an id in %s", path)
+		}
+		if !strings.HasPrefix(key.Hash, apiKeyHashPrefix) {
+			return nil, fmt.Errorf("API key %s: hash must start with %q", key.ID, apiKeyHashPrefix)
+		}
+	}
+
+	return keys, nil
+}
+
+// matchAPIKey returns the key whose hash matches raw
+func matchAPIKey(keys []APIKey, raw string) (APIKey, bool) {
+	hash := []byte(HashAPIKey(raw))
+
+	var match APIKey
+	found := false
+	// Compare against every key so timing does not reveal which one matched
+	for _, key := range keys {
+		if subtle.ConstantTimeCompare(hash, []byte(key.Hash)) == 1 {
+			match = key
+			found = true
+		}
+	}
+
+	return match, found
+}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// signHS256 builds an HS256 token for claims
+func signHS256(t *testing.T, secret string, claims map[string]interface{}) string {
+	header := encodeSegment(t, map[string]string{"alg": "HS256", "typ": "JWT"})
+	payload := encodeSegment(t, claims)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(header + "." + payload))
+
+	return header + "." + payload + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Failed to encode segment: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func TestJWTVerifier_HS256(t *testing.T) {
+	verifier, err := NewJWTVerifier(JWTConfig{Secret: []byte("secret"), Issuer: "ci", Audience: "blog-api"})
+	if err != nil {
+		t.Fatalf("NewJWTVerifier failed: %v", err)
+	}
+
+	exp := time.Now().Add(time.Hour).Unix()
+
+	token := signHS256(t, "secret", map[string]interface{}{
+		"sub": "editor", "iss": "ci", "aud": "blog-api", "exp": exp, "scope": "posts:write preview",
+	})
+	principal, err := verifier.Verify(token)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if principal.ID != "editor" || !principal.HasScope(ScopePostsWrite) || principal.HasScope(ScopeAdmin) {
+		t.Errorf("Unexpected principal %+v", principal)
+	}
+
+	invalid := map[string]string{
+		"wrong secret": signHS256(t, "other", map[string]interface{}{"sub": "editor", "iss": "ci", "aud": "blog-api", "exp": exp}),
+		"expired":      signHS256(t, "secret", map[string]interface{}{"sub": "editor", "iss": "ci", "aud": "blog-api", "exp": time.Now().Add(-time.Hour).Unix()}),
+		"no expiry":    signHS256(t, "secret", map[string]interface{}{"sub": "editor", "iss": "ci", "aud": "blog-api"}),
+		"wrong issuer": signHS256(t, "secret", map[string]interface{}{"sub": "editor", "iss": "other", "aud": "blog-api", "exp": exp}),
+		"wrong aud":    signHS256(t, "secret", map[string]interface{}{"sub": "editor", "iss": "ci", "aud": []string{"other"}, "exp": exp}),
+		"alg none":     encodeSegment(t, map[string]string{"alg": "none"}) + "." + encodeSegment(t, map[string]interface{}{"sub": "editor", "exp": exp}) + ".",
+	}
+	for name, token := range invalid {
+		if _, err := verifier.Verify(token); err == nil {
+			t.Errorf("%s: expected verification to fail", name)
+		}
+	}
+}
+
+func TestJWTVerifier_JWKS(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	jwks := map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "EC",
+			"kid": "k1",
+			"crv": "P-256",
+			"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+			"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+		}},
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	data, _ := json.Marshal(jwks)
+	os.WriteFile(path, data, 0644)
+
+	verifier, err := NewJWTVerifier(JWTConfig{JWKSFile: path})
+	if err != nil {
+		t.Fatalf("NewJWTVerifier failed: %v", err)
+	}
+
+	header := encodeSegment(t, map[string]string{"alg": "ES256", "kid": "k1"})
+	payload := encodeSegment(t, map[string]interface{}{"sub": "ci", "exp": time.Now().Add(time.Hour).Unix(), "scopes": []string{"admin"}})
+	digest := sha256.Sum256([]byte(header + "." + payload))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+	signature := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	token := header + "." + payload + "." + base64.RawURLEncoding.EncodeToString(signature)
+
+	principal, err := verifier.Verify(token)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !principal.HasScope(ScopePostsWrite) {
+		t.Error("admin scope should imply posts:write")
+	}
+
+	// Tampering with the payload invalidates the signature
+	tampered := header + "." + encodeSegment(t, map[string]interface{}{"sub": "attacker", "exp": time.Now().Add(time.Hour).Unix()}) + "." + base64.RawURLEncoding.EncodeToString(signature)
+	if _, err := verifier.Verify(tampered); err == nil {
+		t.Error("Expected tampered token to fail verification")
+	}
+}
+
+func TestAuthenticator_APIKey(t *testing.T) {
+	authenticator := NewAuthenticator([]APIKey{
+		{ID: "editor", Hash: HashAPIKey("s3cret"), Scopes: []string{ScopePostsWrite}},
+	}, nil)
+
+	req := httptest.NewRequest("POST", "/posts", nil)
+	if _, err := authenticator.Authenticate(req); err != ErrNoCredentials {
+		t.Errorf("Expected ErrNoCredentials, got %v", err)
+	}
+
+	req.Header.Set("X-API-Key", "s3cret")
+	principal, err := authenticator.Authenticate(req)
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if principal.ID != "editor" || principal.Type != PrincipalAPIKey {
+		t.Errorf("Unexpected principal %+v", principal)
+	}
+
+	req = httptest.NewRequest("POST", "/posts", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	if _, err := authenticator.Authenticate(req); err == nil {
+		t.Error("Expected an invalid key to be rejected")
+	}
+}
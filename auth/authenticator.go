@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Authenticator resolves request credentials to a Principal. API keys are
+// accepted in the X-API-Key header or as a bearer token; anything in the
+// Authorization header that looks like a JWT is verified as one.
+type Authenticator struct {
+	apiKeys []APIKey
+	jwt     *JWTVerifier
+}
+
+// NewAuthenticator creates an Authenticator. verifier may be nil to disable
+// JWT authentication.
+func NewAuthenticator(apiKeys []APIKey, verifier *JWTVerifier) *Authenticator {
+	return &Authenticator{
+		apiKeys: apiKeys,
+		jwt:     verifier,
+	}
+}
+
+// Authenticate returns the principal for the request's credentials
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return a.authenticateAPIKey(key)
+	}
+
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return nil, ErrNoCredentials
+	}
+
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	if strings.Count(token, ".") == 2 {
+		if a.jwt == nil {
+			return nil, ErrInvalidCredentials
+		}
+		principal, err := a.jwt.Verify(token)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+		}
+		return principal, nil
+	}
+
+	return a.authenticateAPIKey(token)
+}
+
+// authenticateAPIKey looks up a raw API key
+func (a *Authenticator) authenticateAPIKey(raw string) (*Principal, error) {
+	key, ok := matchAPIKey(a.apiKeys, raw)
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+	return &Principal{ID: key.ID, Type: PrincipalAPIKey, Scopes: key.Scopes}, nil
+}
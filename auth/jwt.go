@@ -0,0 +1,333 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+// JWTConfig configures bearer token validation. At least one of Secret or
+// JWKSFile must be set.
+type JWTConfig struct {
+	Secret   []byte
+	JWKSFile string
+	Issuer   string
+	Audience string
+	Leeway   time.Duration
+}
+
+// JWTVerifier validates signed JWTs
+type JWTVerifier struct {
+	secret   []byte
+	keys     map[string]crypto.PublicKey
+	issuer   string
+	audience string
+	leeway   time.Duration
+	now      func() time.Time
+}
+
+// jwtHeader is the decoded JOSE header of a token
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// jwtClaims holds the registered and scope claims read from a token
+type jwtClaims struct {
+	Subject   string          `json:"sub"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *float64        `json:"exp"`
+	NotBefore *float64        `json:"nbf"`
+	Scope     string          `json:"scope"`
+	Scopes    []string        `json:"scopes"`
+}
+
+// jwk is a single JSON Web Key
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// NewJWTVerifier creates a verifier from cfg, loading the JWKS file if set
+func NewJWTVerifier(cfg JWTConfig) (*JWTVerifier, error) {
+	v := &JWTVerifier{
+		secret:   cfg.Secret,
+		keys:     make(map[string]crypto.PublicKey),
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		leeway:   cfg.Leeway,
+		now:      time.Now,
+	}
+
+	if cfg.JWKSFile != "" {
+		if err := v.loadJWKS(cfg.JWKSFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(v.secret) == 0 && len(v.keys) == 0 {
+		return nil, errors.New("JWT verification needs a shared secret or a JWKS file")
+	}
+
+	return v, nil
+}
+
+// loadJWKS reads RSA and EC public keys from a JWKS document
+func (v *JWTVerifier) loadJWKS(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("parsing JWKS file: %w", err)
+	}
+
+	for _, key := range set.Keys {
+		pub, err := key.publicKey()
+		if err != nil {
+			return fmt.Errorf("JWKS key %q: %w", key.Kid, err)
+		}
+		v.keys[key.Kid] = pub
+	}
+
+	return nil
+}
+
+// publicKey converts a JWK to a Go public key
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// Verify checks a token's signature and claims and returns its principal
+func (v *JWTVerifier) Verify(token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("decoding header: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("decoding signature: %w", err)
+	}
+
+	if err := v.verifySignature(header, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("decoding claims: %w", err)
+	}
+
+	if err := v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	scopes := claims.Scopes
+	if claims.Scope != "" {
+		scopes = append(scopes, strings.Fields(claims.Scope)...)
+	}
+
+	return &Principal{ID: claims.Subject, Type: PrincipalJWT, Scopes: scopes}, nil
+}
+
+// verifySignature checks signature over signed using the algorithm named in
+// the header. The "none" algorithm is never accepted.
+func (v *JWTVerifier) verifySignature(header jwtHeader, signed string, signature []byte) error {
+	switch header.Alg {
+	case "HS256", "HS384", "HS512":
+		if len(v.secret) == 0 {
+			return fmt.Errorf("algorithm %s not configured", header.Alg)
+		}
+		mac := hmac.New(hashFunc(header.Alg), v.secret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	case "RS256", "RS384", "RS512", "ES256", "ES384", "ES512":
+		key, ok := v.keys[header.Kid]
+		if !ok {
+			return fmt.Errorf("unknown key id %q", header.Kid)
+		}
+		h := hashFunc(header.Alg)()
+		h.Write([]byte(signed))
+		digest := h.Sum(nil)
+
+		switch pub := key.(type) {
+		case *rsa.PublicKey:
+			if header.Alg[0] != 'R' {
+				return errors.New("key type does not match algorithm")
+			}
+			if err := rsa.VerifyPKCS1v15(pub, cryptoHash(header.Alg), digest, signature); err != nil {
+				return errors.New("invalid signature")
+			}
+		case *ecdsa.PublicKey:
+			if header.Alg[0] != 'E' {
+				return errors.New("key type does not match algorithm")
+			}
+			size := (pub.Curve.Params().BitSize + 7) / 8
+			if len(signature) != 2*size {
+				return errors.New("invalid signature")
+			}
+			r := new(big.Int).SetBytes(signature[:size])
+			s := new(big.Int).SetBytes(signature[size:])
+			if !ecdsa.Verify(pub, digest, r, s) {
+				return errors.New("invalid signature")
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported algorithm %q", header.Alg)
+	}
+}
+
+// validateClaims checks expiry, not-before, issuer and audience
+func (v *JWTVerifier) validateClaims(claims jwtClaims) error {
+	now := v.now()
+
+	if claims.ExpiresAt == nil {
+		return errors.New("token has no expiry")
+	}
+	if now.After(time.Unix(int64(*claims.ExpiresAt), 0).Add(v.leeway)) {
+		return errors.New("token has expired")
+	}
+	if claims.NotBefore != nil && now.Add(v.leeway).Before(time.Unix(int64(*claims.NotBefore), 0)) {
+		return errors.New("token is not valid yet")
+	}
+
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return errors.New("unexpected issuer")
+	}
+
+	if v.audience != "" {
+		var audiences []string
+		var single string
+		if err := json.Unmarshal(claims.Audience, &single); err == nil {
+			audiences = []string{single}
+		} else if err := json.Unmarshal(claims.Audience, &audiences); err != nil {
+			return errors.New("unexpected audience")
+		}
+
+		found := false
+		for _, aud := range audiences {
+			if aud == v.audience {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.New("unexpected audience")
+		}
+	}
+
+	if claims.Subject == "" {
+		return errors.New("token has no subject")
+	}
+
+	return nil
+}
+
+// hashFunc returns the hash constructor for a JWS algorithm name
+func hashFunc(alg string) func() hash.Hash {
+	switch alg[2:] {
+	case "384":
+		return sha512.New384
+	case "512":
+		return sha512.New
+	default:
+		return sha256.New
+	}
+}
+
+// cryptoHash returns the crypto.Hash identifier for a JWS algorithm name
+func cryptoHash(alg string) crypto.Hash {
+	switch alg[2:] {
+	case "384":
+		return crypto.SHA384
+	case "512":
+		return crypto.SHA512
+	default:
+		return crypto.SHA256
+	}
+}
+
+// decodeSegment decodes a base64url JSON token segment into v
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// decodeBigInt decodes a base64url big-endian integer from a JWK
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
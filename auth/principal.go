@@ -0,0 +1,43 @@
+package auth
+
+import "errors"
+
+// Scopes granted to API keys and tokens
+const (
+	ScopePostsWrite = "posts:write"
+	ScopePreview    = "preview"
+	ScopeAdmin      = "admin"
+)
+
+// Principal types
+const (
+	PrincipalAPIKey = "api_key"
+	PrincipalJWT    = "jwt"
+)
+
+var (
+	// ErrNoCredentials is returned when a request carries no credentials
+	ErrNoCredentials = errors.New("no credentials provided")
+
+	// ErrInvalidCredentials is returned when credentials are present but
+	// do not identify a principal
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	ID     string
+	Type   string
+	Scopes []string
+}
+
+// HasScope reports whether the principal was granted scope. The admin scope
+// implies every other scope.
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,451 @@
+// Package comments keeps readers' comments on posts, with a moderation
+// queue, in a JSON file on local disk
+package comments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"blog-api/metrics"
+	"blog-api/netutil"
+	"blog-api/persist"
+)
+
+// Comment statuses
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusSpam     = "spam"
+)
+
+var (
+	// ErrNotFound is returned when no comment exists for an ID
+	ErrNotFound = errors.New("comment not found")
+
+	// ErrInvalid is returned when a comment fails validation
+	ErrInvalid = errors.New("invalid comment")
+)
+
+// Comment is a reader's comment on a post
+type Comment struct {
+	ID       string `json:"id" example:"3f2a9c1d5e7b8a60"`
+	Post     string `json:"post" example:"hello-world"`
+	ParentID string `json:"parent_id,omitempty" example:"9b1c7d2e4f6a8b00"`
+	Author   string `json:"author" example:"Jane Doe"`
+	URL      string `json:"url,omitempty" example:"https://jane.example"`
+	// Body is the comment as written; HTML is its sanitized rendering
+	Body        string    `json:"body" example:"Thanks, **great** post!"`
+	HTML        string    `json:"html" example:"<p>Thanks, <strong>great</strong> post!</p>"`
+	Status      string    `json:"status" example:"approved"`
+	CreatedAt   string    `json:"created_at" example:"2025-06-01T10:00:00Z"`
+	ModeratedAt string    `json:"moderated_at,omitempty" example:"2025-06-01T12:00:00Z"`
+	Replies     []Comment `json:"replies,omitempty"`
+}
+
+// Input is a comment submitted by a reader
+type Input struct {
+	ParentID string `json:"parent_id,omitempty" form:"parent_id" example:"9b1c7d2e4f6a8b00"`
+	Author   string `json:"author" form:"author" example:"Jane Doe"`
+	URL      string `json:"url,omitempty" form:"url" example:"https://jane.example"`
+	Body     string `json:"body" form:"body" example:"Thanks, **great** post!"`
+	// Nickname is a honeypot hidden from people; anything in it marks the
+	// comment as spam
+	Nickname string `json:"nickname,omitempty" form:"nickname" example:""`
+}
+
+// Moderation is a moderator's decision on a comment
+type Moderation struct {
+	Status string `json:"status" example:"approved"`
+}
+
+// Thread is the approved comments on a post, replies nested under the
+// comment they answer
+type Thread struct {
+	Comments []Comment `json:"comments"`
+	Count    int       `json:"count" example:"4"`
+}
+
+// Backup describes a backup written to disk
+type Backup struct {
+	File  string `json:"file" example:"data/backups/comments-20250601T100000Z.json"`
+	Count int    `json:"count" example:"42"`
+}
+
+// StoreConfig configures a Store
+type StoreConfig struct {
+	// File persists the comments; "" keeps them in memory only
+	File string
+	// BackupDir receives copies of the comments made by Backup
+	BackupDir string
+	// BackupInterval is how often Run makes a backup; 0 disables them
+	BackupInterval time.Duration
+	// BackupKeep bounds how many backups are kept, oldest removed first
+	BackupKeep int
+	// MaxLength bounds the length of a comment body, in characters
+	MaxLength int
+}
+
+// Store holds comments and persists every change
+type Store struct {
+	cfg      StoreConfig
+	registry *metrics.Registry
+	now      func() time.Time
+
+	mu       sync.RWMutex
+	comments map[string]*Comment
+}
+
+// NewStore creates a Store and loads any persisted comments
+func NewStore(cfg StoreConfig, registry *metrics.Registry) (*Store, error) {
+	if cfg.MaxLength <= 0 {
+		cfg.MaxLength = 5000
+	}
+	if cfg.BackupKeep <= 0 {
+		cfg.BackupKeep = 7
+	}
+
+	s := &Store{
+		cfg:      cfg,
+		registry: registry,
+		now:      time.Now,
+		comments: make(map[string]*Comment),
+	}
+
+	if cfg.File != "" {
+		var comments []*Comment
+		if err := persist.LoadJSON(cfg.File, &comments); err != nil {
+			return nil, fmt.Errorf("loading comments: %w", err)
+		}
+		for _, comment := range comments {
+			s.comments[comment.ID] = comment
+		}
+	}
+
+	registry.Describe("blog_api_comments_submitted_total", "Comments submitted by readers, by the status they were given")
+
+	return s, nil
+}
+
+// Submit adds a comment on post, pending moderation. A reply must answer an
+// approved comment on the same post, made under its slug or one of its
+// former slugs. A comment caught by the honeypot is kept as spam but
+// reported as pending, so its sender learns nothing.
+func (s *Store) Submit(post string, input Input, formerSlugs ...string) (Comment, error) {
+	input.Author = strings.TrimSpace(input.Author)
+	input.URL = strings.TrimSpace(input.URL)
+	input.Body = strings.TrimSpace(input.Body)
+	if err := s.validate(input); err != nil {
+		return Comment{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if input.ParentID != "" {
+		parent, ok := s.comments[input.ParentID]
+		if !ok || !onPost(parent.Post, post, formerSlugs) || parent.Status != StatusApproved {
+			return Comment{}, fmt.Errorf("%w: no comment %s to reply to on this post", ErrInvalid, input.ParentID)
+		}
+	}
+
+	comment := &Comment{
+		ID:        persist.NewID(),
+		Post:      post,
+		ParentID:  input.ParentID,
+		Author:    input.Author,
+		URL:       input.URL,
+		Body:      input.Body,
+		HTML:      Render(input.Body),
+		Status:    StatusPending,
+		CreatedAt: s.now().UTC().Format(time.RFC3339),
+	}
+	if input.Nickname != "" {
+		comment.Status = StatusSpam
+	}
+
+	s.comments[comment.ID] = comment
+	if err := s.save(); err != nil {
+		delete(s.comments, comment.ID)
+		return Comment{}, err
+	}
+	s.registry.Inc("blog_api_comments_submitted_total", "status", comment.Status)
+
+	submitted := *comment
+	submitted.Status = StatusPending
+	return submitted, nil
+}
+
+// Thread returns the approved comments on a post, oldest first with their
+// replies nested beneath them. A post may be given several slugs, such as
+// its aliases, to gather comments made before it was renamed. Replies to a
+// comment that is no longer approved are left out with it.
+func (s *Store) Thread(posts ...string) Thread {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	onPost := make(map[string]bool, len(posts))
+	for _, post := range posts {
+		onPost[post] = true
+	}
+
+	children := make(map[string][]*Comment)
+	count := 0
+	for _, comment := range s.comments {
+		if onPost[comment.Post] && comment.Status == StatusApproved {
+			children[comment.ParentID] = append(children[comment.ParentID], comment)
+		}
+	}
+
+	var build func(parentID string) []Comment
+	build = func(parentID string) []Comment {
+		siblings := children[parentID]
+		sortOldestFirst(siblings)
+
+		thread := make([]Comment, 0, len(siblings))
+		for _, comment := range siblings {
+			c := *comment
+			c.Replies = build(c.ID)
+			thread = append(thread, c)
+			count++
+		}
+		return thread
+	}
+
+	comments := build("")
+	return Thread{Comments: comments, Count: count}
+}
+
+// List returns the comments with a status, newest first, or every comment
+// when status is empty
+func (s *Store) List(status string) []Comment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matching []*Comment
+	for _, comment := range s.comments {
+		if status == "" || comment.Status == status {
+			matching = append(matching, comment)
+		}
+	}
+	sortOldestFirst(matching)
+
+	list := make([]Comment, 0, len(matching))
+	for i := len(matching) - 1; i >= 0; i-- {
+		list = append(list, *matching[i])
+	}
+	return list
+}
+
+// Get returns a single comment
+func (s *Store) Get(id string) (Comment, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	comment, ok := s.comments[id]
+	if !ok {
+		return Comment{}, false
+	}
+	return *comment, true
+}
+
+// SetStatus moderates a comment, approving it, marking it as spam or
+// returning it to the queue
+func (s *Store) SetStatus(id, status string) (Comment, error) {
+	if !validStatus(status) {
+		return Comment{}, fmt.Errorf("%w: status must be pending, approved or spam", ErrInvalid)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comment, ok := s.comments[id]
+	if !ok {
+		return Comment{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+
+	previous := *comment
+	comment.Status = status
+	comment.ModeratedAt = s.now().UTC().Format(time.RFC3339)
+	if err := s.save(); err != nil {
+		*comment = previous
+		return Comment{}, err
+	}
+	return *comment, nil
+}
+
+// Delete removes a comment along with every reply to it
+func (s *Store) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.comments[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+
+	removed := make(map[string]*Comment)
+	var remove func(id string)
+	remove = func(id string) {
+		removed[id] = s.comments[id]
+		delete(s.comments, id)
+		for _, comment := range s.comments {
+			if comment.ParentID == id {
+				remove(comment.ID)
+			}
+		}
+	}
+	remove(id)
+
+	if err := s.save(); err != nil {
+		for id, comment := range removed {
+			s.comments[id] = comment
+		}
+		return err
+	}
+	return nil
+}
+
+// Export returns every comment, oldest first, in the form they are stored
+// in. An export can be restored by copying it over the comments file.
+func (s *Store) Export() []Comment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lockedSnapshot()
+}
+
+// Backup writes a copy of every comment to a timestamped file in the backup
+// directory, removing the oldest backups beyond the number kept
+func (s *Store) Backup() (Backup, error) {
+	if s.cfg.BackupDir == "" {
+		return Backup{}, errors.New("no backup directory configured")
+	}
+
+	s.mu.RLock()
+	snapshot := s.lockedSnapshot()
+	s.mu.RUnlock()
+
+	name := "comments-" + s.now().UTC().Format("20060102T150405Z") + ".json"
+	path := filepath.Join(s.cfg.BackupDir, name)
+	if err := persist.SaveJSON(path, snapshot); err != nil {
+		return Backup{}, fmt.Errorf("writing comments backup: %w", err)
+	}
+
+	backups, err := filepath.Glob(filepath.Join(s.cfg.BackupDir, "comments-*.json"))
+	if err != nil {
+		return Backup{}, err
+	}
+	sort.Strings(backups)
+	for len(backups) > s.cfg.BackupKeep {
+		if err := os.Remove(backups[0]); err != nil {
+			fmt.Printf("Error removing old comments backup %s: %v\n", backups[0], err)
+		}
+		backups = backups[1:]
+	}
+
+	return Backup{File: path, Count: len(snapshot)}, nil
+}
+
+// Run makes a backup every BackupInterval until ctx is done
+func (s *Store) Run(ctx context.Context) {
+	if s.cfg.BackupInterval <= 0 || s.cfg.BackupDir == "" {
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.BackupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Backup(); err != nil {
+				fmt.Printf("Error backing up comments: %v\n", err)
+			}
+		}
+	}
+}
+
+// validate checks a submitted comment
+func (s *Store) validate(input Input) error {
+	if input.Author == "" {
+		return fmt.Errorf("%w: author is required", ErrInvalid)
+	}
+	if utf8.RuneCountInString(input.Author) > 100 || strings.ContainsAny(input.Author, "\r\n") {
+		return fmt.Errorf("%w: author must be a single line of at most 100 characters", ErrInvalid)
+	}
+	if input.Body == "" {
+		return fmt.Errorf("%w: body is required", ErrInvalid)
+	}
+	if utf8.RuneCountInString(input.Body) > s.cfg.MaxLength {
+		return fmt.Errorf("%w: body must be at most %d characters", ErrInvalid, s.cfg.MaxLength)
+	}
+	if input.URL != "" && !netutil.IsWebURL(input.URL) {
+		return fmt.Errorf("%w: url must be an http or https URL", ErrInvalid)
+	}
+	return nil
+}
+
+// lockedSnapshot copies every comment, oldest first. The caller must hold
+// s.mu.
+func (s *Store) lockedSnapshot() []Comment {
+	all := make([]*Comment, 0, len(s.comments))
+	for _, comment := range s.comments {
+		all = append(all, comment)
+	}
+	sortOldestFirst(all)
+
+	snapshot := make([]Comment, len(all))
+	for i, comment := range all {
+		snapshot[i] = *comment
+	}
+	return snapshot
+}
+
+// save persists the comments. The caller must hold s.mu.
+func (s *Store) save() error {
+	if s.cfg.File == "" {
+		return nil
+	}
+	if err := persist.SaveJSON(s.cfg.File, s.lockedSnapshot()); err != nil {
+		return fmt.Errorf("saving comments: %w", err)
+	}
+	return nil
+}
+
+// sortOldestFirst orders comments by when they were made, breaking ties by
+// ID so the order is stable
+func sortOldestFirst(comments []*Comment) {
+	sort.Slice(comments, func(i, j int) bool {
+		if comments[i].CreatedAt != comments[j].CreatedAt {
+			return comments[i].CreatedAt < comments[j].CreatedAt
+		}
+		return comments[i].ID < comments[j].ID
+	})
+}
+
+// onPost reports whether a comment made under slug is on the post with
+// slugs post and formerSlugs
+func onPost(slug, post string, formerSlugs []string) bool {
+	if slug == post {
+		return true
+	}
+	for _, former := range formerSlugs {
+		if slug == former {
+			return true
+		}
+	}
+	return false
+}
+
+// validStatus reports whether status is a comment status
+func validStatus(status string) bool {
+	return status == StatusPending || status == StatusApproved || status == StatusSpam
+}
@@ -0,0 +1,134 @@
+package comments
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"blog-api/metrics"
+)
+
+func newTestStore(t *testing.T, cfg StoreConfig) *Store {
+	s, err := NewStore(cfg, metrics.NewRegistry())
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	clock := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	s.now = func() time.Time {
+		clock = clock.Add(time.Minute)
+		return clock
+	}
+	return s
+}
+
+func TestStore_ModerationAndThreads(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "comments.json")
+	s := newTestStore(t, StoreConfig{File: file})
+
+	first, err := s.Submit("hello", Input{Author: "Jane", Body: "First!"})
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if first.Status != StatusPending || first.HTML != "<p>First!</p>\n" {
+		t.Errorf("Expected a pending, rendered comment, got %+v", first)
+	}
+	if thread := s.Thread("hello"); thread.Count != 0 {
+		t.Errorf("Expected pending comments to be hidden, got %+v", thread)
+	}
+
+	// Replies need an approved parent on the same post
+	if _, err := s.Submit("hello", Input{ParentID: first.ID, Author: "Sam", Body: "Hi"}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected a reply to a pending comment to be refused, got %v", err)
+	}
+	if _, err := s.SetStatus(first.ID, StatusApproved); err != nil {
+		t.Fatalf("SetStatus failed: %v", err)
+	}
+	reply, err := s.Submit("hello", Input{ParentID: first.ID, Author: "Sam", Body: "Hi"})
+	if err != nil {
+		t.Fatalf("Submit reply failed: %v", err)
+	}
+	if _, err := s.Submit("other", Input{ParentID: first.ID, Author: "Sam", Body: "Hi"}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected a reply from another post to be refused, got %v", err)
+	}
+	s.SetStatus(reply.ID, StatusApproved)
+	second, _ := s.Submit("old-hello", Input{Author: "Ana", Body: "Before the rename"})
+	s.SetStatus(second.ID, StatusApproved)
+
+	if _, err := s.Submit("hello", Input{ParentID: second.ID, Author: "Sam", Body: "Hi"}, "old-hello"); err != nil {
+		t.Errorf("Expected a reply to a comment made under a former slug, got %v", err)
+	}
+
+	thread := s.Thread("hello", "old-hello")
+	if thread.Count != 3 || len(thread.Comments) != 2 || thread.Comments[0].ID != first.ID || len(thread.Comments[0].Replies) != 1 || thread.Comments[0].Replies[0].ID != reply.ID {
+		t.Errorf("Unexpected thread %+v", thread)
+	}
+
+	// The honeypot files the comment as spam but does not say so
+	trapped, err := s.Submit("hello", Input{Author: "Bot", Body: "Buy now", Nickname: "bot"})
+	if err != nil || trapped.Status != StatusPending {
+		t.Errorf("Expected a trapped comment to look pending, got %+v, %v", trapped, err)
+	}
+	if spam := s.List(StatusSpam); len(spam) != 1 || spam[0].ID != trapped.ID {
+		t.Errorf("Expected the trapped comment in the spam queue, got %+v", spam)
+	}
+
+	// Changes survive a restart
+	reloaded := newTestStore(t, StoreConfig{File: file})
+	if all := reloaded.List(""); len(all) != 5 || all[0].ID != trapped.ID {
+		t.Errorf("Expected five comments newest first after reloading, got %+v", all)
+	}
+
+	if err := reloaded.Delete(first.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, ok := reloaded.Get(reply.ID); ok {
+		t.Error("Expected replies to be deleted with their parent")
+	}
+	if err := reloaded.Delete(first.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestStore_Validation(t *testing.T) {
+	s := newTestStore(t, StoreConfig{MaxLength: 10})
+	for name, input := range map[string]Input{
+		"no author":  {Body: "Hi"},
+		"no body":    {Author: "Jane", Body: "   "},
+		"too long":   {Author: "Jane", Body: "Hello there, world"},
+		"bad url":    {Author: "Jane", Body: "Hi", URL: "javascript:alert(1)"},
+		"multi-line": {Author: "Jane\nDoe", Body: "Hi"},
+	} {
+		if _, err := s.Submit("hello", input); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: expected ErrInvalid, got %v", name, err)
+		}
+	}
+	if _, err := s.SetStatus("missing", "deleted"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected an unknown status to be refused, got %v", err)
+	}
+}
+
+func TestStore_Backup(t *testing.T) {
+	dir := t.TempDir()
+	s := newTestStore(t, StoreConfig{BackupDir: dir, BackupKeep: 2})
+	s.Submit("hello", Input{Author: "Jane", Body: "Hi"})
+
+	var last Backup
+	for i := 0; i < 3; i++ {
+		backup, err := s.Backup()
+		if err != nil {
+			t.Fatalf("Backup failed: %v", err)
+		}
+		last = backup
+	}
+
+	backups, _ := filepath.Glob(filepath.Join(dir, "comments-*.json"))
+	if len(backups) != 2 || backups[1] != last.File || last.Count != 1 {
+		t.Errorf("Expected the two newest backups, got %v (last %+v)", backups, last)
+	}
+
+	restored := newTestStore(t, StoreConfig{File: last.File})
+	if all := restored.List(""); len(all) != 1 || all[0].Body != "Hi" {
+		t.Errorf("Expected a backup to load as a comments file, got %+v", all)
+	}
+}
@@ -0,0 +1,97 @@
+package comments
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+
+	"blog-api/netutil"
+)
+
+// linkRel is set on every link in a comment, so readers' links carry no
+// weight with search engines and cannot reach back to the page
+var linkRel = []byte("nofollow ugc noopener")
+
+// commentMarkdown parses only paragraphs, with emphasis, code spans and
+// links inline. Bare URLs are linked. There is no HTML parser, so any HTML
+// is left as text and escaped.
+var commentMarkdown = goldmark.New(
+	goldmark.WithParser(parser.NewParser(
+		parser.WithBlockParsers(util.Prioritized(parser.NewParagraphParser(), 1000)),
+		parser.WithInlineParsers(
+			util.Prioritized(parser.NewCodeSpanParser(), 100),
+			util.Prioritized(parser.NewLinkParser(), 200),
+			util.Prioritized(parser.NewAutoLinkParser(), 300),
+			util.Prioritized(parser.NewEmphasisParser(), 400),
+		),
+		parser.WithASTTransformers(util.Prioritized(commentLinks{}, 100)),
+	)),
+	goldmark.WithExtensions(extension.Linkify),
+	goldmark.WithRendererOptions(html.WithHardWraps()),
+)
+
+// Render turns a comment body written in a small subset of markdown into
+// HTML. Blank lines separate paragraphs and single newlines become line
+// breaks. **strong**, *emphasis*, `code` and [links](https://...) are
+// supported, and bare http and https URLs are linked. Everything else,
+// including any HTML, is escaped, so the result is safe to show as is.
+func Render(body string) string {
+	var out bytes.Buffer
+	if err := commentMarkdown.Convert([]byte(body), &out); err != nil {
+		return ""
+	}
+	return out.String()
+}
+
+// commentLinks marks every link nofollow. Links to anything but http and
+// https URLs, and images, are replaced by their text.
+type commentLinks struct{}
+
+// Transform implements parser.ASTTransformer
+func (commentLinks) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+
+	var unwrap []ast.Node
+	ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := node.(type) {
+		case *ast.Link:
+			if !netutil.IsWebURL(string(n.Destination)) {
+				unwrap = append(unwrap, n)
+				return ast.WalkContinue, nil
+			}
+			n.SetAttributeString("rel", linkRel)
+		case *ast.AutoLink:
+			if n.AutoLinkType != ast.AutoLinkURL || !netutil.IsWebURL(string(n.URL(source))) {
+				unwrap = append(unwrap, n)
+				return ast.WalkSkipChildren, nil
+			}
+			n.SetAttributeString("rel", linkRel)
+		case *ast.Image:
+			unwrap = append(unwrap, n)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, node := range unwrap {
+		parent := node.Parent()
+		if autoLink, ok := node.(*ast.AutoLink); ok {
+			parent.ReplaceChild(parent, node, ast.NewString(autoLink.Label(source)))
+			continue
+		}
+		for child := node.FirstChild(); child != nil; {
+			next := child.NextSibling()
+			parent.InsertBefore(parent, node, child)
+			child = next
+		}
+		parent.RemoveChild(parent, node)
+	}
+}
@@ -0,0 +1,29 @@
+package comments
+
+import "testing"
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		body, want string
+	}{
+		{"Hello", "<p>Hello</p>\n"},
+		{"One\ntwo\n\nThree", "<p>One<br>\ntwo</p>\n<p>Three</p>\n"},
+		{"**bold** and *em*", "<p><strong>bold</strong> and <em>em</em></p>\n"},
+		{"Use `<b>*x*</b>`", "<p>Use <code>&lt;b&gt;*x*&lt;/b&gt;</code></p>\n"},
+		{"<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n"},
+		{"[my *site*](https://jane.example/a?b=1&c=2)", `<p><a href="https://jane.example/a?b=1&amp;c=2" rel="nofollow ugc noopener">my <em>site</em></a></p>` + "\n"},
+		{"[bad](javascript:alert(1))", "<p>bad</p>\n"},
+		{`[x](https://a.example/"onmouseover=")`, `<p><a href="https://a.example/%22onmouseover=%22" rel="nofollow ugc noopener">x</a></p>` + "\n"},
+		{"![tracker](https://a.example/t.gif)", "<p>tracker</p>\n"},
+		{"<https://a.example/> or <jane@a.example>", `<p><a href="https://a.example/" rel="nofollow ugc noopener">https://a.example/</a> or jane@a.example</p>` + "\n"},
+		{"# Not a heading\n> nor a quote", "<p># Not a heading<br>\n&gt; nor a quote</p>\n"},
+		{"See https://example.com/post.", `<p>See <a href="https://example.com/post" rel="nofollow ugc noopener">https://example.com/post</a>.</p>` + "\n"},
+		{"2 * 3 * 4", "<p>2 * 3 * 4</p>\n"},
+		{"unmatched ` tick", "<p>unmatched ` tick</p>\n"},
+	}
+	for _, tt := range tests {
+		if got := Render(tt.body); got != tt.want {
+			t.Errorf("Render(%q):\nexpected %q\n     got %q", tt.body, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds runtime settings read from the environment
+type Config struct {
+	Port           string
+	PostsDir       string
+	Layout         LayoutConfig
+	Timezone       string
+	Languages      []string
+	Authors        AuthorsConfig
+	ContentSource  string // "disk", "embedded", "overlay" or "git"
+	Git            GitConfig
+	Auth           AuthConfig
+	RateLimit      RateLimitConfig
+	Hooks          HooksConfig
+	Webhooks       WebhooksConfig
+	WebSub         WebSubConfig
+	Comments       CommentsConfig
+	Webmention     WebmentionConfig
+	Robots         RobotsConfig
+	Related        RelatedConfig
+	Reading        ReadingConfig
+	ExcerptLength  int
+	HighlightTheme string
+	Images         ImagesConfig
+	RedirectsFile  string
+}
+
+// GitConfig holds settings for the git content source
+type GitConfig struct {
+	RepoPath     string
+	PostsDir     string
+	Branch       string
+	Remote       string
+	SyncInterval time.Duration
+}
+
+// LayoutConfig holds settings for finding posts in the posts directory
+type LayoutConfig struct {
+	SlugSources []string
+	Ignore      []string
+}
+
+// AuthorsConfig holds settings for author profiles
+type AuthorsConfig struct {
+	// File is a JSON list of author profiles; empty means none
+	File string
+	// Default is the author of posts that name none
+	Default string
+}
+
+// AuthConfig holds authentication settings
+type AuthConfig struct {
+	APIKeysFile string
+	JWTSecret   string
+	JWKSFile    string
+	JWTIssuer   string
+	JWTAudience string
+	JWTLeeway   time.Duration
+}
+
+// HooksConfig holds incoming webhook settings
+type HooksConfig struct {
+	Secret   string
+	Debounce time.Duration
+}
+
+// WebhooksConfig holds outgoing webhook settings
+type WebhooksConfig struct {
+	File           string
+	LogFile        string
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	Timeout        time.Duration
+}
+
+// WebSubConfig holds WebSub hub settings
+type WebSubConfig struct {
+	Enabled      bool
+	StateFile    string
+	DefaultLease time.Duration
+	MaxLease     time.Duration
+	MaxPending   int
+	AllowPrivate bool
+}
+
+// CommentsConfig holds comment settings
+type CommentsConfig struct {
+	Enabled        bool
+	File           string
+	BackupDir      string
+	BackupInterval time.Duration
+	BackupKeep     int
+	MaxLength      int
+	PerMinute      int
+	Burst          int
+}
+
+// WebmentionConfig holds Webmention settings
+type WebmentionConfig struct {
+	Enabled      bool
+	File         string
+	Send         bool
+	Timeout      time.Duration
+	MaxPending   int
+	RejectedTTL  time.Duration
+	AllowPrivate bool
+}
+
+// RobotsConfig holds robots.txt settings
+type RobotsConfig struct {
+	File     string
+	Disallow []string
+}
+
+// ImagesConfig holds image resizing settings
+type ImagesConfig struct {
+	Enabled   bool
+	CacheDir  string
+	CacheSize int64
+	Sizes     []int
+}
+
+// RelatedConfig holds the weights used to rank related posts
+type RelatedConfig struct {
+	TagWeight     float64
+	ContentWeight float64
+	RecencyWeight float64
+}
+
+// ReadingConfig holds reading time estimation settings
+type ReadingConfig struct {
+	WordsPerMinute     int
+	CodeWordsPerMinute int
+}
+
+// RateLimitConfig holds rate limiting settings
+type RateLimitConfig struct {
+	Enabled        bool
+	PerMinute      int
+	Burst          int
+	KeyPerMinute   int
+	KeyBurst       int
+	Routes         map[string]RouteLimit
+	TrustedProxies []string
+	MaxClients     int
+}
+
+// RouteLimit is a per-route rate limit
+type RouteLimit struct {
+	PerMinute int
+	Burst     int
+}
+
+// Load reads the configuration from environment variables, falling back to
+// defaults for anything unset
+func Load() Config {
+	return Config{
+		Port:          getEnv("PORT", "8080"),
+		PostsDir:      getEnv("POSTS_DIR", "./posts"),
+		Timezone:      getEnv("SITE_TIMEZONE", "UTC"),
+		Languages:     getList("SITE_LANGUAGES", "en-us"),
+		ContentSource: getEnv("CONTENT_SOURCE", "disk"),
+		Layout: LayoutConfig{
+			SlugSources: getList("POSTS_SLUG_SOURCES", "frontmatter,filename"),
+			Ignore:      getList("POSTS_IGNORE", "_drafts/"),
+		},
+		Authors: AuthorsConfig{
+			File:    os.Getenv("AUTHORS_FILE"),
+			Default: os.Getenv("DEFAULT_AUTHOR"),
+		},
+		Git: GitConfig{
+			RepoPath:     getEnv("GIT_REPO_PATH", "."),
+			PostsDir:     getEnv("GIT_POSTS_DIR", "posts"),
+			Branch:       os.Getenv("GIT_BRANCH"),
+			Remote:       os.Getenv("GIT_REMOTE"),
+			SyncInterval: getDuration("GIT_SYNC_INTERVAL", 5*time.Minute),
+		},
+		Auth: AuthConfig{
+			APIKeysFile: os.Getenv("AUTH_API_KEYS_FILE"),
+			JWTSecret:   os.Getenv("AUTH_JWT_SECRET"),
+			JWKSFile:    os.Getenv("AUTH_JWKS_FILE"),
+			JWTIssuer:   os.Getenv("AUTH_JWT_ISSUER"),
+			JWTAudience: os.Getenv("AUTH_JWT_AUDIENCE"),
+			JWTLeeway:   getDuration("AUTH_JWT_LEEWAY", 30*time.Second),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:        getBool("RATE_LIMIT_ENABLED", true),
+			PerMinute:      getInt("RATE_LIMIT_PER_MINUTE", 60),
+			Burst:          getInt("RATE_LIMIT_BURST", 20),
+			KeyPerMinute:   getInt("RATE_LIMIT_KEY_PER_MINUTE", 600),
+			KeyBurst:       getInt("RATE_LIMIT_KEY_BURST", 100),
+			Routes:         getRouteLimits("RATE_LIMIT_ROUTES", "/rss=10/5,/search=20/10"),
+			TrustedProxies: getList("RATE_LIMIT_TRUSTED_PROXIES", ""),
+			MaxClients:     getInt("RATE_LIMIT_MAX_CLIENTS", 10000),
+		},
+		Hooks: HooksConfig{
+			Secret:   os.Getenv("HOOKS_SECRET"),
+			Debounce: getDuration("HOOKS_DEBOUNCE", 2*time.Second),
+		},
+		Webhooks: WebhooksConfig{
+			File:           os.Getenv("WEBHOOKS_FILE"),
+			LogFile:        getEnv("WEBHOOKS_LOG_FILE", "./data/webhook-deliveries.json"),
+			MaxAttempts:    getInt("WEBHOOKS_MAX_ATTEMPTS", 5),
+			InitialBackoff: getDuration("WEBHOOKS_INITIAL_BACKOFF", 10*time.Second),
+			Timeout:        getDuration("WEBHOOKS_TIMEOUT", 10*time.Second),
+		},
+		WebSub: WebSubConfig{
+			Enabled:      getBool("WEBSUB_ENABLED", false),
+			StateFile:    getEnv("WEBSUB_STATE_FILE", "./data/websub-subscriptions.json"),
+			DefaultLease: getDuration("WEBSUB_DEFAULT_LEASE", 10*24*time.Hour),
+			MaxLease:     getDuration("WEBSUB_MAX_LEASE", 30*24*time.Hour),
+			MaxPending:   getInt("WEBSUB_MAX_PENDING", 32),
+			AllowPrivate: getBool("WEBSUB_ALLOW_PRIVATE", false),
+		},
+		Comments: CommentsConfig{
+			Enabled:        getBool("COMMENTS_ENABLED", false),
+			File:           getEnv("COMMENTS_FILE", "./data/comments.json"),
+			BackupDir:      getEnv("COMMENTS_BACKUP_DIR", "./data/backups"),
+			BackupInterval: getDuration("COMMENTS_BACKUP_INTERVAL", 24*time.Hour),
+			BackupKeep:     getInt("COMMENTS_BACKUP_KEEP", 7),
+			MaxLength:      getInt("COMMENTS_MAX_LENGTH", 5000),
+			PerMinute:      getInt("COMMENTS_PER_MINUTE", 2),
+			Burst:          getInt("COMMENTS_BURST", 5),
+		},
+		Webmention: WebmentionConfig{
+			Enabled:      getBool("WEBMENTION_ENABLED", false),
+			File:         getEnv("WEBMENTION_FILE", "./data/webmentions.json"),
+			Send:         getBool("WEBMENTION_SEND", false),
+			Timeout:      getDuration("WEBMENTION_TIMEOUT", 10*time.Second),
+			MaxPending:   getInt("WEBMENTION_MAX_PENDING", 64),
+			RejectedTTL:  getDuration("WEBMENTION_REJECTED_TTL", 24*time.Hour),
+			AllowPrivate: getBool("WEBMENTION_ALLOW_PRIVATE", false),
+		},
+		Robots: RobotsConfig{
+			File:     os.Getenv("ROBOTS_FILE"),
+			Disallow: getList("ROBOTS_DISALLOW", ""),
+		},
+		Related: RelatedConfig{
+			TagWeight:     getFloat("RELATED_TAG_WEIGHT", 0.5),
+			ContentWeight: getFloat("RELATED_CONTENT_WEIGHT", 0.4),
+			RecencyWeight: getFloat("RELATED_RECENCY_WEIGHT", 0.1),
+		},
+		ExcerptLength:  getInt("EXCERPT_LENGTH", 200),
+		HighlightTheme: getEnv("HIGHLIGHT_THEME", "github"),
+		RedirectsFile:  os.Getenv("REDIRECTS_FILE"),
+		Images: ImagesConfig{
+			Enabled:   getBool("IMAGES_ENABLED", false),
+			CacheDir:  getEnv("IMAGES_CACHE_DIR", "./data/images"),
+			CacheSize: int64(getInt("IMAGES_CACHE_MB", 512)) << 20,
+			Sizes:     getIntList("IMAGES_SIZES", "320,640,960,1280,1920"),
+		},
+
+		Reading: ReadingConfig{
+			WordsPerMinute:     getInt("READING_WPM", 200),
+			CodeWordsPerMinute: getInt("READING_CODE_WPM", 100),
+		},
+	}
+}
+
+// getEnv returns the value of key, or fallback if it is unset or empty
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getInt returns key parsed as an integer, or fallback if unset or invalid
+func getInt(key string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
+
+// getFloat returns key parsed as a number, or fallback if unset or invalid
+func getFloat(key string, fallback float64) float64 {
+	if value, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return value
+	}
+	return fallback
+}
+
+// getBool returns key parsed as a boolean, or fallback if unset or invalid
+func getBool(key string, fallback bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
+
+// getList returns key, or fallback if unset, split on commas, with empty
+// entries dropped
+func getList(key, fallback string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key, fallback), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getIntList returns key, or fallback if unset, parsed as integers
+// separated by commas. Malformed entries are reported and skipped.
+func getIntList(key, fallback string) []int {
+	var values []int
+	for _, entry := range strings.Split(getEnv(key, fallback), ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		value, err := strconv.Atoi(entry)
+		if err != nil || value <= 0 {
+			fmt.Printf("Ignoring invalid %s entry %q\n", key, entry)
+			continue
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// getRouteLimits parses "route=perMinute/burst" pairs separated by commas.
+// Malformed entries are reported and skipped.
+func getRouteLimits(key, fallback string) map[string]RouteLimit {
+	limits := make(map[string]RouteLimit)
+
+	for _, entry := range strings.Split(getEnv(key, fallback), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		route, spec, ok := strings.Cut(entry, "=")
+		perMinute, burst, ok2 := strings.Cut(spec, "/")
+		pm, err1 := strconv.Atoi(perMinute)
+		b, err2 := strconv.Atoi(burst)
+		if !ok || !ok2 || err1 != nil || err2 != nil {
+			fmt.Printf("Ignoring invalid %s entry %q\n", key, entry)
+			continue
+		}
+
+		limits[route] = RouteLimit{PerMinute: pm, Burst: b}
+	}
+
+	return limits
+}
+
+// getDuration returns key parsed as a duration, or fallback if unset or invalid
+func getDuration(key string, fallback time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,9 @@
+package main
+
+import "embed"
+
+// embeddedContent is the post corpus compiled into the binary, served when
+// CONTENT_SOURCE is "embedded" or "overlay"
+//
+//go:embed posts
+var embeddedContent embed.FS
@@ -24,93 +24,210 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/health": {
+        "/archive": {
             "get": {
-                "description": "Get the health status of the API including uptime and version",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the number of posts in each year and month, newest first. Posts are placed by their date in the site time zone unless tz is given.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "archive"
+                ],
+                "summary": "Get the archive",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "IANA time zone, e.g. Pacific/Auckland",
+                        "name": "tz",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Health check",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.HealthResponse"
+                            "$ref": "#/definitions/models.ArchiveResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/health/live": {
+        "/archive/{year}": {
             "get": {
-                "description": "Check if the API is alive and responding",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the posts dated within a year, newest first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "archive"
+                ],
+                "summary": "Get posts from a year",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Year, e.g. 2025",
+                        "name": "year",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone, e.g. Pacific/Auckland",
+                        "name": "tz",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Liveness check",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.LivenessResponse"
+                            "$ref": "#/definitions/models.PostsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/health/ready": {
+        "/archive/{year}/{month}": {
             "get": {
-                "description": "Check if the API is ready to serve requests",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the posts dated within a month, newest first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "archive"
+                ],
+                "summary": "Get posts from a month",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Year, e.g. 2025",
+                        "name": "year",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Month, 1-12",
+                        "name": "month",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone, e.g. Pacific/Auckland",
+                        "name": "tz",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Readiness check",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ReadinessResponse"
+                            "$ref": "#/definitions/models.PostsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/posts": {
+        "/assets/highlight.css": {
             "get": {
-                "description": "Get a list of all blog posts with metadata only (no content)",
-                "consumes": [
-                    "application/json"
+                "description": "Get the CSS for the class-based syntax highlighting in rendered post HTML. Line numbers and highlighted lines, set with fence attributes such as ` + "`" + `` + "`" + `` + "`" + `go {3-5 linenos}, are styled too.",
+                "produces": [
+                    "text/css"
                 ],
+                "tags": [
+                    "assets"
+                ],
+                "summary": "Syntax highlighting stylesheet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Highlighting theme, e.g. github, monokai or dracula",
+                        "name": "theme",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stylesheet",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/atom": {
+            "get": {
+                "description": "Get an Atom feed of all blog posts, or with ?lang= of the posts in one of the site's languages, with when each was published and last updated. When the WebSub hub is enabled the feed links to it.",
                 "produces": [
-                    "application/json"
+                    "application/atom+xml"
                 ],
                 "tags": [
                     "posts"
                 ],
-                "summary": "Get all blog posts",
+                "summary": "Get Atom feed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "One of the site's languages",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Atom XML feed",
                         "schema": {
-                            "$ref": "#/definitions/models.PostsResponse"
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
@@ -122,24 +239,47 @@ const docTemplate = `{
                 }
             }
         },
-        "/posts/{slug}": {
+        "/authors": {
             "get": {
-                "description": "Get a specific blog post by its slug identifier",
-                "consumes": [
+                "description": "Get every author with a profile in the authors file or a post naming them, with how many posts each wrote",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "authors"
+                ],
+                "summary": "Get all authors",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AuthorListResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/authors/{id}": {
+            "get": {
+                "description": "Get an author's profile and their posts, newest first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "posts"
+                    "authors"
                 ],
-                "summary": "Get a blog post by slug",
+                "summary": "Get an author",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Post slug",
-                        "name": "slug",
+                        "description": "Author ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -148,7 +288,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.BlogPost"
+                            "$ref": "#/definitions/models.AuthorPosts"
                         }
                     },
                     "404": {
@@ -160,94 +300,2613 @@ const docTemplate = `{
                 }
             }
         },
-        "/rss": {
+        "/comments": {
             "get": {
-                "description": "Get an RSS feed of all blog posts",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Get comments with a status, newest first: pending (the default), approved, spam or all. Requires the admin scope.",
                 "produces": [
-                    "application/rss+xml"
+                    "application/json"
                 ],
                 "tags": [
-                    "posts"
+                    "comments"
+                ],
+                "summary": "List comments for moderation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "pending, approved, spam or all",
+                        "name": "status",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get RSS feed",
                 "responses": {
                     "200": {
-                        "description": "RSS XML feed",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/comments.Comment"
+                            }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handlers.HealthResponse": {
-            "type": "object",
-            "properties": {
-                "service": {
-                    "type": "string",
-                    "example": "blog-api"
-                },
-                "status": {
-                    "type": "string",
-                    "example": "healthy"
-                },
-                "timestamp": {
-                    "type": "string",
-                    "example": "2024-01-01T12:00:00Z"
-                },
-                "uptime": {
-                    "type": "string",
-                    "example": "1h23m45s"
-                },
-                "version": {
-                    "type": "string",
-                    "example": "1.0.0"
-                }
-            }
         },
-        "handlers.LivenessResponse": {
-            "type": "object",
-            "properties": {
-                "status": {
-                    "type": "string",
-                    "example": "alive"
-                },
-                "timestamp": {
-                    "type": "string",
-                    "example": "2024-01-01T12:00:00Z"
+        "/comments/backup": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Write a timestamped copy of every comment to the backup directory, removing the oldest backups beyond the number kept. Requires the admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Back up comments",
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/comments.Backup"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "handlers.ReadinessResponse": {
-            "type": "object",
-            "properties": {
-                "status": {
-                    "type": "string",
-                    "example": "ready"
-                },
-                "timestamp": {
-                    "type": "string",
+        "/comments/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Download every comment, in any status, oldest first. The export can be restored by copying it over the comments file. Requires the admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Export comments",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/comments.Comment"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/comments/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a comment along with every reply to it. Requires the admin scope.",
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Delete a comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Set a comment's status to approved, spam or pending. Only approved comments are shown. Requires the admin scope.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Moderate a comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New status",
+                        "name": "moderation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/comments.Moderation"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/comments.Comment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/feed.json": {
+            "get": {
+                "description": "Get a JSON Feed (version 1.1) of all blog posts, or with ?lang= of the posts in one of the site's languages, with their rendered HTML and authors. When the WebSub hub is enabled the feed lists it.",
+                "produces": [
+                    "application/feed+json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Get JSON Feed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "One of the site's languages",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.JSONFeed"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Get the health status of the API including uptime and version",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health/live": {
+            "get": {
+                "description": "Check if the API is alive and responding",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.LivenessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health/ready": {
+            "get": {
+                "description": "Check if the API is ready to serve requests",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReadinessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/hooks/reload": {
+            "post": {
+                "description": "Queue a reload of the content source. The body must be signed with the shared secret, either GitHub-style in X-Hub-Signature-256 or with X-Signature-256 over \"\u003cX-Signature-Timestamp\u003e.\u003cbody\u003e\". Requests arriving while a reload is queued join it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "hooks"
+                ],
+                "summary": "Trigger a content reload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "sha256=\u003chex HMAC of the body\u003e",
+                        "name": "X-Hub-Signature-256",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sha256=\u003chex HMAC of timestamp.body\u003e",
+                        "name": "X-Signature-256",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unix timestamp included in X-Signature-256",
+                        "name": "X-Signature-Timestamp",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReloadJob"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/hooks/reload/{id}": {
+            "get": {
+                "description": "Get the status of a reload queued by POST /hooks/reload",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "hooks"
+                ],
+                "summary": "Get reload status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reload job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReloadJob"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/hub": {
+            "post": {
+                "description": "Subscribe to or unsubscribe from a feed. The hub confirms the request by sending a GET with hub.challenge to the callback, which must echo the challenge. Subscribers then receive the full feed whenever posts change, signed in X-Hub-Signature when a secret was given, until the lease expires. Callbacks on private addresses are refused.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "WebSub hub",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "subscribe or unsubscribe",
+                        "name": "hub.mode",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Feed URL, e.g. https://blog-api.murray.kiwi/rss",
+                        "name": "hub.topic",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Subscriber callback URL",
+                        "name": "hub.callback",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Secret used to sign content deliveries",
+                        "name": "hub.secret",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Requested subscription lifetime in seconds",
+                        "name": "hub.lease_seconds",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted; intent verification follows",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/images/{path}": {
+            "get": {
+                "description": "Get an image from a post's assets, scaled down to the requested width and/or height and encoded as JPEG or PNG. Widths and heights are limited to the configured sizes. JPEG, PNG, GIF and WebP sources are accepted; images are never enlarged. Rendered post HTML offers these in each image's srcset.",
+                "produces": [
+                    "image/jpeg",
+                    "image/png"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Get a resized image",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post slug and asset path, e.g. pi-cluster/rack.jpg",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Width in pixels",
+                        "name": "w",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Height in pixels",
+                        "name": "h",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "contain (default), cover or fill",
+                        "name": "fit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "jpeg or png; defaults to JPEG for JPEG sources, otherwise PNG",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Content hash of the source",
+                        "name": "v",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Image",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/metrics": {
+            "get": {
+                "description": "Get application counters in the Prometheus text exposition format",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "general"
+                ],
+                "summary": "Metrics",
+                "responses": {
+                    "200": {
+                        "description": "Prometheus metrics",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts": {
+            "get": {
+                "description": "Get a list of all blog posts with metadata only (no content)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Get all blog posts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only posts with this tag",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only posts in this section, the directory holding them",
+                        "name": "section",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only posts in this language; en matches every region of English",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PostsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new blog post. If no slug is given one is derived from the title.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Create a blog post",
+                "parameters": [
+                    {
+                        "description": "Post to create",
+                        "name": "post",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PostInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.BlogPost"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{slug}": {
+            "get": {
+                "description": "Get a specific blog post by its slug identifier. A former slug listed in a post's aliases redirects permanently to the post. A post's translation key, which for translations named \u003cslug\u003e.\u003clang\u003e.md is the slug without the language, returns the translation best matching ?lang= or Accept-Language; a translation's own slug always returns that translation unless ?lang= asks for another.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Get a blog post by slug",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post slug or translation key",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language of the translation wanted",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Preferred languages",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BlogPost"
+                        },
+                        "headers": {
+                            "Content-Language": {
+                                "type": "string",
+                                "description": "The post's language"
+                            },
+                            "Link": {
+                                "type": "string",
+                                "description": "The post's canonical URL and its translations"
+                            }
+                        }
+                    },
+                    "301": {
+                        "description": "Moved to the post's current slug",
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "The post's current URL"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace a blog post. Fields left out or empty keep their current values, and an empty list clears tags, authors or aliases. A different slug in the body renames the post.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Replace a blog post",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous read",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Replacement post",
+                        "name": "post",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PostInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BlogPost"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a blog post by its slug",
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Delete a blog post",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous read",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update only the given fields of a blog post",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Update a blog post",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous read",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "post",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PostPatch"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BlogPost"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{slug}/assets/{path}": {
+            "get": {
+                "description": "Get an image or other file belonging to a post, looked up first beside the post's index.md, for posts stored as \u003cslug\u003e/index.md, then in the shared assets directory. Supports range requests. Requests carrying the content hash in v, as in the URLs of rendered post HTML, are cacheable forever.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Get a post asset",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Asset path, e.g. photo.jpg",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Content hash",
+                        "name": "v",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Asset",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "206": {
+                        "description": "Part of the asset",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{slug}/comments": {
+            "get": {
+                "description": "Get the approved comments on a post, oldest first, with replies nested under the comment they answer. Comments made under the post's former slugs are included.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Get comments on a post",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/comments.Thread"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Submit a comment, as JSON or a form, to be shown once a moderator approves it. The body may use **strong**, *emphasis*, ` + "`" + `code` + "`" + ` and links; anything else is escaped. Set parent_id to reply to an approved comment. Leave nickname empty: it is a trap for bots.",
+                "consumes": [
+                    "application/json",
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Comment on a post",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Comment",
+                        "name": "comment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/comments.Input"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/comments.Comment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{slug}/mentions": {
+            "get": {
+                "description": "Get the verified Webmentions of a post, oldest first. Mentions of the post's former slugs are included.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webmention"
+                ],
+                "summary": "Get mentions of a post",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/webmention.MentionList"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{slug}/related": {
+            "get": {
+                "description": "Get other posts ranked by shared tags, content similarity and recency",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Get related posts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Maximum number of posts (1-20)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RelatedPostsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/robots.txt": {
+            "get": {
+                "description": "Get crawler rules, including the location of the sitemap",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Get robots.txt",
+                "responses": {
+                    "200": {
+                        "description": "robots.txt",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rss": {
+            "get": {
+                "description": "Get an RSS feed of all blog posts, or with ?lang= of the posts in one of the site's languages. When the WebSub hub is enabled the feed links to it, so readers can subscribe for pushed updates instead of polling.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/rss+xml"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Get RSS feed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "One of the site's languages",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "RSS XML feed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/series": {
+            "get": {
+                "description": "Get every series of posts, named by the series frontmatter field",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Get all series",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SeriesListResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/series/{name}": {
+            "get": {
+                "description": "Get a series with its parts in reading order, by series_order and then date",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Get a series",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Series slug or name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Series"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sitemap.xml": {
+            "get": {
+                "description": "Get a sitemap of the index, every post and every tag page. Past 50,000 URLs or 50MB this is a sitemap index pointing at /sitemaps/{n}.xml. Append .gz for a gzipped copy.",
+                "produces": [
+                    "text/xml"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Get sitemap",
+                "responses": {
+                    "200": {
+                        "description": "Sitemap XML",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sitemaps/{file}": {
+            "get": {
+                "description": "Get one of the sitemap files listed in the sitemap index, e.g. 1.xml or 1.xml.gz",
+                "produces": [
+                    "text/xml"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Get part of a split sitemap",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sitemap file, e.g. 1.xml",
+                        "name": "file",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Sitemap XML",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get outgoing webhook deliveries, newest first. Requires the admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhook deliveries",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/webhook.Delivery"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/deliveries/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get an outgoing webhook delivery and its attempts. Requires the admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get a webhook delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Delivery"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/deliveries/{id}/redeliver": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Queue a new delivery with the same payload as an earlier one. Requires the admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Redeliver a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Delivery"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webmention": {
+            "post": {
+                "description": "Tell the blog that the page at source links to one of its posts at target. The mention is verified in the background by fetching source, and shown on the post once verified. Send it again after source is updated or deleted to have it verified again.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webmention"
+                ],
+                "summary": "Send a Webmention",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "URL of the page that links to target",
+                        "name": "source",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "URL of the post",
+                        "name": "target",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/webmention.Mention"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the mention's status"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webmention/{id}": {
+            "get": {
+                "description": "Get a mention sent to the blog, to see whether it has been verified",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webmention"
+                ],
+                "summary": "Get a Webmention's status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Mention ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/webmention.Mention"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "comments.Backup": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "file": {
+                    "type": "string",
+                    "example": "data/backups/comments-20250601T100000Z.json"
+                }
+            }
+        },
+        "comments.Comment": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "Jane Doe"
+                },
+                "body": {
+                    "description": "Body is the comment as written; HTML is its sanitized rendering",
+                    "type": "string",
+                    "example": "Thanks, **great** post!"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-06-01T10:00:00Z"
+                },
+                "html": {
+                    "type": "string",
+                    "example": "\u003cp\u003eThanks, \u003cstrong\u003egreat\u003c/strong\u003e post!\u003c/p\u003e"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3f2a9c1d5e7b8a60"
+                },
+                "moderated_at": {
+                    "type": "string",
+                    "example": "2025-06-01T12:00:00Z"
+                },
+                "parent_id": {
+                    "type": "string",
+                    "example": "9b1c7d2e4f6a8b00"
+                },
+                "post": {
+                    "type": "string",
+                    "example": "hello-world"
+                },
+                "replies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/comments.Comment"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "approved"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://jane.example"
+                }
+            }
+        },
+        "comments.Input": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "Jane Doe"
+                },
+                "body": {
+                    "type": "string",
+                    "example": "Thanks, **great** post!"
+                },
+                "nickname": {
+                    "description": "Nickname is a honeypot hidden from people; anything in it marks the\ncomment as spam",
+                    "type": "string",
+                    "example": ""
+                },
+                "parent_id": {
+                    "type": "string",
+                    "example": "9b1c7d2e4f6a8b00"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://jane.example"
+                }
+            }
+        },
+        "comments.Moderation": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string",
+                    "example": "approved"
+                }
+            }
+        },
+        "comments.Thread": {
+            "type": "object",
+            "properties": {
+                "comments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/comments.Comment"
+                    }
+                },
+                "count": {
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "handlers.ContentStatus": {
+            "type": "object",
+            "properties": {
+                "revision": {
+                    "type": "string",
+                    "example": "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+                },
+                "synced_at": {
+                    "type": "string",
+                    "example": "2024-01-01T12:00:00Z"
+                }
+            }
+        },
+        "handlers.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "$ref": "#/definitions/handlers.ContentStatus"
+                },
+                "service": {
+                    "type": "string",
+                    "example": "blog-api"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "healthy"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2024-01-01T12:00:00Z"
+                },
+                "uptime": {
+                    "type": "string",
+                    "example": "1h23m45s"
+                },
+                "version": {
+                    "type": "string",
+                    "example": "1.0.0"
+                }
+            }
+        },
+        "handlers.LivenessResponse": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string",
+                    "example": "alive"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2024-01-01T12:00:00Z"
+                }
+            }
+        },
+        "handlers.ReadinessResponse": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string",
+                    "example": "ready"
+                },
+                "timestamp": {
+                    "type": "string",
                     "example": "2024-01-01T12:00:00Z"
                 }
             }
         },
-        "models.BlogPost": {
+        "models.ArchiveMonth": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "month": {
+                    "type": "integer",
+                    "example": 6
+                },
+                "name": {
+                    "type": "string",
+                    "example": "June"
+                }
+            }
+        },
+        "models.ArchiveResponse": {
+            "type": "object",
+            "properties": {
+                "timezone": {
+                    "type": "string",
+                    "example": "Pacific/Auckland"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "years": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ArchiveYear"
+                    }
+                }
+            }
+        },
+        "models.ArchiveYear": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "months": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ArchiveMonth"
+                    }
+                },
+                "year": {
+                    "type": "integer",
+                    "example": 2025
+                }
+            }
+        },
+        "models.Author": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "type": "string",
+                    "example": "https://blog-api.murray.kiwi/avatars/scott.jpg"
+                },
+                "bio": {
+                    "type": "string",
+                    "example": "Writes about Go and home labs."
+                },
+                "id": {
+                    "type": "string",
+                    "example": "scott"
+                },
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuthorLink"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Scott Murray"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://murray.kiwi"
+                }
+            }
+        },
+        "models.AuthorLink": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Mastodon"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://mastodon.nz/@scott"
+                }
+            }
+        },
+        "models.AuthorListResponse": {
+            "type": "object",
+            "properties": {
+                "authors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuthorSummary"
+                    }
+                },
+                "count": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "models.AuthorPosts": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "type": "string",
+                    "example": "https://blog-api.murray.kiwi/avatars/scott.jpg"
+                },
+                "bio": {
+                    "type": "string",
+                    "example": "Writes about Go and home labs."
+                },
+                "count": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "id": {
+                    "type": "string",
+                    "example": "scott"
+                },
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuthorLink"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Scott Murray"
+                },
+                "posts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BlogPostMeta"
+                    }
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://murray.kiwi"
+                }
+            }
+        },
+        "models.AuthorRef": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "scott"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Scott Murray"
+                }
+            }
+        },
+        "models.AuthorSummary": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "type": "string",
+                    "example": "https://blog-api.murray.kiwi/avatars/scott.jpg"
+                },
+                "bio": {
+                    "type": "string",
+                    "example": "Writes about Go and home labs."
+                },
+                "count": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "id": {
+                    "type": "string",
+                    "example": "scott"
+                },
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuthorLink"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Scott Murray"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://murray.kiwi"
+                }
+            }
+        },
+        "models.BlogPost": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "hello-wrold"
+                    ]
+                },
+                "authors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Author"
+                    }
+                },
+                "canonical_url": {
+                    "type": "string",
+                    "example": "https://blog-api.murray.kiwi/posts/hello-world"
+                },
+                "content": {
+                    "type": "string",
+                    "example": "This is the full content of the blog post..."
+                },
+                "contributors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Scott Murray"
+                    ]
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2024-01-01"
+                },
+                "excerpt": {
+                    "type": "string",
+                    "example": "This is a short excerpt..."
+                },
+                "html": {
+                    "type": "string",
+                    "example": "\u003cp\u003eThis is the full content of the blog post...\u003c/p\u003e"
+                },
+                "lang": {
+                    "type": "string",
+                    "example": "en-us"
+                },
+                "next": {
+                    "$ref": "#/definitions/models.PostSummary"
+                },
+                "previous": {
+                    "description": "Navigation, filled in for single-post responses",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.PostSummary"
+                        }
+                    ]
+                },
+                "publish_date": {
+                    "description": "PublishDate is the day of Date, kept for older clients.\n\nDeprecated: use Date.",
+                    "type": "string",
+                    "example": "2024-01-01"
+                },
+                "reading_time": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "section": {
+                    "type": "string",
+                    "example": "2025"
+                },
+                "series": {
+                    "type": "string",
+                    "example": "Kubernetes from Scratch"
+                },
+                "series_context": {
+                    "$ref": "#/definitions/models.SeriesContext"
+                },
+                "series_order": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "hello-world"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "go",
+                        "api",
+                        "blog"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Hello World"
+                },
+                "toc": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TOCEntry"
+                    }
+                },
+                "translation_key": {
+                    "type": "string",
+                    "example": "hello-world"
+                },
+                "translations": {
+                    "description": "Translations lists the post in every language it is written in, for\nhreflang alternates",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Translation"
+                    }
+                },
+                "updated": {
+                    "type": "string",
+                    "example": "2024-01-02T09:30:00Z"
+                },
+                "word_count": {
+                    "type": "integer",
+                    "example": 1250
+                }
+            }
+        },
+        "models.BlogPostMeta": {
+            "type": "object",
+            "properties": {
+                "authors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuthorRef"
+                    }
+                },
+                "canonical_url": {
+                    "type": "string",
+                    "example": "https://blog-api.murray.kiwi/posts/hello-world"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2024-01-01"
+                },
+                "excerpt": {
+                    "type": "string",
+                    "example": "This is a short excerpt..."
+                },
+                "lang": {
+                    "type": "string",
+                    "example": "en-us"
+                },
+                "publish_date": {
+                    "description": "PublishDate is the day of Date, kept for older clients.\n\nDeprecated: use Date.",
+                    "type": "string",
+                    "example": "2024-01-01"
+                },
+                "reading_time": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "section": {
+                    "type": "string",
+                    "example": "2025"
+                },
+                "series": {
+                    "type": "string",
+                    "example": "Kubernetes from Scratch"
+                },
+                "series_order": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "hello-world"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "go",
+                        "api",
+                        "blog"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Hello World"
+                },
+                "updated": {
+                    "type": "string",
+                    "example": "2024-01-02T09:30:00Z"
+                },
+                "word_count": {
+                    "type": "integer",
+                    "example": 1250
+                }
+            }
+        },
+        "models.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Something went wrong"
+                }
+            }
+        },
+        "models.JSONFeed": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "feed_url": {
+                    "type": "string"
+                },
+                "home_page_url": {
+                    "type": "string"
+                },
+                "hubs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.JSONFeedHub"
+                    }
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.JSONFeedItem"
+                    }
+                },
+                "language": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.JSONFeedAuthor": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.JSONFeedHub": {
+            "type": "object",
+            "properties": {
+                "type": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.JSONFeedItem": {
+            "type": "object",
+            "properties": {
+                "authors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.JSONFeedAuthor"
+                    }
+                },
+                "content_html": {
+                    "type": "string"
+                },
+                "content_text": {
+                    "type": "string"
+                },
+                "date_modified": {
+                    "type": "string"
+                },
+                "date_published": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "language": {
+                    "type": "string"
+                },
+                "summary": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PostInput": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "hello-wrold"
+                    ]
+                },
+                "authors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "scott"
+                    ]
+                },
+                "content": {
+                    "type": "string",
+                    "example": "# Hello World"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2024-01-01"
+                },
+                "excerpt": {
+                    "type": "string",
+                    "example": "This is a short excerpt..."
+                },
+                "lang": {
+                    "type": "string",
+                    "example": "mi"
+                },
+                "series": {
+                    "type": "string",
+                    "example": "Kubernetes from Scratch"
+                },
+                "series_order": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "hello-world"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "go",
+                        "api",
+                        "blog"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Hello World"
+                },
+                "translation_key": {
+                    "type": "string",
+                    "example": "hello-world"
+                },
+                "updated": {
+                    "type": "string",
+                    "example": "2024-01-02T09:30:00+13:00"
+                }
+            }
+        },
+        "models.PostPatch": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "hello-wrold"
+                    ]
+                },
+                "authors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "scott"
+                    ]
+                },
+                "content": {
+                    "type": "string",
+                    "example": "# Hello World"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2024-01-01"
+                },
+                "excerpt": {
+                    "type": "string",
+                    "example": "This is a short excerpt..."
+                },
+                "lang": {
+                    "type": "string",
+                    "example": "mi"
+                },
+                "series": {
+                    "type": "string",
+                    "example": "Kubernetes from Scratch"
+                },
+                "series_order": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "go",
+                        "api",
+                        "blog"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Hello World"
+                },
+                "translation_key": {
+                    "type": "string",
+                    "example": "hello-world"
+                },
+                "updated": {
+                    "type": "string",
+                    "example": "2024-01-02T09:30:00+13:00"
+                }
+            }
+        },
+        "models.PostSummary": {
             "type": "object",
             "properties": {
-                "content": {
+                "date": {
                     "type": "string",
-                    "example": "This is the full content of the blog post..."
+                    "example": "2024-01-01"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "hello-world"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Hello World"
+                }
+            }
+        },
+        "models.PostsResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "posts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BlogPostMeta"
+                    }
+                }
+            }
+        },
+        "models.RelatedPost": {
+            "type": "object",
+            "properties": {
+                "authors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuthorRef"
+                    }
+                },
+                "canonical_url": {
+                    "type": "string",
+                    "example": "https://blog-api.murray.kiwi/posts/hello-world"
                 },
                 "date": {
                     "type": "string",
@@ -257,9 +2916,34 @@ const docTemplate = `{
                     "type": "string",
                     "example": "This is a short excerpt..."
                 },
+                "lang": {
+                    "type": "string",
+                    "example": "en-us"
+                },
                 "publish_date": {
+                    "description": "PublishDate is the day of Date, kept for older clients.\n\nDeprecated: use Date.",
                     "type": "string",
-                    "example": "2024-01-01T12:00:00Z"
+                    "example": "2024-01-01"
+                },
+                "reading_time": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "score": {
+                    "type": "number",
+                    "example": 0.42
+                },
+                "section": {
+                    "type": "string",
+                    "example": "2025"
+                },
+                "series": {
+                    "type": "string",
+                    "example": "Kubernetes from Scratch"
+                },
+                "series_order": {
+                    "type": "integer",
+                    "example": 2
                 },
                 "slug": {
                     "type": "string",
@@ -279,38 +2963,154 @@ const docTemplate = `{
                 "title": {
                     "type": "string",
                     "example": "Hello World"
+                },
+                "updated": {
+                    "type": "string",
+                    "example": "2024-01-02T09:30:00Z"
+                },
+                "word_count": {
+                    "type": "integer",
+                    "example": 1250
                 }
             }
         },
-        "models.BlogPostMeta": {
+        "models.RelatedPostsResponse": {
             "type": "object",
             "properties": {
-                "date": {
+                "count": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "posts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RelatedPost"
+                    }
+                }
+            }
+        },
+        "models.ReloadJob": {
+            "type": "object",
+            "properties": {
+                "changed": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "created_at": {
                     "type": "string",
-                    "example": "2024-01-01"
+                    "example": "2024-01-01T12:00:00Z"
                 },
-                "excerpt": {
+                "error": {
                     "type": "string",
-                    "example": "This is a short excerpt..."
+                    "example": "fetching origin: authentication required"
                 },
-                "publish_date": {
+                "finished_at": {
                     "type": "string",
-                    "example": "2024-01-01T12:00:00Z"
+                    "example": "2024-01-01T12:00:03Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3f2a9c1e8b7d4a6f"
+                },
+                "requests": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "started_at": {
+                    "type": "string",
+                    "example": "2024-01-01T12:00:02Z"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "succeeded"
+                }
+            }
+        },
+        "models.Series": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Kubernetes from Scratch"
+                },
+                "parts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SeriesPart"
+                    }
                 },
                 "slug": {
                     "type": "string",
-                    "example": "hello-world"
+                    "example": "kubernetes-from-scratch"
+                }
+            }
+        },
+        "models.SeriesContext": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 4
                 },
-                "tags": {
+                "name": {
+                    "type": "string",
+                    "example": "Kubernetes from Scratch"
+                },
+                "next": {
+                    "$ref": "#/definitions/models.PostSummary"
+                },
+                "part": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "parts": {
                     "type": "array",
                     "items": {
-                        "type": "string"
-                    },
-                    "example": [
-                        "go",
-                        "api",
-                        "blog"
-                    ]
+                        "$ref": "#/definitions/models.SeriesPart"
+                    }
+                },
+                "previous": {
+                    "$ref": "#/definitions/models.PostSummary"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "kubernetes-from-scratch"
+                }
+            }
+        },
+        "models.SeriesListResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "series": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SeriesSummary"
+                    }
+                }
+            }
+        },
+        "models.SeriesPart": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "example": "2024-01-01"
+                },
+                "part": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "hello-world"
                 },
                 "title": {
                     "type": "string",
@@ -318,30 +3118,189 @@ const docTemplate = `{
                 }
             }
         },
-        "models.ErrorResponse": {
+        "models.SeriesSummary": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Kubernetes from Scratch"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "kubernetes-from-scratch"
+                }
+            }
+        },
+        "models.TOCEntry": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TOCEntry"
+                    }
+                },
+                "id": {
+                    "type": "string",
+                    "example": "getting-started"
+                },
+                "level": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Getting Started"
+                }
+            }
+        },
+        "models.Translation": {
+            "type": "object",
+            "properties": {
+                "lang": {
+                    "type": "string",
+                    "example": "mi"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "hello-world.mi"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://blog-api.murray.kiwi/posts/hello-world.mi"
+                }
+            }
+        },
+        "webhook.Attempt": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "duration_ms": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "status_code": {
+                    "type": "integer"
+                }
+            }
+        },
+        "webhook.Delivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/webhook.Attempt"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "redelivery_of": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "webmention.Mention": {
             "type": "object",
             "properties": {
+                "checked_at": {
+                    "description": "CheckedAt is when the source was last fetched, verified or not",
+                    "type": "string",
+                    "example": "2025-06-01T10:00:02Z"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-06-01T10:00:00Z"
+                },
                 "error": {
                     "type": "string",
-                    "example": "Something went wrong"
+                    "example": ""
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3f2a9c1d5e7b8a60"
+                },
+                "post": {
+                    "type": "string",
+                    "example": "hello-world"
+                },
+                "source": {
+                    "type": "string",
+                    "example": "https://jane.example/replies/1"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "verified"
+                },
+                "target": {
+                    "type": "string",
+                    "example": "https://blog-api.murray.kiwi/posts/hello-world"
+                },
+                "title": {
+                    "description": "Title is the title of the source page, once verified",
+                    "type": "string",
+                    "example": "Re: Hello World"
+                },
+                "verified_at": {
+                    "type": "string",
+                    "example": "2025-06-01T10:00:02Z"
                 }
             }
         },
-        "models.PostsResponse": {
+        "webmention.MentionList": {
             "type": "object",
             "properties": {
                 "count": {
                     "type": "integer",
-                    "example": 5
+                    "example": 2
                 },
-                "posts": {
+                "mentions": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.BlogPostMeta"
+                        "$ref": "#/definitions/webmention.Mention"
                     }
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
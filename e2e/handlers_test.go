@@ -240,7 +240,8 @@ func TestBlogAPIEndpoints(t *testing.T) {
 		// Verify RSS structure
 		rssContent := string(body)
 		assert.Contains(t, rssContent, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>")
-		assert.Contains(t, rssContent, "<rss version=\"2.0\">")
+		// The root also declares namespaces, such as atom for the hub links
+		assert.Contains(t, rssContent, "<rss version=\"2.0\"")
 		assert.Contains(t, rssContent, "<channel>")
 		assert.Contains(t, rssContent, "<title>My Blog</title>")
 		assert.Contains(t, rssContent, "<description>Latest posts from my blog</description>")
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"blog-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ArchiveService is the set of archive operations ArchiveHandler depends on
+type ArchiveService interface {
+	GetArchive(location *time.Location) (models.ArchiveResponse, error)
+	GetPostsInPeriod(year, month int, location *time.Location) ([]models.BlogPost, error)
+	Location() *time.Location
+}
+
+// ArchiveHandler handles HTTP requests for the post archive
+type ArchiveHandler struct {
+	archiveService ArchiveService
+}
+
+// NewArchiveHandler creates a new ArchiveHandler instance
+func NewArchiveHandler(archiveService ArchiveService) *ArchiveHandler {
+	return &ArchiveHandler{
+		archiveService: archiveService,
+	}
+}
+
+// GetArchive returns post counts by year and month
+// @Summary Get the archive
+// @Description Get the number of posts in each year and month, newest first. Posts are placed by their date in the site time zone unless tz is given.
+// @Tags archive
+// @Produce json
+// @Param tz query string false "IANA time zone, e.g. Pacific/Auckland"
+// @Success 200 {object} models.ArchiveResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /archive [get]
+func (ah *ArchiveHandler) GetArchive(c *gin.Context) {
+	location, ok := ah.location(c)
+	if !ok {
+		return
+	}
+
+	archive, err := ah.archiveService.GetArchive(location)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load archive: " + err.Error()})
+		return
+	}
+
+	archive.Timezone = location.String()
+	c.JSON(http.StatusOK, archive)
+}
+
+// GetYear returns the posts from a year
+// @Summary Get posts from a year
+// @Description Get the posts dated within a year, newest first
+// @Tags archive
+// @Produce json
+// @Param year path int true "Year, e.g. 2025"
+// @Param tz query string false "IANA time zone, e.g. Pacific/Auckland"
+// @Success 200 {object} models.PostsResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /archive/{year} [get]
+func (ah *ArchiveHandler) GetYear(c *gin.Context) {
+	ah.getPeriod(c)
+}
+
+// GetMonth returns the posts from a month
+// @Summary Get posts from a month
+// @Description Get the posts dated within a month, newest first
+// @Tags archive
+// @Produce json
+// @Param year path int true "Year, e.g. 2025"
+// @Param month path int true "Month, 1-12"
+// @Param tz query string false "IANA time zone, e.g. Pacific/Auckland"
+// @Success 200 {object} models.PostsResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /archive/{year}/{month} [get]
+func (ah *ArchiveHandler) GetMonth(c *gin.Context) {
+	ah.getPeriod(c)
+}
+
+// getPeriod responds with the posts in the year, and month if given, from
+// the request path
+func (ah *ArchiveHandler) getPeriod(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil || year < 1 || year > 9999 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "year must be a number such as 2025"})
+		return
+	}
+
+	month := 0
+	if value := c.Param("month"); value != "" {
+		month, err = strconv.Atoi(value)
+		if err != nil || month < 1 || month > 12 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "month must be between 1 and 12"})
+			return
+		}
+	}
+
+	location, ok := ah.location(c)
+	if !ok {
+		return
+	}
+
+	posts, err := ah.archiveService.GetPostsInPeriod(year, month, location)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load posts: " + err.Error()})
+		return
+	}
+
+	postMetas := make([]models.BlogPostMeta, 0, len(posts))
+	for _, post := range posts {
+		postMetas = append(postMetas, models.NewBlogPostMeta(post))
+	}
+
+	c.JSON(http.StatusOK, models.PostsResponse{
+		Posts: postMetas,
+		Count: len(postMetas),
+	})
+}
+
+// location returns the time zone requested with tz, or the site time zone.
+// It responds with an error and returns false for an unknown zone.
+func (ah *ArchiveHandler) location(c *gin.Context) (*time.Location, bool) {
+	name := c.Query("tz")
+	if name == "" {
+		return ah.archiveService.Location(), true
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown time zone: " + name})
+		return nil, false
+	}
+	return location, true
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+
+	"blog-api/render"
+	"blog-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PostAssets is the set of asset operations AssetsHandler depends on
+type PostAssets interface {
+	GetAsset(slug, name string) (services.Asset, error)
+}
+
+// AssetsHandler serves post assets and the static files used by rendered
+// post content
+type AssetsHandler struct {
+	assets PostAssets
+	theme  string
+}
+
+// NewAssetsHandler creates a new AssetsHandler instance. theme is the
+// highlighting theme served when none is requested.
+func NewAssetsHandler(assets PostAssets, theme string) *AssetsHandler {
+	if theme == "" {
+		theme = render.DefaultTheme
+	}
+	return &AssetsHandler{
+		assets: assets,
+		theme:  theme,
+	}
+}
+
+// GetPostAsset serves a file from a post's bundle directory or the shared
+// assets directory
+// @Summary Get a post asset
+// @Description Get an image or other file belonging to a post, looked up first beside the post's index.md, for posts stored as <slug>/index.md, then in the shared assets directory. Supports range requests. Requests carrying the content hash in v, as in the URLs of rendered post HTML, are cacheable forever.
+// @Tags posts
+// @Produce octet-stream
+// @Param slug path string true "Post slug"
+// @Param path path string true "Asset path, e.g. photo.jpg"
+// @Param v query string false "Content hash"
+// @Success 200 {file} file "Asset"
+// @Success 206 {file} file "Part of the asset"
+// @Failure 404 {object} models.ErrorResponse
+// @Router /posts/{slug}/assets/{path} [get]
+func (ah *AssetsHandler) GetPostAsset(c *gin.Context) {
+	name := strings.TrimPrefix(c.Param("path"), "/")
+
+	asset, err := ah.assets.GetAsset(c.Param("slug"), name)
+	if errors.Is(err, services.ErrPostNotFound) || errors.Is(err, services.ErrAssetNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Asset not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load asset: " + err.Error()})
+		return
+	}
+
+	c.Header("ETag", `"`+asset.Hash+`"`)
+	if c.Query("v") == asset.Hash {
+		c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		c.Header("Cache-Control", "no-cache")
+	}
+	// Assets are user content; keep SVG and HTML from running scripts
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Security-Policy", "default-src 'none'; img-src 'self'; style-src 'unsafe-inline'; sandbox")
+
+	http.ServeContent(c.Writer, c.Request, path.Base(name), asset.ModTime, bytes.NewReader(asset.Content))
+}
+
+// GetHighlightCSS serves the stylesheet for highlighted code
+// @Summary Syntax highlighting stylesheet
+// @Description Get the CSS for the class-based syntax highlighting in rendered post HTML. Line numbers and highlighted lines, set with fence attributes such as ```go {3-5 linenos}, are styled too.
+// @Tags assets
+// @Produce text/css
+// @Param theme query string false "Highlighting theme, e.g. github, monokai or dracula"
+// @Success 200 {string} string "Stylesheet"
+// @Failure 404 {object} models.ErrorResponse
+// @Router /assets/highlight.css [get]
+func (ah *AssetsHandler) GetHighlightCSS(c *gin.Context) {
+	theme := c.DefaultQuery("theme", ah.theme)
+
+	css, err := render.ThemeCSS(theme)
+	if errors.Is(err, render.ErrUnknownTheme) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown theme: " + theme})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate stylesheet"})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Data(http.StatusOK, "text/css; charset=utf-8", css)
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"html"
+	"image"
+	"image/png"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"blog-api/imaging"
+	"blog-api/services"
+	"blog-api/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPostAssets(t *testing.T) {
+	memory := store.NewMemoryStore()
+	memory.Put("trip", []byte("---\ntitle: \"Trip\"\ndate: \"2025-01-01\"\n---\n\n![Summit](./photo.jpg)\n\n![Logo](../assets/logo.png)\n\n![Remote](https://example.com/x.jpg)\n\n![Missing](gone.jpg)\n"))
+	memory.PutAsset("trip/photo.jpg", []byte("0123456789"))
+	memory.PutAsset("assets/logo.png", []byte("\x89PNG\r\n\x1a\n"))
+
+	postService := services.NewPostServiceWithStore(memory)
+	postHandler := NewPostHandler(postService)
+	assetsHandler := NewAssetsHandler(postService, "")
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/posts/:slug", postHandler.GetPostBySlug)
+	r.GET("/posts/:slug/assets/*path", assetsHandler.GetPostAsset)
+
+	w := serve(r, "GET", "/posts/trip", "", nil)
+	var post struct {
+		HTML string `json:"html"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &post); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	photo := regexp.MustCompile(`src="(/posts/trip/assets/photo\.jpg\?v=[0-9a-f]{16})"`).FindStringSubmatch(post.HTML)
+	if photo == nil {
+		t.Fatalf("Expected a content-hashed photo URL in:\n%s", post.HTML)
+	}
+	for _, want := range []string{`src="/posts/trip/assets/logo.png?v=`, `src="https://example.com/x.jpg"`, `src="gone.jpg"`} {
+		if !strings.Contains(post.HTML, want) {
+			t.Errorf("Expected %s in:\n%s", want, post.HTML)
+		}
+	}
+
+	w = serve(r, "GET", photo[1], "", nil)
+	if w.Code != http.StatusOK || w.Body.String() != "0123456789" {
+		t.Fatalf("Expected the photo, got %d %q", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "image/jpeg" {
+		t.Errorf("Expected image/jpeg, got %q", ct)
+	}
+	if cc := w.Header().Get("Cache-Control"); !strings.Contains(cc, "immutable") {
+		t.Errorf("Expected immutable caching for a hashed URL, got %q", cc)
+	}
+
+	w = serve(r, "GET", "/posts/trip/assets/photo.jpg", "", map[string]string{"Range": "bytes=2-4"})
+	if w.Code != http.StatusPartialContent || w.Body.String() != "234" {
+		t.Errorf("Expected bytes 2-4, got %d %q", w.Code, w.Body.String())
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != "no-cache" {
+		t.Errorf("Expected revalidation without a hash, got %q", cc)
+	}
+
+	etag := w.Header().Get("ETag")
+	w = serve(r, "GET", "/posts/trip/assets/photo.jpg", "", map[string]string{"If-None-Match": etag})
+	if w.Code != http.StatusNotModified {
+		t.Errorf("Expected 304 for a matching ETag, got %d", w.Code)
+	}
+
+	for _, path := range []string{"/posts/trip/assets/gone.jpg", "/posts/missing/assets/photo.jpg", "/posts/trip/assets/../trip/photo.jpg"} {
+		if w := serve(r, "GET", path, "", nil); w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404 for %s, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestGetImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 800, 400))
+	var photo bytes.Buffer
+	if err := png.Encode(&photo, img); err != nil {
+		t.Fatalf("Failed to encode test image: %v", err)
+	}
+
+	memory := store.NewMemoryStore()
+	memory.Put("trip", []byte("---\ntitle: \"Trip\"\ndate: \"2025-01-01\"\n---\n\n![Summit](photo.png)\n"))
+	memory.PutAsset("trip/photo.png", photo.Bytes())
+	memory.PutAsset("trip/notes.txt", []byte("not an image"))
+
+	postService := services.NewPostServiceWithStore(memory)
+	postService.SetImageWidths([]int{320, 640, 1280})
+	cache, err := imaging.NewCache(t.TempDir(), 1<<20)
+	if err != nil {
+		t.Fatalf("NewCache failed: %v", err)
+	}
+	postHandler := NewPostHandler(postService)
+	imageHandler := NewImageHandler(postService, cache, []int{320, 640, 1280})
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/posts/:slug", postHandler.GetPostBySlug)
+	r.GET("/images/*path", imageHandler.GetImage)
+
+	w := serve(r, "GET", "/posts/trip", "", nil)
+	var post struct {
+		HTML string `json:"html"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &post); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	srcset := regexp.MustCompile(`srcset="([^"]*)"`).FindStringSubmatch(post.HTML)
+	if srcset == nil {
+		t.Fatalf("Expected a srcset in %s", post.HTML)
+	}
+	candidates := strings.Split(html.UnescapeString(srcset[1]), ", ")
+	if len(candidates) != 3 || !strings.HasSuffix(candidates[0], " 320w") || !strings.HasSuffix(candidates[2], " 800w") {
+		t.Fatalf("Expected 320w, 640w and the 800w original, got %q", candidates)
+	}
+
+	resized := strings.Fields(candidates[0])[0]
+	w = serve(r, "GET", resized, "", nil)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("Expected a PNG, got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	if cfg, err := png.DecodeConfig(w.Body); err != nil || cfg.Width != 320 || cfg.Height != 160 {
+		t.Errorf("Expected 320x160, got %+v, %v", cfg, err)
+	}
+	if cc := w.Header().Get("Cache-Control"); !strings.Contains(cc, "immutable") {
+		t.Errorf("Expected immutable caching for a hashed URL, got %q", cc)
+	}
+
+	// The second request is served from the cache
+	if again := serve(r, "GET", resized, "", nil); again.Code != http.StatusOK || again.Header().Get("ETag") != w.Header().Get("ETag") {
+		t.Errorf("Expected the same image again, got %d", again.Code)
+	}
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/images/trip/photo.png?w=100", http.StatusBadRequest},
+		{"/images/trip/photo.png?w=320&format=avif", http.StatusBadRequest},
+		{"/images/trip/photo.png?w=320&fit=squash", http.StatusBadRequest},
+		{"/images/trip/notes.txt?w=320", http.StatusUnsupportedMediaType},
+		{"/images/trip/missing.png?w=320", http.StatusNotFound},
+		{"/images/trip", http.StatusNotFound},
+		{"/images/trip/photo.png?w=320&h=320&fit=cover&format=jpeg", http.StatusOK},
+	}
+	for _, tt := range tests {
+		if w := serve(r, "GET", tt.path, "", nil); w.Code != tt.code {
+			t.Errorf("Expected %d for %s, got %d: %s", tt.code, tt.path, w.Code, w.Body.String())
+		}
+	}
+}
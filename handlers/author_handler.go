@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"blog-api/models"
+	"blog-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthorService is the set of author operations AuthorHandler depends on
+type AuthorService interface {
+	GetAllAuthors() ([]models.AuthorSummary, error)
+	GetAuthor(id string) (models.AuthorPosts, error)
+}
+
+// AuthorHandler handles HTTP requests for authors
+type AuthorHandler struct {
+	authorService AuthorService
+}
+
+// NewAuthorHandler creates a new AuthorHandler instance
+func NewAuthorHandler(authorService AuthorService) *AuthorHandler {
+	return &AuthorHandler{
+		authorService: authorService,
+	}
+}
+
+// GetAllAuthors returns every author
+// @Summary Get all authors
+// @Description Get every author with a profile in the authors file or a post naming them, with how many posts each wrote
+// @Tags authors
+// @Produce json
+// @Success 200 {object} models.AuthorListResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /authors [get]
+func (ah *AuthorHandler) GetAllAuthors(c *gin.Context) {
+	authors, err := ah.authorService.GetAllAuthors()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load authors: " + err.Error()})
+		return
+	}
+
+	if authors == nil {
+		authors = []models.AuthorSummary{}
+	}
+	c.JSON(http.StatusOK, models.AuthorListResponse{
+		Authors: authors,
+		Count:   len(authors),
+	})
+}
+
+// GetAuthor returns an author's profile and posts
+// @Summary Get an author
+// @Description Get an author's profile and their posts, newest first
+// @Tags authors
+// @Produce json
+// @Param id path string true "Author ID"
+// @Success 200 {object} models.AuthorPosts
+// @Failure 404 {object} models.ErrorResponse
+// @Router /authors/{id} [get]
+func (ah *AuthorHandler) GetAuthor(c *gin.Context) {
+	author, err := ah.authorService.GetAuthor(c.Param("id"))
+	if errors.Is(err, services.ErrAuthorNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Author not found: " + c.Param("id")})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load author: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, author)
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"blog-api/comments"
+	"blog-api/models"
+	"blog-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CommentStore is the set of comment operations CommentHandler depends on
+type CommentStore interface {
+	Submit(post string, input comments.Input, formerSlugs ...string) (comments.Comment, error)
+	Thread(posts ...string) comments.Thread
+	List(status string) []comments.Comment
+	SetStatus(id, status string) (comments.Comment, error)
+	Delete(id string) error
+	Export() []comments.Comment
+	Backup() (comments.Backup, error)
+}
+
+// CommentPosts looks up the posts comments are made on
+type CommentPosts interface {
+	GetPostBySlug(slug string) (models.BlogPost, error)
+}
+
+// CommentHandler handles HTTP requests for comments and their moderation
+type CommentHandler struct {
+	store CommentStore
+	posts CommentPosts
+}
+
+// NewCommentHandler creates a new CommentHandler instance
+func NewCommentHandler(store CommentStore, posts CommentPosts) *CommentHandler {
+	return &CommentHandler{
+		store: store,
+		posts: posts,
+	}
+}
+
+// GetComments returns the approved comments on a post
+// @Summary Get comments on a post
+// @Description Get the approved comments on a post, oldest first, with replies nested under the comment they answer. Comments made under the post's former slugs are included.
+// @Tags comments
+// @Produce json
+// @Param slug path string true "Post slug"
+// @Success 200 {object} comments.Thread
+// @Failure 404 {object} models.ErrorResponse
+// @Router /posts/{slug}/comments [get]
+func (ch *CommentHandler) GetComments(c *gin.Context) {
+	post, ok := ch.post(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, ch.store.Thread(append([]string{post.Slug}, post.Aliases...)...))
+}
+
+// CreateComment submits a comment on a post for moderation
+// @Summary Comment on a post
+// @Description Submit a comment, as JSON or a form, to be shown once a moderator approves it. The body may use **strong**, *emphasis*, `code` and links; anything else is escaped. Set parent_id to reply to an approved comment. Leave nickname empty: it is a trap for bots.
+// @Tags comments
+// @Accept json
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param slug path string true "Post slug"
+// @Param comment body comments.Input true "Comment"
+// @Success 202 {object} comments.Comment
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Router /posts/{slug}/comments [post]
+func (ch *CommentHandler) CreateComment(c *gin.Context) {
+	post, ok := ch.post(c)
+	if !ok {
+		return
+	}
+
+	var input comments.Input
+	if err := c.ShouldBind(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment: " + err.Error()})
+		return
+	}
+
+	comment, err := ch.store.Submit(post.Slug, input, post.Aliases...)
+	if errors.Is(err, comments.ErrInvalid) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save comment: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, comment)
+}
+
+// ListComments returns the moderation queue
+// @Summary List comments for moderation
+// @Description Get comments with a status, newest first: pending (the default), approved, spam or all. Requires the admin scope.
+// @Tags comments
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param status query string false "pending, approved, spam or all"
+// @Success 200 {array} comments.Comment
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Router /comments [get]
+func (ch *CommentHandler) ListComments(c *gin.Context) {
+	status := c.DefaultQuery("status", comments.StatusPending)
+	switch status {
+	case "all":
+		status = ""
+	case comments.StatusPending, comments.StatusApproved, comments.StatusSpam:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, approved, spam or all"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ch.store.List(status))
+}
+
+// ModerateComment approves a comment, marks it as spam or returns it to
+// the queue
+// @Summary Moderate a comment
+// @Description Set a comment's status to approved, spam or pending. Only approved comments are shown. Requires the admin scope.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param id path string true "Comment ID"
+// @Param moderation body comments.Moderation true "New status"
+// @Success 200 {object} comments.Comment
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /comments/{id} [patch]
+func (ch *CommentHandler) ModerateComment(c *gin.Context) {
+	var moderation comments.Moderation
+	if err := c.ShouldBindJSON(&moderation); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid moderation: " + err.Error()})
+		return
+	}
+
+	comment, err := ch.store.SetStatus(c.Param("id"), moderation.Status)
+	if errors.Is(err, comments.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+	if errors.Is(err, comments.ErrInvalid) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to moderate comment: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// DeleteComment removes a comment and its replies
+// @Summary Delete a comment
+// @Description Delete a comment along with every reply to it. Requires the admin scope.
+// @Tags comments
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param id path string true "Comment ID"
+// @Success 204
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /comments/{id} [delete]
+func (ch *CommentHandler) DeleteComment(c *gin.Context) {
+	err := ch.store.Delete(c.Param("id"))
+	if errors.Is(err, comments.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete comment: " + err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ExportComments downloads every comment
+// @Summary Export comments
+// @Description Download every comment, in any status, oldest first. The export can be restored by copying it over the comments file. Requires the admin scope.
+// @Tags comments
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {array} comments.Comment
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Router /comments/export [get]
+func (ch *CommentHandler) ExportComments(c *gin.Context) {
+	filename := "comments-" + time.Now().UTC().Format("20060102") + ".json"
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.JSON(http.StatusOK, ch.store.Export())
+}
+
+// BackupComments writes a backup of every comment to the backup directory
+// @Summary Back up comments
+// @Description Write a timestamped copy of every comment to the backup directory, removing the oldest backups beyond the number kept. Requires the admin scope.
+// @Tags comments
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 201 {object} comments.Backup
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /comments/backup [post]
+func (ch *CommentHandler) BackupComments(c *gin.Context) {
+	backup, err := ch.store.Backup()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to back up comments: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, backup)
+}
+
+// post looks up the post named in the path, responding with 404 and
+// reporting false when there is none
+func (ch *CommentHandler) post(c *gin.Context) (models.BlogPost, bool) {
+	post, err := ch.posts.GetPostBySlug(c.Param("slug"))
+	if errors.Is(err, services.ErrPostNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return post, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load post: " + err.Error()})
+		return post, false
+	}
+	return post, true
+}
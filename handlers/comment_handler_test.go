@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"blog-api/comments"
+	"blog-api/metrics"
+	"blog-api/services"
+	"blog-api/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCommentHandler(t *testing.T) {
+	memory := store.NewMemoryStore()
+	memory.Put("hello", []byte("---\ntitle: \"Hello\"\ndate: \"2025-06-01\"\n---\n\nHi"))
+	commentStore, err := comments.NewStore(comments.StoreConfig{}, metrics.NewRegistry())
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	handler := NewCommentHandler(commentStore, services.NewPostServiceWithStore(memory))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/posts/:slug/comments", handler.GetComments)
+	r.POST("/posts/:slug/comments", handler.CreateComment)
+	r.GET("/comments", handler.ListComments)
+	r.PATCH("/comments/:id", handler.ModerateComment)
+	r.DELETE("/comments/:id", handler.DeleteComment)
+
+	if w := serve(r, "POST", "/posts/missing/comments", `{"author":"Jane","body":"Hi"}`, nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing post, got %d", w.Code)
+	}
+	if w := serve(r, "POST", "/posts/hello/comments", `{"author":"Jane"}`, nil); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a comment without a body, got %d", w.Code)
+	}
+
+	// Plain HTML forms can post comments too
+	form := url.Values{"author": {"Jane"}, "body": {"Nice <b>post</b>"}}
+	req := httptest.NewRequest("POST", "/posts/hello/comments", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var comment comments.Comment
+	json.Unmarshal(w.Body.Bytes(), &comment)
+
+	if w := serve(r, "POST", "/posts/hello/comments", `{"author":"Bot","body":"Spam","nickname":"x"}`, nil); w.Code != http.StatusAccepted || !strings.Contains(w.Body.String(), `"status":"pending"`) {
+		t.Errorf("Expected the honeypot to answer like any comment, got %d %s", w.Code, w.Body.String())
+	}
+
+	if w := serve(r, "GET", "/comments", "", nil); strings.Count(w.Body.String(), `"id"`) != 1 {
+		t.Errorf("Expected one comment in the queue, got %s", w.Body.String())
+	}
+	if w := serve(r, "GET", "/comments?status=spam", "", nil); !strings.Contains(w.Body.String(), `"author":"Bot"`) {
+		t.Errorf("Expected the trapped comment in the spam queue, got %s", w.Body.String())
+	}
+	if w := serve(r, "GET", "/comments?status=deleted", "", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown status, got %d", w.Code)
+	}
+
+	if w := serve(r, "GET", "/posts/hello/comments", "", nil); !strings.Contains(w.Body.String(), `"count":0`) {
+		t.Errorf("Expected no comments before moderation, got %s", w.Body.String())
+	}
+	if w := serve(r, "PATCH", "/comments/"+comment.ID, `{"status":"approved"}`, nil); w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 approving, got %d: %s", w.Code, w.Body.String())
+	}
+	var thread comments.Thread
+	json.Unmarshal(serve(r, "GET", "/posts/hello/comments", "", nil).Body.Bytes(), &thread)
+	if thread.Count != 1 || thread.Comments[0].HTML != "<p>Nice &lt;b&gt;post&lt;/b&gt;</p>\n" {
+		t.Errorf("Expected the approved, escaped comment, got %+v", thread)
+	}
+
+	if w := serve(r, "PATCH", "/comments/missing", `{"status":"approved"}`, nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 moderating a missing comment, got %d", w.Code)
+	}
+	if w := serve(r, "DELETE", "/comments/"+comment.ID, "", nil); w.Code != http.StatusNoContent {
+		t.Errorf("Expected 204 deleting, got %d", w.Code)
+	}
+}
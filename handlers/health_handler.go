@@ -4,19 +4,28 @@ import (
 	"net/http"
 	"time"
 
+	"blog-api/store"
+
 	"github.com/gin-gonic/gin"
 )
 
 // HealthResponse represents the health check response
 type HealthResponse struct {
-	Status    string `json:"status" example:"healthy"`
-	Timestamp string `json:"timestamp" example:"2024-01-01T12:00:00Z"`
-	Uptime    string `json:"uptime" example:"1h23m45s"`
-	Service   string `json:"service" example:"blog-api"`
-	Version   string `json:"version" example:"1.0.0"`
+	Status    string         `json:"status" example:"healthy"`
+	Timestamp string         `json:"timestamp" example:"2024-01-01T12:00:00Z"`
+	Uptime    string         `json:"uptime" example:"1h23m45s"`
+	Service   string         `json:"service" example:"blog-api"`
+	Version   string         `json:"version" example:"1.0.0"`
+	Content   *ContentStatus `json:"content,omitempty"`
+}
+
+// ContentStatus describes the revision of content being served
+type ContentStatus struct {
+	Revision string `json:"revision" example:"4b825dc642cb6eb9a060e54bf8d69288fbee4904"`
+	SyncedAt string `json:"synced_at" example:"2024-01-01T12:00:00Z"`
 }
 
-// ReadinessResponse represents the readiness check response  
+// ReadinessResponse represents the readiness check response
 type ReadinessResponse struct {
 	Status    string `json:"status" example:"ready"`
 	Timestamp string `json:"timestamp" example:"2024-01-01T12:00:00Z"`
@@ -31,12 +40,15 @@ type LivenessResponse struct {
 // HealthHandler handles health check requests
 type HealthHandler struct {
 	startTime time.Time
+	content   store.Revisioner
 }
 
-// NewHealthHandler creates a new HealthHandler instance
-func NewHealthHandler() *HealthHandler {
+// NewHealthHandler creates a new HealthHandler instance. content may be nil
+// when the content source has no notion of revisions.
+func NewHealthHandler(content store.Revisioner) *HealthHandler {
 	return &HealthHandler{
 		startTime: time.Now(),
+		content:   content,
 	}
 }
 
@@ -50,14 +62,24 @@ func NewHealthHandler() *HealthHandler {
 // @Router /health [get]
 func (hh *HealthHandler) HealthCheck(c *gin.Context) {
 	uptime := time.Since(hh.startTime)
-	
-	c.JSON(http.StatusOK, gin.H{
+
+	response := gin.H{
 		"status":    "healthy",
 		"timestamp": time.Now().UTC().Format(time.RFC3339),
 		"uptime":    uptime.String(),
 		"service":   "blog-api",
 		"version":   "1.0.0",
-	})
+	}
+
+	if hh.content != nil {
+		revision, syncedAt := hh.content.Revision()
+		response["content"] = ContentStatus{
+			Revision: revision,
+			SyncedAt: syncedAt.UTC().Format(time.RFC3339),
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 // ReadinessCheck returns readiness status (can be extended to check dependencies)
@@ -70,7 +92,7 @@ func (hh *HealthHandler) HealthCheck(c *gin.Context) {
 // @Router /health/ready [get]
 func (hh *HealthHandler) ReadinessCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
-		"status": "ready",
+		"status":    "ready",
 		"timestamp": time.Now().UTC().Format(time.RFC3339),
 	})
 }
@@ -85,7 +107,7 @@ func (hh *HealthHandler) ReadinessCheck(c *gin.Context) {
 // @Router /health/live [get]
 func (hh *HealthHandler) LivenessCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
-		"status": "alive",
+		"status":    "alive",
 		"timestamp": time.Now().UTC().Format(time.RFC3339),
 	})
 }
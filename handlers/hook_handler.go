@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"blog-api/models"
+	"blog-api/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxHookBody bounds how much of a webhook body is read and verified
+const maxHookBody = 1 << 20
+
+// signatureTolerance is how far a generic webhook timestamp may drift
+const signatureTolerance = 5 * time.Minute
+
+// ReloadQueue is the set of reload operations HookHandler depends on
+type ReloadQueue interface {
+	Trigger() models.ReloadJob
+	GetJob(id string) (models.ReloadJob, bool)
+}
+
+// HookHandler handles incoming webhooks
+type HookHandler struct {
+	secret  []byte
+	reloads ReloadQueue
+}
+
+// NewHookHandler creates a new HookHandler instance
+func NewHookHandler(secret string, reloads ReloadQueue) *HookHandler {
+	return &HookHandler{
+		secret:  []byte(secret),
+		reloads: reloads,
+	}
+}
+
+// TriggerReload queues a reload of the content source
+// @Summary Trigger a content reload
+// @Description Queue a reload of the content source. The body must be signed with the shared secret, either GitHub-style in X-Hub-Signature-256 or with X-Signature-256 over "<X-Signature-Timestamp>.<body>". Requests arriving while a reload is queued join it.
+// @Tags hooks
+// @Accept json
+// @Produce json
+// @Param X-Hub-Signature-256 header string false "sha256=<hex HMAC of the body>"
+// @Param X-Signature-256 header string false "sha256=<hex HMAC of timestamp.body>"
+// @Param X-Signature-Timestamp header string false "Unix timestamp included in X-Signature-256"
+// @Success 202 {object} models.ReloadJob
+// @Failure 401 {object} models.ErrorResponse
+// @Router /hooks/reload [post]
+func (hh *HookHandler) TriggerReload(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxHookBody))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	if err := hh.verify(c.Request, body); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid webhook signature: " + err.Error()})
+		return
+	}
+
+	job := hh.reloads.Trigger()
+
+	c.Header("Location", "/hooks/reload/"+job.ID)
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetReloadStatus returns the status of a reload job
+// @Summary Get reload status
+// @Description Get the status of a reload queued by POST /hooks/reload
+// @Tags hooks
+// @Produce json
+// @Param id path string true "Reload job ID"
+// @Success 200 {object} models.ReloadJob
+// @Failure 404 {object} models.ErrorResponse
+// @Router /hooks/reload/{id} [get]
+func (hh *HookHandler) GetReloadStatus(c *gin.Context) {
+	job, ok := hh.reloads.GetJob(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reload job not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// verify checks the request's signature, preferring the GitHub header
+func (hh *HookHandler) verify(r *http.Request, body []byte) error {
+	if signature := r.Header.Get("X-Hub-Signature-256"); signature != "" {
+		return webhook.Verify(hh.secret, body, signature)
+	}
+
+	return webhook.VerifyWithTimestamp(hh.secret, body,
+		r.Header.Get("X-Signature-256"), r.Header.Get("X-Signature-Timestamp"),
+		signatureTolerance, time.Now())
+}
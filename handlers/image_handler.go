@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"blog-api/imaging"
+	"blog-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImageHandler serves post images resized and re-encoded
+type ImageHandler struct {
+	assets PostAssets
+	cache  *imaging.Cache
+	sizes  map[int]bool
+
+	// resizing bounds concurrent resizes, which are CPU and memory hungry
+	resizing chan struct{}
+}
+
+// NewImageHandler creates a new ImageHandler instance. Only the widths and
+// heights in sizes may be requested. cache may be nil to resize every
+// request.
+func NewImageHandler(assets PostAssets, cache *imaging.Cache, sizes []int) *ImageHandler {
+	allowed := make(map[int]bool, len(sizes))
+	for _, size := range sizes {
+		allowed[size] = true
+	}
+	return &ImageHandler{
+		assets:   assets,
+		cache:    cache,
+		sizes:    allowed,
+		resizing: make(chan struct{}, runtime.NumCPU()),
+	}
+}
+
+// GetImage serves a post image resized and re-encoded
+// @Summary Get a resized image
+// @Description Get an image from a post's assets, scaled down to the requested width and/or height and encoded as JPEG or PNG. Widths and heights are limited to the configured sizes. JPEG, PNG, GIF and WebP sources are accepted; images are never enlarged. Rendered post HTML offers these in each image's srcset.
+// @Tags posts
+// @Produce image/jpeg
+// @Produce image/png
+// @Param path path string true "Post slug and asset path, e.g. pi-cluster/rack.jpg"
+// @Param w query int false "Width in pixels"
+// @Param h query int false "Height in pixels"
+// @Param fit query string false "contain (default), cover or fill"
+// @Param format query string false "jpeg or png; defaults to JPEG for JPEG sources, otherwise PNG"
+// @Param v query string false "Content hash of the source"
+// @Success 200 {file} file "Image"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Router /images/{path} [get]
+func (ih *ImageHandler) GetImage(c *gin.Context) {
+	slug, name, ok := strings.Cut(strings.TrimPrefix(c.Param("path"), "/"), "/")
+	if !ok || slug == "" || name == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		return
+	}
+
+	opts := imaging.Options{
+		Fit:    c.Query("fit"),
+		Format: c.Query("format"),
+	}
+	for _, dim := range []struct {
+		param string
+		value *int
+	}{{"w", &opts.Width}, {"h", &opts.Height}} {
+		raw := c.Query(dim.param)
+		if raw == "" {
+			continue
+		}
+		size, err := strconv.Atoi(raw)
+		if err != nil || !ih.sizes[size] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s must be one of %s", dim.param, ih.allowedSizes())})
+			return
+		}
+		*dim.value = size
+	}
+
+	asset, err := ih.assets.GetAsset(slug, name)
+	if errors.Is(err, services.ErrPostNotFound) || errors.Is(err, services.ErrAssetNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load image: " + err.Error()})
+		return
+	}
+
+	sourceFormat, _, err := imaging.Format(asset.Content)
+	if err != nil {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Not a supported image"})
+		return
+	}
+	if opts, err = opts.Normalize(sourceFormat); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	key := imaging.Key(asset.Hash, opts)
+	data, err := ih.resize(key, asset.Content, opts)
+	if errors.Is(err, imaging.ErrUnsupportedFormat) {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resize image: " + err.Error()})
+		return
+	}
+
+	c.Header("ETag", `"`+key+`"`)
+	if c.Query("v") == asset.Hash {
+		c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		c.Header("Cache-Control", "no-cache")
+	}
+	c.Header("Content-Type", opts.ContentType())
+	c.Header("X-Content-Type-Options", "nosniff")
+
+	http.ServeContent(c.Writer, c.Request, "", time.Time{}, bytes.NewReader(data))
+}
+
+// resize returns the cached copy of an image resized with opts, resizing
+// and caching it if there is none
+func (ih *ImageHandler) resize(key string, src []byte, opts imaging.Options) ([]byte, error) {
+	if ih.cache != nil {
+		if data, ok := ih.cache.Get(key); ok {
+			return data, nil
+		}
+	}
+
+	ih.resizing <- struct{}{}
+	data, err := imaging.Resize(src, opts)
+	<-ih.resizing
+	if err != nil {
+		return nil, err
+	}
+
+	if ih.cache != nil {
+		if err := ih.cache.Put(key, data); err != nil {
+			fmt.Printf("Error caching resized image: %v\n", err)
+		}
+	}
+	return data, nil
+}
+
+// allowedSizes lists the sizes that may be requested
+func (ih *ImageHandler) allowedSizes() string {
+	sizes := make([]int, 0, len(ih.sizes))
+	for size := range ih.sizes {
+		sizes = append(sizes, size)
+	}
+	sort.Ints(sizes)
+
+	parts := make([]string, len(sizes))
+	for i, size := range sizes {
+		parts[i] = strconv.Itoa(size)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"blog-api/models"
+	"blog-api/services"
+
+	"golang.org/x/text/language"
+)
+
+// negotiateTranslation picks the translation to serve: the one in lang if
+// given, or failing that the best match for an Accept-Language header.
+// Without a match it falls back to the translation with slug preferred, if
+// it is one of them, and otherwise the first. It reports false only when no
+// translation is in lang.
+func negotiateTranslation(translations []models.Translation, preferred, lang, acceptLanguage string) (models.Translation, bool) {
+	if lang != "" {
+		for _, translation := range translations {
+			if services.MatchesLanguage(translation.Lang, lang) {
+				return translation, true
+			}
+		}
+		return models.Translation{}, false
+	}
+
+	ordered := make([]models.Translation, 0, len(translations))
+	for _, translation := range translations {
+		if translation.Slug == preferred {
+			ordered = append([]models.Translation{translation}, ordered...)
+		} else {
+			ordered = append(ordered, translation)
+		}
+	}
+
+	wanted, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(wanted) == 0 {
+		return ordered[0], true
+	}
+
+	tags := make([]language.Tag, len(ordered))
+	for i, translation := range ordered {
+		tags[i] = language.Make(translation.Lang)
+	}
+	_, index, confidence := language.NewMatcher(tags).Match(wanted...)
+	if confidence == language.No {
+		return ordered[0], true
+	}
+	return ordered[index], true
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+
+	"blog-api/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsHandler serves application metrics
+type MetricsHandler struct {
+	registry *metrics.Registry
+}
+
+// NewMetricsHandler creates a new MetricsHandler instance
+func NewMetricsHandler(registry *metrics.Registry) *MetricsHandler {
+	return &MetricsHandler{
+		registry: registry,
+	}
+}
+
+// GetMetrics returns counters in the Prometheus text format
+// @Summary Metrics
+// @Description Get application counters in the Prometheus text exposition format
+// @Tags general
+// @Produce plain
+// @Success 200 {string} string "Prometheus metrics"
+// @Router /metrics [get]
+func (mh *MetricsHandler) GetMetrics(c *gin.Context) {
+	var buf bytes.Buffer
+	if err := mh.registry.WriteText(&buf); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to write metrics: " + err.Error()})
+		return
+	}
+
+	c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", buf.Bytes())
+}
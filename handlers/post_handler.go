@@ -1,30 +1,79 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
 	"blog-api/models"
 	"blog-api/services"
 
 	"github.com/gin-gonic/gin"
 )
 
+// PostService is the set of post operations PostHandler depends on
+type PostService interface {
+	GetAllPosts(includeContent bool) ([]models.BlogPost, error)
+	GetPostBySlug(slug string) (models.BlogPost, error)
+	ResolveAlias(alias string) (string, error)
+	GetTranslations(slug string) ([]models.Translation, error)
+	Languages() []string
+	CreatePost(input models.PostInput) (models.BlogPost, error)
+	UpdatePost(slug string, input models.PostInput, ifMatch string) (models.BlogPost, error)
+	PatchPost(slug string, patch models.PostPatch, ifMatch string) (models.BlogPost, error)
+	DeletePost(slug, ifMatch string) error
+	GetRelatedPosts(slug string, limit int) ([]models.RelatedPost, error)
+	GenerateRSSFeed(title, baseURL, description, lang string) (models.RSSFeed, error)
+	GenerateAtomFeed(title, baseURL, subtitle, lang string) (models.AtomFeed, error)
+	GenerateJSONFeed(title, baseURL, description, lang string) (models.JSONFeed, error)
+}
+
+// Site details used in feeds
+const (
+	SiteURL         = "https://blog-api.murray.kiwi"
+	RSSFeedURL      = SiteURL + "/rss"
+	AtomFeedURL     = SiteURL + "/atom"
+	JSONFeedURL     = SiteURL + "/feed.json"
+	siteTitle       = "Scott Murray's Blog"
+	siteDescription = "Latest posts from my blog"
+)
+
 // PostHandler handles HTTP requests for blog posts
 type PostHandler struct {
-	postService *services.PostService
+	postService   PostService
+	hubURL        string
+	webmentionURL string
 }
 
 // NewPostHandler creates a new PostHandler instance
-func NewPostHandler(postService *services.PostService) *PostHandler {
+func NewPostHandler(postService PostService) *PostHandler {
 	return &PostHandler{
 		postService: postService,
 	}
 }
 
+// SetHubURL advertises a WebSub hub in the feeds
+func (ph *PostHandler) SetHubURL(hubURL string) {
+	ph.hubURL = hubURL
+}
+
+// SetWebmentionURL advertises a Webmention endpoint on each post
+func (ph *PostHandler) SetWebmentionURL(webmentionURL string) {
+	ph.webmentionURL = webmentionURL
+}
+
 // GetAllPosts returns a list of all blog posts (without full content)
 // @Summary Get all blog posts
 // @Description Get a list of all blog posts with metadata only (no content)
 // @Tags posts
 // @Accept json
 // @Produce json
+// @Param tag query string false "Only posts with this tag"
+// @Param section query string false "Only posts in this section, the directory holding them"
+// @Param lang query string false "Only posts in this language; en matches every region of English"
 // @Success 200 {object} models.PostsResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /posts [get]
@@ -35,16 +84,22 @@ func (ph *PostHandler) GetAllPosts(c *gin.Context) {
 		return
 	}
 
+	tag := c.Query("tag")
+	section, filterSection := c.GetQuery("section")
+	lang := c.Query("lang")
+
 	var postMetas []models.BlogPostMeta
 	for _, post := range posts {
-		postMetas = append(postMetas, models.BlogPostMeta{
-			Slug:        post.Slug,
-			Title:       post.Title,
-			Date:        post.Date,
-			Tags:        post.Tags,
-			Excerpt:     post.Excerpt,
-			PublishDate: post.PublishDate,
-		})
+		if tag != "" && !hasTag(post, tag) {
+			continue
+		}
+		if filterSection && post.Section != strings.Trim(section, "/") {
+			continue
+		}
+		if lang != "" && !services.MatchesLanguage(post.Lang, lang) {
+			continue
+		}
+		postMetas = append(postMetas, models.NewBlogPostMeta(post))
 	}
 
 	c.JSON(200, gin.H{
@@ -55,52 +110,442 @@ func (ph *PostHandler) GetAllPosts(c *gin.Context) {
 
 // GetPostBySlug returns a specific blog post by its slug
 // @Summary Get a blog post by slug
-// @Description Get a specific blog post by its slug identifier
+// @Description Get a specific blog post by its slug identifier. A former slug listed in a post's aliases redirects permanently to the post. A post's translation key, which for translations named <slug>.<lang>.md is the slug without the language, returns the translation best matching ?lang= or Accept-Language; a translation's own slug always returns that translation unless ?lang= asks for another.
 // @Tags posts
 // @Accept json
 // @Produce json
-// @Param slug path string true "Post slug"
+// @Param slug path string true "Post slug or translation key"
+// @Param lang query string false "Language of the translation wanted"
+// @Param Accept-Language header string false "Preferred languages"
 // @Success 200 {object} models.BlogPost
+// @Success 301 "Moved to the post's current slug"
+// @Header 200 {string} Link "The post's canonical URL and its translations"
+// @Header 200 {string} Content-Language "The post's language"
+// @Header 301 {string} Location "The post's current URL"
 // @Failure 404 {object} models.ErrorResponse
 // @Router /posts/{slug} [get]
 func (ph *PostHandler) GetPostBySlug(c *gin.Context) {
 	slug := c.Param("slug")
-	
+
 	// Check if slug is empty (e.g., from /posts/ request)
 	if slug == "" {
 		c.JSON(404, gin.H{"error": "Post not found: empty slug"})
 		return
 	}
 
+	lang := c.Query("lang")
 	post, err := ph.postService.GetPostBySlug(slug)
+	if err == nil && lang == "" && post.TranslationKey != slug {
+		ph.writePost(c, post)
+		return
+	}
+
+	translations, terr := ph.postService.GetTranslations(slug)
+	if terr != nil {
+		if canonical, err := ph.postService.ResolveAlias(slug); err == nil {
+			location := url.URL{Path: "/posts/" + canonical, RawQuery: c.Request.URL.RawQuery}
+			c.Redirect(http.StatusMovedPermanently, location.String())
+			return
+		}
+		c.JSON(404, gin.H{"error": "Post not found: " + slug})
+		return
+	}
+
+	chosen, ok := negotiateTranslation(translations, post.Slug, lang, c.GetHeader("Accept-Language"))
+	if !ok {
+		c.JSON(404, gin.H{"error": "Post not found in language: " + lang})
+		return
+	}
+	if lang == "" {
+		c.Header("Vary", "Accept-Language")
+	}
+	if chosen.Slug != post.Slug {
+		if post, err = ph.postService.GetPostBySlug(chosen.Slug); err != nil {
+			c.JSON(404, gin.H{"error": "Post not found: " + slug})
+			return
+		}
+	}
+
+	ph.writePost(c, post)
+}
+
+// writePost sends a single post with its validator, language and links to
+// its canonical URL, translations and Webmention endpoint
+func (ph *PostHandler) writePost(c *gin.Context, post models.BlogPost) {
+	c.Header("ETag", post.ETag)
+	c.Header("Content-Language", post.Lang)
+	c.Writer.Header().Add("Link", "<"+post.CanonicalURL+`>; rel="canonical"`)
+	for _, translation := range post.Translations {
+		if translation.Slug != post.Slug {
+			c.Writer.Header().Add("Link", "<"+translation.URL+`>; rel="alternate"; hreflang="`+translation.Lang+`"`)
+		}
+	}
+	if ph.webmentionURL != "" {
+		c.Writer.Header().Add("Link", "<"+ph.webmentionURL+`>; rel="webmention"`)
+	}
+	c.JSON(200, post)
+}
+
+// GetRelatedPosts returns posts related to a blog post
+// @Summary Get related posts
+// @Description Get other posts ranked by shared tags, content similarity and recency
+// @Tags posts
+// @Produce json
+// @Param slug path string true "Post slug"
+// @Param limit query int false "Maximum number of posts (1-20)" default(5)
+// @Success 200 {object} models.RelatedPostsResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /posts/{slug}/related [get]
+func (ph *PostHandler) GetRelatedPosts(c *gin.Context) {
+	limit := 5
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 20 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 20"})
+			return
+		}
+		limit = n
+	}
+
+	posts, err := ph.postService.GetRelatedPosts(c.Param("slug"), limit)
+	if errors.Is(err, services.ErrPostNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found: " + c.Param("slug")})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load related posts: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.RelatedPostsResponse{
+		Posts: posts,
+		Count: len(posts),
+	})
+}
+
+// CreatePost creates a new blog post
+// @Summary Create a blog post
+// @Description Create a new blog post. If no slug is given one is derived from the title.
+// @Tags posts
+// @Accept json
+// @Produce json
+// @Param post body models.PostInput true "Post to create"
+// @Success 201 {object} models.BlogPost
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /posts [post]
+func (ph *PostHandler) CreatePost(c *gin.Context) {
+	var input models.PostInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	post, err := ph.postService.CreatePost(input)
+	if err != nil {
+		writePostError(c, err)
+		return
+	}
+
+	c.Header("ETag", post.ETag)
+	c.Header("Location", "/posts/"+post.Slug)
+	c.JSON(201, post)
+}
+
+// UpdatePost replaces an existing blog post
+// @Summary Replace a blog post
+// @Description Replace a blog post. Fields left out or empty keep their current values, and an empty list clears tags, authors or aliases. A different slug in the body renames the post.
+// @Tags posts
+// @Accept json
+// @Produce json
+// @Param slug path string true "Post slug"
+// @Param If-Match header string false "ETag from a previous read"
+// @Param post body models.PostInput true "Replacement post"
+// @Success 200 {object} models.BlogPost
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /posts/{slug} [put]
+func (ph *PostHandler) UpdatePost(c *gin.Context) {
+	var input models.PostInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	post, err := ph.postService.UpdatePost(c.Param("slug"), input, c.GetHeader("If-Match"))
 	if err != nil {
-		c.JSON(404, gin.H{"error": "Post not found: " + err.Error()})
+		writePostError(c, err)
 		return
 	}
 
+	c.Header("ETag", post.ETag)
 	c.JSON(200, post)
 }
 
+// PatchPost partially updates an existing blog post
+// @Summary Update a blog post
+// @Description Update only the given fields of a blog post
+// @Tags posts
+// @Accept json
+// @Produce json
+// @Param slug path string true "Post slug"
+// @Param If-Match header string false "ETag from a previous read"
+// @Param post body models.PostPatch true "Fields to update"
+// @Success 200 {object} models.BlogPost
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /posts/{slug} [patch]
+func (ph *PostHandler) PatchPost(c *gin.Context) {
+	var patch models.PostPatch
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	post, err := ph.postService.PatchPost(c.Param("slug"), patch, c.GetHeader("If-Match"))
+	if err != nil {
+		writePostError(c, err)
+		return
+	}
+
+	c.Header("ETag", post.ETag)
+	c.JSON(200, post)
+}
+
+// DeletePost deletes a blog post
+// @Summary Delete a blog post
+// @Description Delete a blog post by its slug
+// @Tags posts
+// @Param slug path string true "Post slug"
+// @Param If-Match header string false "ETag from a previous read"
+// @Success 204
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /posts/{slug} [delete]
+func (ph *PostHandler) DeletePost(c *gin.Context) {
+	if err := ph.postService.DeletePost(c.Param("slug"), c.GetHeader("If-Match")); err != nil {
+		writePostError(c, err)
+		return
+	}
+
+	c.Status(204)
+}
+
+// writePostError maps post service errors to HTTP responses
+func writePostError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidPost):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrPostNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found: " + c.Param("slug")})
+	case errors.Is(err, services.ErrPostExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrPreconditionFailed):
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrReadOnly):
+		c.JSON(http.StatusMethodNotAllowed, gin.H{"error": "Posts are read-only in this deployment"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save post: " + err.Error()})
+	}
+}
+
+// hasTag reports whether post is tagged tag, ignoring case
+func hasTag(post models.BlogPost, tag string) bool {
+	for _, t := range post.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetRSSFeed returns an RSS feed of blog posts
 // @Summary Get RSS feed
-// @Description Get an RSS feed of all blog posts
+// @Description Get an RSS feed of all blog posts, or with ?lang= of the posts in one of the site's languages. When the WebSub hub is enabled the feed links to it, so readers can subscribe for pushed updates instead of polling.
 // @Tags posts
 // @Accept json
 // @Produce application/rss+xml
+// @Param lang query string false "One of the site's languages"
 // @Success 200 {string} string "RSS XML feed"
+// @Failure 404 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /rss [get]
 func (ph *PostHandler) GetRSSFeed(c *gin.Context) {
-	title := "Scott Murray's Blog"
-	baseURL := "https://blog-api.murray.kiwi"
-	description := "Latest posts from my blog"
+	lang, ok := ph.feedLanguage(c)
+	if !ok {
+		return
+	}
 
-	feed, err := ph.postService.GenerateRSSFeed(title, baseURL, description)
+	contentType, body, err := ph.RenderRSSFeed(lang)
 	if err != nil {
 		c.JSON(500, gin.H{"error": "Failed to generate RSS feed: " + err.Error()})
 		return
 	}
 
-	c.Header("Content-Type", "application/rss+xml; charset=utf-8")
-	c.String(200, feed.ToXML())
+	ph.addHubLinks(c, FeedURL(RSSFeedURL, lang))
+	c.Data(200, contentType, body)
+}
+
+// RenderRSSFeed renders the RSS feed served at /rss, of every post or only
+// those in lang
+func (ph *PostHandler) RenderRSSFeed(lang string) (string, []byte, error) {
+	feed, err := ph.postService.GenerateRSSFeed(siteTitle, SiteURL, siteDescription, lang)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if ph.hubURL != "" {
+		feed.SelfURL = FeedURL(RSSFeedURL, lang)
+		feed.HubURL = ph.hubURL
+	}
+
+	return "application/rss+xml; charset=utf-8", []byte(feed.ToXML()), nil
+}
+
+// GetAtomFeed returns an Atom feed of blog posts
+// @Summary Get Atom feed
+// @Description Get an Atom feed of all blog posts, or with ?lang= of the posts in one of the site's languages, with when each was published and last updated. When the WebSub hub is enabled the feed links to it.
+// @Tags posts
+// @Produce application/atom+xml
+// @Param lang query string false "One of the site's languages"
+// @Success 200 {string} string "Atom XML feed"
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /atom [get]
+func (ph *PostHandler) GetAtomFeed(c *gin.Context) {
+	lang, ok := ph.feedLanguage(c)
+	if !ok {
+		return
+	}
+
+	contentType, body, err := ph.RenderAtomFeed(lang)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to generate Atom feed: " + err.Error()})
+		return
+	}
+
+	ph.addHubLinks(c, FeedURL(AtomFeedURL, lang))
+	c.Data(200, contentType, body)
+}
+
+// RenderAtomFeed renders the Atom feed served at /atom, of every post or
+// only those in lang
+func (ph *PostHandler) RenderAtomFeed(lang string) (string, []byte, error) {
+	feed, err := ph.postService.GenerateAtomFeed(siteTitle, SiteURL, siteDescription, lang)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if ph.hubURL != "" {
+		feed.SelfURL = FeedURL(AtomFeedURL, lang)
+		feed.HubURL = ph.hubURL
+	}
+
+	return "application/atom+xml; charset=utf-8", []byte(feed.ToXML()), nil
+}
+
+// GetJSONFeed returns a JSON Feed of blog posts
+// @Summary Get JSON Feed
+// @Description Get a JSON Feed (version 1.1) of all blog posts, or with ?lang= of the posts in one of the site's languages, with their rendered HTML and authors. When the WebSub hub is enabled the feed lists it.
+// @Tags posts
+// @Produce application/feed+json
+// @Param lang query string false "One of the site's languages"
+// @Success 200 {object} models.JSONFeed
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /feed.json [get]
+func (ph *PostHandler) GetJSONFeed(c *gin.Context) {
+	lang, ok := ph.feedLanguage(c)
+	if !ok {
+		return
+	}
+
+	contentType, body, err := ph.RenderJSONFeed(lang)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to generate JSON feed: " + err.Error()})
+		return
+	}
+
+	ph.addHubLinks(c, FeedURL(JSONFeedURL, lang))
+	c.Data(200, contentType, body)
+}
+
+// RenderJSONFeed renders the JSON Feed served at /feed.json, of every post
+// or only those in lang
+func (ph *PostHandler) RenderJSONFeed(lang string) (string, []byte, error) {
+	feed, err := ph.postService.GenerateJSONFeed(siteTitle, SiteURL, siteDescription, lang)
+	if err != nil {
+		return "", nil, err
+	}
+
+	feed.FeedURL = FeedURL(JSONFeedURL, lang)
+	if ph.hubURL != "" {
+		feed.Hubs = []models.JSONFeedHub{{Type: "WebSub", URL: ph.hubURL}}
+	}
+
+	body, err := json.Marshal(feed)
+	if err != nil {
+		return "", nil, err
+	}
+	return "application/feed+json; charset=utf-8", body, nil
+}
+
+// feedLanguage returns the language a feed was requested in, "" for every
+// language. Only the site's languages have feeds of their own; for anything
+// else it responds with 404 and reports false.
+func (ph *PostHandler) feedLanguage(c *gin.Context) (string, bool) {
+	lang := c.Query("lang")
+	if lang == "" {
+		return "", true
+	}
+
+	normalized, err := services.NormalizeLanguage(lang)
+	if err == nil {
+		for _, siteLang := range ph.postService.Languages() {
+			if siteLang == normalized {
+				return normalized, true
+			}
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "No feed for language: " + lang})
+	return "", false
+}
+
+// FeedURL returns the URL of a feed in lang, or of every post when lang is
+// empty
+func FeedURL(feedURL, lang string) string {
+	if lang == "" {
+		return feedURL
+	}
+	return feedURL + "?lang=" + url.QueryEscape(lang)
+}
+
+// addHubLinks advertises the WebSub hub for a feed in Link headers
+func (ph *PostHandler) addHubLinks(c *gin.Context, selfURL string) {
+	if ph.hubURL == "" {
+		return
+	}
+	c.Writer.Header().Add("Link", `<`+ph.hubURL+`>; rel="hub"`)
+	c.Writer.Header().Add("Link", `<`+selfURL+`>; rel="self"`)
 }
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"blog-api/models"
+	"blog-api/services"
+	"blog-api/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestPostRouter returns a router serving posts from an in-memory store
+func newTestPostRouter(t *testing.T, docs map[string]string) *gin.Engine {
+	memory := store.NewMemoryStore()
+	for slug, content := range docs {
+		if err := memory.Put(slug, []byte(content)); err != nil {
+			t.Fatalf("Failed to seed post %s: %v", slug, err)
+		}
+	}
+
+	handler := NewPostHandler(services.NewPostServiceWithStore(memory))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/posts", handler.GetAllPosts)
+	r.GET("/posts/:slug", handler.GetPostBySlug)
+	r.POST("/posts", handler.CreatePost)
+	r.PATCH("/posts/:slug", handler.PatchPost)
+	r.DELETE("/posts/:slug", handler.DeletePost)
+	r.GET("/rss", handler.GetRSSFeed)
+	r.GET("/atom", handler.GetAtomFeed)
+	r.GET("/feed.json", handler.GetJSONFeed)
+	return r
+}
+
+func serve(r *gin.Engine, method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestGetAllPosts(t *testing.T) {
+	r := newTestPostRouter(t, map[string]string{
+		"first":  "---\ntitle: \"First\"\ndate: \"2025-01-01\"\n---\n\nOne",
+		"second": "---\ntitle: \"Second\"\ndate: \"2025-02-01\"\n---\n\nTwo",
+	})
+
+	w := serve(r, "GET", "/posts", "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+
+	var response struct {
+		Posts []map[string]interface{} `json:"posts"`
+		Count int                      `json:"count"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	if response.Count != 2 || response.Posts[0]["slug"] != "second" {
+		t.Errorf("Expected 2 posts newest first, got %+v", response)
+	}
+	if _, ok := response.Posts[0]["content"]; ok {
+		t.Error("Post list should not include content")
+	}
+}
+
+func TestPostLifecycle(t *testing.T) {
+	r := newTestPostRouter(t, nil)
+
+	w := serve(r, "POST", "/posts", `{"title":"Fresh Post","date":"2025-03-01","content":"Hello"}`, nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Location") != "/posts/fresh-post" {
+		t.Errorf("Unexpected Location %q", w.Header().Get("Location"))
+	}
+	etag := w.Header().Get("ETag")
+
+	if w := serve(r, "POST", "/posts", `{"slug":"fresh-post","title":"Again"}`, nil); w.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for a duplicate slug, got %d", w.Code)
+	}
+	if w := serve(r, "POST", "/posts", `{"title":""}`, nil); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a missing title, got %d", w.Code)
+	}
+
+	w = serve(r, "PATCH", "/posts/fresh-post", `{"title":"Renamed"}`, map[string]string{"If-Match": etag})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := serve(r, "DELETE", "/posts/fresh-post", "", map[string]string{"If-Match": etag}); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected 412 for a stale ETag, got %d", w.Code)
+	}
+	if w := serve(r, "DELETE", "/posts/fresh-post", "", nil); w.Code != http.StatusNoContent {
+		t.Errorf("Expected 204, got %d", w.Code)
+	}
+	if w := serve(r, "GET", "/posts/fresh-post", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 after delete, got %d", w.Code)
+	}
+	if w := serve(r, "DELETE", "/posts/fresh-post", "", nil); w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), `"Post not found: fresh-post"`) {
+		t.Errorf("Expected 404 naming the slug once, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestGetPostBySlug_Alias(t *testing.T) {
+	r := newTestPostRouter(t, map[string]string{
+		"fixed-name": "---\ntitle: \"Fixed\"\naliases: [\"fxied-name\"]\n---\n\nBody",
+	})
+
+	w := serve(r, "GET", "/posts/fxied-name?utm_source=feed", "", nil)
+	if w.Code != http.StatusMovedPermanently {
+		t.Fatalf("Expected 301, got %d", w.Code)
+	}
+	if location := w.Header().Get("Location"); location != "/posts/fixed-name?utm_source=feed" {
+		t.Errorf("Unexpected Location %q", location)
+	}
+
+	w = serve(r, "GET", "/posts/fixed-name", "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+	if link := w.Header().Get("Link"); link != `</posts/fixed-name>; rel="canonical"` {
+		t.Errorf("Unexpected Link %q", link)
+	}
+	if !strings.Contains(w.Body.String(), `"canonical_url":"/posts/fixed-name"`) {
+		t.Errorf("Expected the canonical URL in the body, got %s", w.Body.String())
+	}
+
+	if w := serve(r, "GET", "/posts/unknown", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown slug, got %d", w.Code)
+	}
+}
+
+func TestGetPostBySlug_Languages(t *testing.T) {
+	r := newTestPostRouter(t, map[string]string{
+		"hello":    "---\ntitle: \"Hello\"\ndate: \"2025-06-01\"\n---\n\nHi",
+		"hello.mi": "---\ntitle: \"Kia ora\"\ndate: \"2025-06-02\"\nlang: \"mi\"\n---\n\nKia ora",
+	})
+
+	tests := []struct {
+		path, acceptLanguage string
+		code                 int
+		lang                 string
+	}{
+		{"/posts/hello", "", http.StatusOK, "en-us"},
+		{"/posts/hello", "mi, en;q=0.5", http.StatusOK, "mi"},
+		{"/posts/hello", "fr", http.StatusOK, "en-us"},
+		{"/posts/hello?lang=mi", "", http.StatusOK, "mi"},
+		{"/posts/hello?lang=fr", "", http.StatusNotFound, ""},
+		// A translation's own slug is not negotiated
+		{"/posts/hello.mi", "en", http.StatusOK, "mi"},
+		{"/posts/hello.mi?lang=en", "", http.StatusOK, "en-us"},
+	}
+	for _, tt := range tests {
+		w := serve(r, "GET", tt.path, "", map[string]string{"Accept-Language": tt.acceptLanguage})
+		if w.Code != tt.code || w.Header().Get("Content-Language") != tt.lang {
+			t.Errorf("%s (%s): expected %d in %q, got %d in %q", tt.path, tt.acceptLanguage, tt.code, tt.lang, w.Code, w.Header().Get("Content-Language"))
+		}
+	}
+
+	w := serve(r, "GET", "/posts/hello", "", map[string]string{"Accept-Language": "mi"})
+	if w.Header().Get("Vary") != "Accept-Language" {
+		t.Errorf("Expected a negotiated response to vary on Accept-Language, got %q", w.Header().Get("Vary"))
+	}
+	links := strings.Join(w.Header().Values("Link"), ", ")
+	if !strings.Contains(links, `</posts/hello>; rel="alternate"; hreflang="en-us"`) {
+		t.Errorf("Expected an hreflang alternate, got %q", links)
+	}
+
+	w = serve(r, "GET", "/posts?lang=mi", "", nil)
+	if !strings.Contains(w.Body.String(), `"count":1`) || !strings.Contains(w.Body.String(), `"slug":"hello.mi"`) {
+		t.Errorf("Expected only the Māori post, got %s", w.Body.String())
+	}
+
+	if w := serve(r, "GET", "/rss?lang=en-us", "", nil); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "<language>en-us</language>") {
+		t.Errorf("Expected an English feed, got %d %s", w.Code, w.Body.String())
+	}
+	if w := serve(r, "GET", "/atom?lang=mi", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a language the site is not written in, got %d", w.Code)
+	}
+}
+
+func TestGetJSONFeed(t *testing.T) {
+	r := newTestPostRouter(t, map[string]string{
+		"hello": "---\ntitle: \"Hello\"\ndate: \"2025-06-01\"\nauthor: \"Scott Murray\"\n---\n\nHi",
+	})
+
+	w := serve(r, "GET", "/feed.json", "", nil)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/feed+json; charset=utf-8" {
+		t.Fatalf("Expected a JSON feed, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	var feed models.JSONFeed
+	if err := json.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("Failed to parse feed: %v", err)
+	}
+	if feed.Version != models.JSONFeedVersion || feed.FeedURL != JSONFeedURL || len(feed.Items) != 1 {
+		t.Fatalf("Unexpected feed %+v", feed)
+	}
+	item := feed.Items[0]
+	if item.URL != SiteURL+"/posts/hello" || item.ContentHTML == "" || len(item.Authors) != 1 || item.Authors[0].Name != "Scott Murray" {
+		t.Errorf("Unexpected item %+v", item)
+	}
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+
+	"blog-api/redirects"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RedirectHandler answers requests for paths with no route, redirecting
+// those that have moved
+type RedirectHandler struct {
+	table *redirects.Table
+}
+
+// NewRedirectHandler creates a new RedirectHandler instance. table may be
+// nil, in which case every request is not found.
+func NewRedirectHandler(table *redirects.Table) *RedirectHandler {
+	return &RedirectHandler{
+		table: table,
+	}
+}
+
+// NotFound redirects GET and HEAD requests for a path in the redirects
+// table, keeping the query string unless the destination has its own, and
+// returns 404 for everything else
+func (rh *RedirectHandler) NotFound(c *gin.Context) {
+	method := c.Request.Method
+	if method == http.MethodGet || method == http.MethodHead {
+		if rule, ok := rh.table.Lookup(c.Request.URL.Path); ok {
+			c.Redirect(rule.Status, destination(rule.To, c.Request.URL.RawQuery))
+			return
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+}
+
+// destination adds query to to, if to has no query of its own
+func destination(to, query string) string {
+	u, err := url.Parse(to)
+	if err != nil || query == "" || u.RawQuery != "" {
+		return to
+	}
+	u.RawQuery = query
+	return u.String()
+}
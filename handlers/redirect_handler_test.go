@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"blog-api/redirects"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRedirectHandler(t *testing.T) {
+	table, err := redirects.New([]redirects.Rule{
+		{From: "/feed.xml", To: "/rss", Status: http.StatusMovedPermanently},
+		{From: "/about", To: "https://example.com/about?ref=blog", Status: http.StatusFound},
+	})
+	if err != nil {
+		t.Fatalf("Failed to build redirects: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/rss", func(c *gin.Context) { c.String(http.StatusOK, "rss") })
+	r.NoRoute(NewRedirectHandler(table).NotFound)
+
+	tests := []struct {
+		method, path string
+		code         int
+		location     string
+	}{
+		{"GET", "/feed.xml/?format=full", http.StatusMovedPermanently, "/rss?format=full"},
+		{"HEAD", "/feed.xml", http.StatusMovedPermanently, "/rss"},
+		{"GET", "/about?x=1", http.StatusFound, "https://example.com/about?ref=blog"},
+		{"POST", "/feed.xml", http.StatusNotFound, ""},
+		{"GET", "/missing", http.StatusNotFound, ""},
+		{"GET", "/rss", http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		w := serve(r, tt.method, tt.path, "", nil)
+		if w.Code != tt.code || w.Header().Get("Location") != tt.location {
+			t.Errorf("%s %s: expected %d %q, got %d %q", tt.method, tt.path, tt.code, tt.location, w.Code, w.Header().Get("Location"))
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"blog-api/models"
+	"blog-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SeriesService is the set of series operations SeriesHandler depends on
+type SeriesService interface {
+	GetAllSeries() ([]models.SeriesSummary, error)
+	GetSeries(name string) (models.Series, error)
+}
+
+// SeriesHandler handles HTTP requests for post series
+type SeriesHandler struct {
+	seriesService SeriesService
+}
+
+// NewSeriesHandler creates a new SeriesHandler instance
+func NewSeriesHandler(seriesService SeriesService) *SeriesHandler {
+	return &SeriesHandler{
+		seriesService: seriesService,
+	}
+}
+
+// GetAllSeries returns every series
+// @Summary Get all series
+// @Description Get every series of posts, named by the series frontmatter field
+// @Tags series
+// @Produce json
+// @Success 200 {object} models.SeriesListResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /series [get]
+func (sh *SeriesHandler) GetAllSeries(c *gin.Context) {
+	series, err := sh.seriesService.GetAllSeries()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load series: " + err.Error()})
+		return
+	}
+
+	if series == nil {
+		series = []models.SeriesSummary{}
+	}
+	c.JSON(http.StatusOK, models.SeriesListResponse{
+		Series: series,
+		Count:  len(series),
+	})
+}
+
+// GetSeries returns a series and its parts
+// @Summary Get a series
+// @Description Get a series with its parts in reading order, by series_order and then date
+// @Tags series
+// @Produce json
+// @Param name path string true "Series slug or name"
+// @Success 200 {object} models.Series
+// @Failure 404 {object} models.ErrorResponse
+// @Router /series/{name} [get]
+func (sh *SeriesHandler) GetSeries(c *gin.Context) {
+	series, err := sh.seriesService.GetSeries(c.Param("name"))
+	if errors.Is(err, services.ErrSeriesNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Series not found: " + c.Param("name")})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load series: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
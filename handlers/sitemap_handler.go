@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"blog-api/models"
+	"blog-api/sitemap"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PostLister lists posts
+type PostLister interface {
+	GetAllPosts(includeContent bool) ([]models.BlogPost, error)
+}
+
+// SitemapConfig configures SitemapHandler
+type SitemapConfig struct {
+	// BaseURL prefixes every URL in the sitemap
+	BaseURL string
+	// MaxURLs and MaxBytes bound each sitemap file; zero uses the protocol
+	// limits
+	MaxURLs  int
+	MaxBytes int
+	// RobotsFile, when set, is served as /robots.txt instead of the
+	// generated rules
+	RobotsFile string
+	// Disallow lists paths crawlers are asked to skip
+	Disallow []string
+}
+
+// SitemapHandler serves the sitemap and robots.txt
+type SitemapHandler struct {
+	posts PostLister
+	cfg   SitemapConfig
+}
+
+// NewSitemapHandler creates a new SitemapHandler instance
+func NewSitemapHandler(posts PostLister, cfg SitemapConfig) *SitemapHandler {
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	return &SitemapHandler{
+		posts: posts,
+		cfg:   cfg,
+	}
+}
+
+// GetSitemap returns the sitemap, or a sitemap index once the site outgrows
+// a single file
+// @Summary Get sitemap
+// @Description Get a sitemap of the index, every post and every tag page. Past 50,000 URLs or 50MB this is a sitemap index pointing at /sitemaps/{n}.xml. Append .gz for a gzipped copy.
+// @Tags feeds
+// @Produce xml
+// @Success 200 {string} string "Sitemap XML"
+// @Failure 500 {object} models.ErrorResponse
+// @Router /sitemap.xml [get]
+func (sh *SitemapHandler) GetSitemap(c *gin.Context) {
+	files, lastMod, err := sh.build()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate sitemap: " + err.Error()})
+		return
+	}
+
+	body := files[0]
+	if len(files) > 1 {
+		locs := make([]string, len(files))
+		for i := range files {
+			locs[i] = sh.cfg.BaseURL + "/sitemaps/" + strconv.Itoa(i+1) + ".xml"
+		}
+		body = sitemap.Index(locs, lastMod)
+	}
+
+	sh.write(c, body, strings.HasSuffix(c.Request.URL.Path, ".gz"))
+}
+
+// GetSitemapPart returns one file of a split sitemap
+// @Summary Get part of a split sitemap
+// @Description Get one of the sitemap files listed in the sitemap index, e.g. 1.xml or 1.xml.gz
+// @Tags feeds
+// @Produce xml
+// @Param file path string true "Sitemap file, e.g. 1.xml"
+// @Success 200 {string} string "Sitemap XML"
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /sitemaps/{file} [get]
+func (sh *SitemapHandler) GetSitemapPart(c *gin.Context) {
+	name := c.Param("file")
+	gzipped := strings.HasSuffix(name, ".gz")
+	number, ok := strings.CutSuffix(strings.TrimSuffix(name, ".gz"), ".xml")
+	n, err := strconv.Atoi(number)
+	if !ok || err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sitemap not found"})
+		return
+	}
+
+	files, _, err := sh.build()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate sitemap: " + err.Error()})
+		return
+	}
+	if n < 1 || n > len(files) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sitemap not found"})
+		return
+	}
+
+	sh.write(c, files[n-1], gzipped)
+}
+
+// GetRobots returns robots.txt
+// @Summary Get robots.txt
+// @Description Get crawler rules, including the location of the sitemap
+// @Tags feeds
+// @Produce plain
+// @Success 200 {string} string "robots.txt"
+// @Router /robots.txt [get]
+func (sh *SitemapHandler) GetRobots(c *gin.Context) {
+	sitemapLine := "Sitemap: " + sh.cfg.BaseURL + "/sitemap.xml\n"
+
+	if sh.cfg.RobotsFile != "" {
+		content, err := os.ReadFile(sh.cfg.RobotsFile)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read robots.txt"})
+			return
+		}
+		robots := string(content)
+		if !strings.Contains(strings.ToLower(robots), "sitemap:") {
+			robots = strings.TrimRight(robots, "\n") + "\n\n" + sitemapLine
+		}
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(robots))
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+	if len(sh.cfg.Disallow) == 0 {
+		b.WriteString("Disallow:\n")
+	}
+	for _, path := range sh.cfg.Disallow {
+		b.WriteString("Disallow: " + path + "\n")
+	}
+	b.WriteString("\n" + sitemapLine)
+
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(b.String()))
+}
+
+// build renders the sitemap files and returns them with the time of the
+// most recent change
+func (sh *SitemapHandler) build() ([][]byte, time.Time, error) {
+	posts, err := sh.posts.GetAllPosts(false)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var latest time.Time
+	tagLastMod := make(map[string]time.Time)
+	var postURLs []sitemap.URL
+
+	for _, post := range posts {
+		lastMod := postLastMod(post)
+		if lastMod.After(latest) {
+			latest = lastMod
+		}
+
+		postURLs = append(postURLs, sitemap.URL{
+			Loc:     sh.cfg.BaseURL + "/posts/" + url.PathEscape(post.Slug),
+			LastMod: lastMod,
+		})
+
+		for _, tag := range post.Tags {
+			if lastMod.After(tagLastMod[tag]) || tagLastMod[tag].IsZero() {
+				tagLastMod[tag] = lastMod
+			}
+		}
+	}
+
+	urls := []sitemap.URL{
+		{Loc: sh.cfg.BaseURL + "/", LastMod: latest},
+		{Loc: sh.cfg.BaseURL + "/posts", LastMod: latest},
+	}
+	urls = append(urls, postURLs...)
+
+	tags := make([]string, 0, len(tagLastMod))
+	for tag := range tagLastMod {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		urls = append(urls, sitemap.URL{
+			Loc:     sh.cfg.BaseURL + "/posts?tag=" + url.QueryEscape(tag),
+			LastMod: tagLastMod[tag],
+		})
+	}
+
+	return sitemap.Split(urls, sh.cfg.MaxURLs, sh.cfg.MaxBytes), latest, nil
+}
+
+// write sends a sitemap document, gzipped if requested
+func (sh *SitemapHandler) write(c *gin.Context, body []byte, gzipped bool) {
+	if !gzipped {
+		c.Data(http.StatusOK, "application/xml; charset=utf-8", body)
+		return
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(body)
+	zw.Close()
+
+	c.Data(http.StatusOK, "application/gzip", buf.Bytes())
+}
+
+// postLastMod returns when a post last changed: its updated time if known,
+// otherwise its date
+func postLastMod(post models.BlogPost) time.Time {
+	if updated, err := time.Parse(time.RFC3339, post.Updated); err == nil {
+		return updated
+	}
+	return time.Time(post.Date)
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"blog-api/services"
+	"blog-api/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestSitemapRouter(t *testing.T, cfg SitemapConfig) *gin.Engine {
+	memory := store.NewMemoryStore()
+	memory.Put("first", []byte("---\ntitle: \"First\"\ndate: \"2025-01-01\"\ntags: [go]\n---\n\nOne"))
+	memory.Put("second", []byte("---\ntitle: \"Second\"\ndate: \"2025-02-01\"\ntags: [go, k8s]\n---\n\nTwo"))
+
+	handler := NewSitemapHandler(services.NewPostServiceWithStore(memory), cfg)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/sitemap.xml", handler.GetSitemap)
+	r.GET("/sitemap.xml.gz", handler.GetSitemap)
+	r.GET("/sitemaps/:file", handler.GetSitemapPart)
+	r.GET("/robots.txt", handler.GetRobots)
+	return r
+}
+
+func TestSitemap(t *testing.T) {
+	r := newTestSitemapRouter(t, SitemapConfig{BaseURL: "https://blog.example/"})
+
+	w := serve(r, "GET", "/sitemap.xml", "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	for _, loc := range []string{
+		"<loc>https://blog.example/</loc>",
+		"<loc>https://blog.example/posts/first</loc>",
+		"<loc>https://blog.example/posts?tag=k8s</loc>",
+		"<lastmod>2025-02-01T00:00:00Z</lastmod>",
+	} {
+		if !strings.Contains(body, loc) {
+			t.Errorf("Sitemap missing %s:\n%s", loc, body)
+		}
+	}
+
+	w = serve(r, "GET", "/sitemap.xml.gz", "", nil)
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("Expected a gzipped sitemap: %v", err)
+	}
+	if unzipped, _ := io.ReadAll(zr); string(unzipped) != body {
+		t.Error("Gzipped sitemap differs from the plain one")
+	}
+}
+
+func TestSitemap_Split(t *testing.T) {
+	r := newTestSitemapRouter(t, SitemapConfig{BaseURL: "https://blog.example", MaxURLs: 2})
+
+	w := serve(r, "GET", "/sitemap.xml", "", nil)
+	if !strings.Contains(w.Body.String(), "<sitemapindex") || !strings.Contains(w.Body.String(), "<loc>https://blog.example/sitemaps/3.xml</loc>") {
+		t.Errorf("Expected an index of 3 sitemaps, got:\n%s", w.Body.String())
+	}
+
+	if w := serve(r, "GET", "/sitemaps/3.xml", "", nil); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "<urlset") {
+		t.Errorf("Expected the third sitemap, got %d", w.Code)
+	}
+	if w := serve(r, "GET", "/sitemaps/4.xml", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 past the last sitemap, got %d", w.Code)
+	}
+}
+
+func TestRobots(t *testing.T) {
+	r := newTestSitemapRouter(t, SitemapConfig{BaseURL: "https://blog.example", Disallow: []string{"/hooks/"}})
+
+	w := serve(r, "GET", "/robots.txt", "", nil)
+	expected := "User-agent: *\nDisallow: /hooks/\n\nSitemap: https://blog.example/sitemap.xml\n"
+	if w.Body.String() != expected {
+		t.Errorf("Unexpected robots.txt:\n%s", w.Body.String())
+	}
+}
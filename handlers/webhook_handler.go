@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"blog-api/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeliveryLog is the set of webhook delivery operations WebhookHandler
+// depends on
+type DeliveryLog interface {
+	ListDeliveries() []webhook.Delivery
+	GetDelivery(id string) (webhook.Delivery, bool)
+	Redeliver(id string) (webhook.Delivery, error)
+}
+
+// WebhookHandler handles outgoing webhook administration
+type WebhookHandler struct {
+	deliveries DeliveryLog
+}
+
+// NewWebhookHandler creates a new WebhookHandler instance
+func NewWebhookHandler(deliveries DeliveryLog) *WebhookHandler {
+	return &WebhookHandler{
+		deliveries: deliveries,
+	}
+}
+
+// ListDeliveries returns the outgoing webhook delivery log
+// @Summary List webhook deliveries
+// @Description Get outgoing webhook deliveries, newest first. Requires the admin scope.
+// @Tags webhooks
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {array} webhook.Delivery
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Router /webhooks/deliveries [get]
+func (wh *WebhookHandler) ListDeliveries(c *gin.Context) {
+	c.JSON(http.StatusOK, wh.deliveries.ListDeliveries())
+}
+
+// GetDelivery returns a single webhook delivery
+// @Summary Get a webhook delivery
+// @Description Get an outgoing webhook delivery and its attempts. Requires the admin scope.
+// @Tags webhooks
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param id path string true "Delivery ID"
+// @Success 200 {object} webhook.Delivery
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /webhooks/deliveries/{id} [get]
+func (wh *WebhookHandler) GetDelivery(c *gin.Context) {
+	delivery, ok := wh.deliveries.GetDelivery(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// Redeliver sends a webhook delivery's payload again
+// @Summary Redeliver a webhook
+// @Description Queue a new delivery with the same payload as an earlier one. Requires the admin scope.
+// @Tags webhooks
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param id path string true "Delivery ID"
+// @Success 202 {object} webhook.Delivery
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /webhooks/deliveries/{id}/redeliver [post]
+func (wh *WebhookHandler) Redeliver(c *gin.Context) {
+	delivery, err := wh.deliveries.Redeliver(c.Param("id"))
+	if errors.Is(err, webhook.ErrDeliveryNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to redeliver webhook"})
+		return
+	}
+
+	c.Header("Location", "/webhooks/deliveries/"+delivery.ID)
+	c.JSON(http.StatusAccepted, delivery)
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"blog-api/models"
+	"blog-api/services"
+	"blog-api/webmention"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebmentionReceiver is the set of Webmention operations WebmentionHandler
+// depends on
+type WebmentionReceiver interface {
+	Receive(source, target, post string) (webmention.Mention, error)
+	Get(id string) (webmention.Mention, bool)
+	Mentions(posts ...string) webmention.MentionList
+}
+
+// WebmentionPosts looks up the posts mentions are of
+type WebmentionPosts interface {
+	GetPostBySlug(slug string) (models.BlogPost, error)
+	ResolveAlias(alias string) (string, error)
+}
+
+// WebmentionHandler handles HTTP requests for Webmentions
+type WebmentionHandler struct {
+	receiver WebmentionReceiver
+	posts    WebmentionPosts
+	siteURL  string
+}
+
+// NewWebmentionHandler creates a new WebmentionHandler instance accepting
+// mentions of the posts under siteURL
+func NewWebmentionHandler(receiver WebmentionReceiver, posts WebmentionPosts, siteURL string) *WebmentionHandler {
+	return &WebmentionHandler{
+		receiver: receiver,
+		posts:    posts,
+		siteURL:  strings.TrimRight(siteURL, "/"),
+	}
+}
+
+// ReceiveWebmention accepts a Webmention for verification
+// @Summary Send a Webmention
+// @Description Tell the blog that the page at source links to one of its posts at target. The mention is verified in the background by fetching source, and shown on the post once verified. Send it again after source is updated or deleted to have it verified again.
+// @Tags webmention
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param source formData string true "URL of the page that links to target"
+// @Param target formData string true "URL of the post"
+// @Success 202 {object} webmention.Mention
+// @Header 202 {string} Location "URL of the mention's status"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Router /webmention [post]
+func (wh *WebmentionHandler) ReceiveWebmention(c *gin.Context) {
+	source, target := c.PostForm("source"), c.PostForm("target")
+	if source == "" || target == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "source and target are required"})
+		return
+	}
+
+	slug, ok := wh.postSlug(target)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "target is not a post on this site"})
+		return
+	}
+
+	mention, err := wh.receiver.Receive(source, target, slug)
+	if errors.Is(err, webmention.ErrBusy) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Location", "/webmention/"+mention.ID)
+	c.JSON(http.StatusAccepted, mention)
+}
+
+// GetWebmention returns the status of a Webmention
+// @Summary Get a Webmention's status
+// @Description Get a mention sent to the blog, to see whether it has been verified
+// @Tags webmention
+// @Produce json
+// @Param id path string true "Mention ID"
+// @Success 200 {object} webmention.Mention
+// @Failure 404 {object} models.ErrorResponse
+// @Router /webmention/{id} [get]
+func (wh *WebmentionHandler) GetWebmention(c *gin.Context) {
+	mention, ok := wh.receiver.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webmention not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, mention)
+}
+
+// GetMentions returns the verified Webmentions of a post
+// @Summary Get mentions of a post
+// @Description Get the verified Webmentions of a post, oldest first. Mentions of the post's former slugs are included.
+// @Tags webmention
+// @Produce json
+// @Param slug path string true "Post slug"
+// @Success 200 {object} webmention.MentionList
+// @Failure 404 {object} models.ErrorResponse
+// @Router /posts/{slug}/mentions [get]
+func (wh *WebmentionHandler) GetMentions(c *gin.Context) {
+	post, err := wh.posts.GetPostBySlug(c.Param("slug"))
+	if errors.Is(err, services.ErrPostNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load post: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, wh.receiver.Mentions(append([]string{post.Slug}, post.Aliases...)...))
+}
+
+// postSlug returns the slug of the post a target URL names, following
+// aliases to the post's current slug
+func (wh *WebmentionHandler) postSlug(target string) (string, bool) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", false
+	}
+	u.RawQuery, u.Fragment = "", ""
+
+	rest, ok := strings.CutPrefix(u.String(), wh.siteURL+"/posts/")
+	if !ok {
+		return "", false
+	}
+	slug, err := url.PathUnescape(strings.TrimSuffix(rest, "/"))
+	if err != nil || slug == "" {
+		return "", false
+	}
+
+	if post, err := wh.posts.GetPostBySlug(slug); err == nil {
+		return post.Slug, true
+	}
+	if canonical, err := wh.posts.ResolveAlias(slug); err == nil {
+		return canonical, true
+	}
+	return "", false
+}
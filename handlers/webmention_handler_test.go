@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"blog-api/metrics"
+	"blog-api/services"
+	"blog-api/store"
+	"blog-api/webmention"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestWebmentionHandler(t *testing.T) {
+	memory := store.NewMemoryStore()
+	memory.Put("hello", []byte("---\ntitle: \"Hello\"\ndate: \"2025-06-01\"\naliases: [\"hi\"]\n---\n\nHi"))
+	postService := services.NewPostServiceWithStore(memory)
+	postService.SetSiteURL("https://blog.example")
+
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<title>Re: Hi</title><a href="https://blog.example/posts/hi">Hi</a>`))
+	}))
+	defer source.Close()
+
+	receiver, err := webmention.NewReceiver(webmention.ReceiverConfig{AllowPrivate: true}, metrics.NewRegistry())
+	if err != nil {
+		t.Fatalf("NewReceiver failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go receiver.Run(ctx)
+
+	handler := NewWebmentionHandler(receiver, postService, "https://blog.example/")
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/webmention", handler.ReceiveWebmention)
+	r.GET("/webmention/:id", handler.GetWebmention)
+	r.GET("/posts/:slug/mentions", handler.GetMentions)
+
+	send := func(source, target string) *httptest.ResponseRecorder {
+		form := url.Values{"source": {source}, "target": {target}}
+		req := httptest.NewRequest("POST", "/webmention", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, target := range []string{"https://blog.example/posts/missing", "https://elsewhere.example/posts/hello", ""} {
+		if w := send(source.URL, target); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for target %q, got %d", target, w.Code)
+		}
+	}
+	if w := send("not a url", "https://blog.example/posts/hello"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid source, got %d", w.Code)
+	}
+
+	// A mention of a former slug is kept with the post
+	w := send(source.URL, "https://blog.example/posts/hi")
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var mention webmention.Mention
+	json.Unmarshal(w.Body.Bytes(), &mention)
+	if mention.Post != "hello" || w.Header().Get("Location") != "/webmention/"+mention.ID {
+		t.Errorf("Expected a mention of hello with its status URL, got %+v at %q", mention, w.Header().Get("Location"))
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for mention.Status == webmention.StatusPending && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		json.Unmarshal(serve(r, "GET", "/webmention/"+mention.ID, "", nil).Body.Bytes(), &mention)
+	}
+	if mention.Status != webmention.StatusVerified {
+		t.Fatalf("Expected the mention to be verified, got %+v", mention)
+	}
+
+	var list webmention.MentionList
+	json.Unmarshal(serve(r, "GET", "/posts/hello/mentions", "", nil).Body.Bytes(), &list)
+	if list.Count != 1 || list.Mentions[0].Title != "Re: Hi" {
+		t.Errorf("Expected the verified mention, got %+v", list)
+	}
+
+	if w := serve(r, "GET", "/posts/missing/mentions", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing post, got %d", w.Code)
+	}
+	if w := serve(r, "GET", "/webmention/missing", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing mention, got %d", w.Code)
+	}
+}
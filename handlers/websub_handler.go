@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"blog-api/websub"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SubscriptionHub is the set of hub operations WebSubHandler depends on
+type SubscriptionHub interface {
+	HandleRequest(req websub.Request) error
+}
+
+// WebSubHandler handles WebSub subscription requests
+type WebSubHandler struct {
+	hub SubscriptionHub
+}
+
+// NewWebSubHandler creates a new WebSubHandler instance
+func NewWebSubHandler(hub SubscriptionHub) *WebSubHandler {
+	return &WebSubHandler{
+		hub: hub,
+	}
+}
+
+// Subscribe handles a WebSub subscription or unsubscription request
+// @Summary WebSub hub
+// @Description Subscribe to or unsubscribe from a feed. The hub confirms the request by sending a GET with hub.challenge to the callback, which must echo the challenge. Subscribers then receive the full feed whenever posts change, signed in X-Hub-Signature when a secret was given, until the lease expires. Callbacks on private addresses are refused.
+// @Tags feeds
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param hub.mode formData string true "subscribe or unsubscribe"
+// @Param hub.topic formData string true "Feed URL, e.g. https://blog-api.murray.kiwi/rss"
+// @Param hub.callback formData string true "Subscriber callback URL"
+// @Param hub.secret formData string false "Secret used to sign content deliveries"
+// @Param hub.lease_seconds formData int false "Requested subscription lifetime in seconds"
+// @Success 202 {string} string "Accepted; intent verification follows"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Router /hub [post]
+func (wh *WebSubHandler) Subscribe(c *gin.Context) {
+	req := websub.Request{
+		Mode:     c.PostForm("hub.mode"),
+		Topic:    c.PostForm("hub.topic"),
+		Callback: c.PostForm("hub.callback"),
+		Secret:   c.PostForm("hub.secret"),
+	}
+	if lease := c.PostForm("hub.lease_seconds"); lease != "" {
+		seconds, err := strconv.Atoi(lease)
+		if err != nil || seconds < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "hub.lease_seconds must be a positive integer"})
+			return
+		}
+		req.LeaseSeconds = seconds
+	}
+
+	err := wh.hub.HandleRequest(req)
+	if errors.Is(err, websub.ErrBusy) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
@@ -0,0 +1,147 @@
+package imaging
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"blog-api/persist"
+)
+
+// Cache keeps resized images on disk, evicting the least recently used once
+// their total size exceeds a limit
+type Cache struct {
+	dir      string
+	maxBytes int64
+
+	mu    sync.Mutex
+	files map[string]cacheFile
+	size  int64
+}
+
+// cacheFile is the size and last use of a cached image
+type cacheFile struct {
+	size    int64
+	lastUse time.Time
+}
+
+// NewCache opens the cache in dir, creating it if missing, and accounts for
+// the images already there
+func NewCache(dir string, maxBytes int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		files:    make(map[string]cacheFile),
+	}
+	for _, entry := range entries {
+		// Leftover temporary files from interrupted writes start with a dot
+		if strings.HasPrefix(entry.Name(), ".") {
+			os.Remove(filepath.Join(dir, entry.Name()))
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		c.files[entry.Name()] = cacheFile{size: info.Size(), lastUse: info.ModTime()}
+		c.size += info.Size()
+	}
+	c.evict()
+
+	return c, nil
+}
+
+// Key returns the cache key for a source image, identified by its content
+// hash, resized with opts
+func Key(sourceHash string, opts Options) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%d|%s|%s", sourceHash, opts.Width, opts.Height, opts.Fit, opts.Format)))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Get returns a cached image
+func (c *Cache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	file, ok := c.files[key]
+	if ok {
+		file.lastUse = time.Now()
+		c.files[key] = file
+	}
+	c.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(filepath.Join(c.dir, key))
+	if err != nil {
+		c.mu.Lock()
+		c.remove(key)
+		c.mu.Unlock()
+		return nil, false
+	}
+	// The modification time records the last use across restarts
+	os.Chtimes(filepath.Join(c.dir, key), time.Time{}, file.lastUse)
+	return data, true
+}
+
+// Put stores an image, evicting others if the cache is over its limit
+func (c *Cache) Put(key string, data []byte) error {
+	if err := persist.WriteFileAtomic(filepath.Join(c.dir, key), data); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.remove(key)
+	c.files[key] = cacheFile{size: int64(len(data)), lastUse: time.Now()}
+	c.size += int64(len(data))
+	c.evict()
+	return nil
+}
+
+// evict removes the least recently used images until the cache fits its
+// limit. The caller must hold c.mu, or have sole access.
+func (c *Cache) evict() {
+	if c.maxBytes <= 0 || c.size <= c.maxBytes {
+		return
+	}
+
+	keys := make([]string, 0, len(c.files))
+	for key := range c.files {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return c.files[keys[i]].lastUse.Before(c.files[keys[j]].lastUse)
+	})
+
+	for _, key := range keys {
+		if c.size <= c.maxBytes {
+			break
+		}
+		os.Remove(filepath.Join(c.dir, key))
+		c.remove(key)
+	}
+}
+
+// remove forgets a cached image. The caller must hold c.mu.
+func (c *Cache) remove(key string) {
+	if file, ok := c.files[key]; ok {
+		c.size -= file.size
+		delete(c.files, key)
+	}
+}
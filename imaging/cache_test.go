@@ -0,0 +1,52 @@
+package imaging
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewCache(dir, 10)
+	if err != nil {
+		t.Fatalf("NewCache failed: %v", err)
+	}
+
+	c.Put("a", []byte("aaaa"))
+	time.Sleep(10 * time.Millisecond)
+	c.Put("b", []byte("bbbb"))
+	time.Sleep(10 * time.Millisecond)
+
+	// Using a makes b the least recently used
+	if data, ok := c.Get("a"); !ok || !bytes.Equal(data, []byte("aaaa")) {
+		t.Fatalf("Get returned %q, %v", data, ok)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	c.Put("c", []byte("cccc"))
+	if _, ok := c.Get("b"); ok {
+		t.Error("Expected the least recently used image to be evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("Expected a recently used image to be kept")
+	}
+
+	// A new cache over the same directory picks up what is there
+	reopened, err := NewCache(dir, 10)
+	if err != nil {
+		t.Fatalf("NewCache failed: %v", err)
+	}
+	if data, ok := reopened.Get("c"); !ok || string(data) != "cccc" {
+		t.Errorf("Expected the reopened cache to hold c, got %q, %v", data, ok)
+	}
+}
+
+func TestKey(t *testing.T) {
+	a := Key("hash", Options{Width: 320, Fit: FitContain, Format: FormatJPEG})
+	b := Key("hash", Options{Width: 640, Fit: FitContain, Format: FormatJPEG})
+	c := Key("other", Options{Width: 320, Fit: FitContain, Format: FormatJPEG})
+	if a == b || a == c {
+		t.Error("Expected keys to differ by source and options")
+	}
+}
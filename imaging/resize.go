@@ -0,0 +1,207 @@
+// Package imaging resizes and re-encodes post images
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // GIF sources are decoded too
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // WebP sources are decoded; output is JPEG or PNG
+)
+
+// Fit modes
+const (
+	// FitContain scales the image to fit within the box, keeping its shape
+	FitContain = "contain"
+	// FitCover scales the image to fill the box, cropping the overflow
+	FitCover = "cover"
+	// FitFill stretches the image to the box
+	FitFill = "fill"
+)
+
+// Output formats
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+)
+
+const (
+	// jpegQuality balances size against artefacts for photos
+	jpegQuality = 82
+	// MaxPixels bounds the size of the images decoded, since a small file
+	// can declare enormous dimensions
+	MaxPixels = 50_000_000
+)
+
+var (
+	// ErrUnsupportedFormat is returned for images that cannot be decoded or
+	// formats that cannot be produced
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+
+	// ErrInvalidOptions is returned for options out of range
+	ErrInvalidOptions = errors.New("invalid resize options")
+)
+
+// Options describes a resized image. A zero Width or Height is derived from
+// the other, keeping the source's shape; both zero keeps the source size.
+// Images are never enlarged.
+type Options struct {
+	Width  int
+	Height int
+	Fit    string
+	// Format is jpeg or png; "" keeps JPEG sources as JPEG and converts
+	// everything else to PNG
+	Format string
+}
+
+// Normalize fills in default options and checks the rest, given the
+// source's format as reported by Format
+func (o Options) Normalize(sourceFormat string) (Options, error) {
+	if o.Width < 0 || o.Height < 0 {
+		return o, fmt.Errorf("%w: width and height must be positive", ErrInvalidOptions)
+	}
+
+	switch o.Fit {
+	case "":
+		o.Fit = FitContain
+	case FitContain, FitCover, FitFill:
+	default:
+		return o, fmt.Errorf("%w: fit must be contain, cover or fill", ErrInvalidOptions)
+	}
+
+	switch o.Format {
+	case "":
+		if sourceFormat == FormatJPEG {
+			o.Format = FormatJPEG
+		} else {
+			o.Format = FormatPNG
+		}
+	case "jpg":
+		o.Format = FormatJPEG
+	case FormatJPEG, FormatPNG:
+	default:
+		return o, fmt.Errorf("%w: %s output", ErrUnsupportedFormat, o.Format)
+	}
+
+	return o, nil
+}
+
+// ContentType returns the MIME type of the output format
+func (o Options) ContentType() string {
+	if o.Format == FormatJPEG {
+		return "image/jpeg"
+	}
+	return "image/png"
+}
+
+// Format reports the format of an encoded image, such as jpeg, png, gif or
+// webp, and its dimensions, without decoding it fully
+func Format(src []byte) (string, image.Point, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(src))
+	if err != nil {
+		return "", image.Point{}, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	return format, image.Pt(cfg.Width, cfg.Height), nil
+}
+
+// Resize decodes src, scales it according to opts and encodes the result.
+// opts must have been normalized.
+func Resize(src []byte, opts Options) ([]byte, error) {
+	_, dims, err := Format(src)
+	if err != nil {
+		return nil, err
+	}
+	if dims.X*dims.Y > MaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d is too large", ErrUnsupportedFormat, dims.X, dims.Y)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(src))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+
+	bounds := img.Bounds()
+	size, crop := layout(bounds.Size(), opts)
+	crop = crop.Add(bounds.Min)
+
+	var dst draw.Image
+	if opts.Format == FormatJPEG {
+		// JPEG has no alpha, so transparent areas are flattened onto white
+		rgba := image.NewRGBA(image.Rectangle{Max: size})
+		draw.Draw(rgba, rgba.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		dst = rgba
+	} else {
+		dst = image.NewNRGBA(image.Rectangle{Max: size})
+	}
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, crop, draw.Over, nil)
+
+	var buf bytes.Buffer
+	switch opts.Format {
+	case FormatJPEG:
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: jpegQuality})
+	default:
+		err = (&png.Encoder{CompressionLevel: png.BestCompression}).Encode(&buf, dst)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// layout returns the output size for a source of size src, and the part of
+// the source, relative to its origin, that is scaled to fill it
+func layout(src image.Point, opts Options) (image.Point, image.Rectangle) {
+	full := image.Rectangle{Max: src}
+	w, h := opts.Width, opts.Height
+
+	switch {
+	case w == 0 && h == 0:
+		return src, full
+	case w == 0:
+		w = scale(src.X, h, src.Y)
+	case h == 0:
+		h = scale(src.Y, w, src.X)
+	}
+
+	switch opts.Fit {
+	case FitFill:
+		return image.Pt(min(w, src.X), min(h, src.Y)), full
+
+	case FitCover:
+		// Crop the source to the box's shape, then shrink the crop
+		crop := src
+		if src.X*h > src.Y*w {
+			crop.X = scale(src.Y, w, h)
+		} else {
+			crop.Y = scale(src.X, h, w)
+		}
+		offset := src.Sub(crop).Div(2)
+		if w > crop.X || h > crop.Y {
+			w, h = crop.X, crop.Y
+		}
+		return image.Pt(w, h), image.Rectangle{Min: offset, Max: offset.Add(crop)}
+
+	default:
+		// Shrink to the tighter of the two limits
+		if src.X*h > src.Y*w {
+			h = scale(src.Y, w, src.X)
+		} else {
+			w = scale(src.X, h, src.Y)
+		}
+		if w > src.X || h > src.Y {
+			return src, full
+		}
+		return image.Pt(w, h), full
+	}
+}
+
+// scale returns n*num/den rounded to the nearest pixel, and at least one
+func scale(n, num, den int) int {
+	return max(1, (n*num+den/2)/den)
+}
@@ -0,0 +1,91 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// testPNG encodes a solid image of the given size
+func testPNG(t *testing.T, width, height int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.NRGBA{R: 200, G: 100, B: 50, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode test image: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestLayout(t *testing.T) {
+	src := image.Pt(400, 200)
+	tests := []struct {
+		name string
+		opts Options
+		size image.Point
+		crop image.Rectangle
+	}{
+		{"width only", Options{Width: 100, Fit: FitContain}, image.Pt(100, 50), image.Rect(0, 0, 400, 200)},
+		{"height only", Options{Height: 100, Fit: FitContain}, image.Pt(200, 100), image.Rect(0, 0, 400, 200)},
+		{"contain", Options{Width: 100, Height: 100, Fit: FitContain}, image.Pt(100, 50), image.Rect(0, 0, 400, 200)},
+		{"cover", Options{Width: 100, Height: 100, Fit: FitCover}, image.Pt(100, 100), image.Rect(100, 0, 300, 200)},
+		{"fill", Options{Width: 100, Height: 100, Fit: FitFill}, image.Pt(100, 100), image.Rect(0, 0, 400, 200)},
+		{"never enlarged", Options{Width: 800, Fit: FitContain}, image.Pt(400, 200), image.Rect(0, 0, 400, 200)},
+		{"cover never enlarged", Options{Width: 1000, Height: 1000, Fit: FitCover}, image.Pt(200, 200), image.Rect(100, 0, 300, 200)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size, crop := layout(src, tt.opts)
+			if size != tt.size || crop != tt.crop {
+				t.Errorf("Expected %v from %v, got %v from %v", tt.size, tt.crop, size, crop)
+			}
+		})
+	}
+}
+
+func TestResize(t *testing.T) {
+	src := testPNG(t, 400, 200)
+
+	opts, err := Options{Width: 100, Format: "jpg"}.Normalize(FormatPNG)
+	if err != nil {
+		t.Fatalf("Normalize failed: %v", err)
+	}
+	out, err := Resize(src, opts)
+	if err != nil {
+		t.Fatalf("Resize failed: %v", err)
+	}
+
+	format, size, err := Format(out)
+	if err != nil || format != FormatJPEG || size != image.Pt(100, 50) {
+		t.Errorf("Expected a 100x50 jpeg, got %s %v, %v", format, size, err)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if opts, _ := (Options{}).Normalize(FormatJPEG); opts.Fit != FitContain || opts.Format != FormatJPEG {
+		t.Errorf("Expected contain and jpeg defaults for a JPEG, got %+v", opts)
+	}
+	if opts, _ := (Options{}).Normalize("webp"); opts.Format != FormatPNG {
+		t.Errorf("Expected png for a WebP source, got %+v", opts)
+	}
+	if _, err := (Options{Format: "avif"}).Normalize(FormatJPEG); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat, got %v", err)
+	}
+	if _, err := (Options{Fit: "squash"}).Normalize(FormatJPEG); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Expected ErrInvalidOptions, got %v", err)
+	}
+}
+
+func TestResize_RejectsNonImages(t *testing.T) {
+	if _, err := Resize([]byte("not an image"), Options{Fit: FitContain, Format: FormatPNG}); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat, got %v", err)
+	}
+}
@@ -1,11 +1,26 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"time"
 
+	"blog-api/auth"
+	"blog-api/comments"
+	"blog-api/config"
 	"blog-api/handlers"
+	"blog-api/imaging"
+	"blog-api/metrics"
 	"blog-api/middleware"
+	"blog-api/models"
+	"blog-api/redirects"
+	"blog-api/render"
 	"blog-api/services"
+	"blog-api/store"
+	"blog-api/webhook"
+	"blog-api/webmention"
+	"blog-api/websub"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -29,11 +44,123 @@ import (
 // @host blog-api.murray.kiwi
 // @BasePath /
 
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+
 func main() {
-	postService := services.NewPostService("./posts")
+	cfg := config.Load()
+
+	authenticator, err := newAuthenticator(cfg.Auth)
+	if err != nil {
+		log.Fatalf("Failed to configure authentication: %v", err)
+	}
+
+	postStore, err := newPostStore(cfg)
+	if err != nil {
+		log.Fatalf("Failed to configure content source: %v", err)
+	}
+
+	location, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		log.Fatalf("Failed to load site time zone: %v", err)
+	}
+
+	if _, err := render.ThemeCSS(cfg.HighlightTheme); err != nil {
+		log.Fatalf("Failed to load highlight theme %q: %v", cfg.HighlightTheme, err)
+	}
+
+	postService := services.NewPostServiceWithStore(postStore)
+	postService.SetLocation(location)
+	postService.SetRelatedWeights(services.RelatedWeights{
+		Tags:    cfg.Related.TagWeight,
+		Content: cfg.Related.ContentWeight,
+		Recency: cfg.Related.RecencyWeight,
+	})
+	postService.SetReadingConfig(services.ReadingConfig{
+		WordsPerMinute:     cfg.Reading.WordsPerMinute,
+		CodeWordsPerMinute: cfg.Reading.CodeWordsPerMinute,
+	})
+	postService.SetExcerptLength(cfg.ExcerptLength)
+	postService.SetSiteURL(handlers.SiteURL)
+	if err := postService.SetLanguages(cfg.Languages); err != nil {
+		log.Fatalf("Failed to configure site languages: %v", err)
+	}
+	if cfg.Authors.File != "" {
+		authors, err := services.LoadAuthors(cfg.Authors.File)
+		if err != nil {
+			log.Fatalf("Failed to load authors: %v", err)
+		}
+		if err := postService.SetAuthors(authors); err != nil {
+			log.Fatalf("Failed to load authors: %s: %v", cfg.Authors.File, err)
+		}
+		fmt.Printf("Loaded %d authors from %s\n", len(authors), cfg.Authors.File)
+	}
+	postService.SetDefaultAuthor(cfg.Authors.Default)
+	if cfg.Images.Enabled {
+		postService.SetImageWidths(cfg.Images.Sizes)
+	}
+
+	if gitStore, ok := postStore.(*store.GitStore); ok && cfg.Git.Remote != "" && cfg.Git.SyncInterval > 0 {
+		go gitStore.Run(context.Background(), cfg.Git.SyncInterval)
+	}
+
+	dispatcher, err := newDispatcher(cfg.Webhooks)
+	if err != nil {
+		log.Fatalf("Failed to configure webhooks: %v", err)
+	}
+	if dispatcher != nil {
+		postService.Subscribe(dispatcher.HandlePostEvent)
+		go dispatcher.Run(context.Background())
+	}
+
+	go func() {
+		if err := postService.Watch(context.Background()); err != nil {
+			fmt.Printf("Error watching posts: %v\n", err)
+		}
+	}()
 
 	postHandler := handlers.NewPostHandler(postService)
-	healthHandler := handlers.NewHealthHandler()
+
+	var hub *websub.Hub
+	if cfg.WebSub.Enabled {
+		hub, err = websub.NewHub(websub.HubConfig{
+			URL:          handlers.SiteURL + "/hub",
+			StateFile:    cfg.WebSub.StateFile,
+			DefaultLease: cfg.WebSub.DefaultLease,
+			MaxLease:     cfg.WebSub.MaxLease,
+			MaxPending:   cfg.WebSub.MaxPending,
+			AllowPrivate: cfg.WebSub.AllowPrivate,
+		}, metrics.Default)
+		if err != nil {
+			log.Fatalf("Failed to configure WebSub hub: %v", err)
+		}
+		for _, lang := range append([]string{""}, postService.Languages()...) {
+			hub.AddTopic(handlers.FeedURL(handlers.RSSFeedURL, lang), func() (string, []byte, error) {
+				return postHandler.RenderRSSFeed(lang)
+			})
+			hub.AddTopic(handlers.FeedURL(handlers.AtomFeedURL, lang), func() (string, []byte, error) {
+				return postHandler.RenderAtomFeed(lang)
+			})
+			hub.AddTopic(handlers.FeedURL(handlers.JSONFeedURL, lang), func() (string, []byte, error) {
+				return postHandler.RenderJSONFeed(lang)
+			})
+		}
+		postHandler.SetHubURL(hub.URL())
+		postService.Subscribe(func(models.PostEvent) { hub.Notify() })
+		go hub.Run(context.Background())
+	}
+
+	var revisioner store.Revisioner
+	if r, ok := postStore.(store.Revisioner); ok {
+		revisioner = r
+	}
+	healthHandler := handlers.NewHealthHandler(revisioner)
+	metricsHandler := handlers.NewMetricsHandler(metrics.Default)
 
 	docs.SwaggerInfo.Schemes = []string{"https"}
 
@@ -41,6 +168,14 @@ func main() {
 
 	r.Use(middleware.CORS())
 
+	if cfg.RateLimit.Enabled {
+		limiter, err := newRateLimiter(cfg.RateLimit, authenticator)
+		if err != nil {
+			log.Fatalf("Failed to configure rate limiting: %v", err)
+		}
+		r.Use(middleware.RateLimit(limiter))
+	}
+
 	// Swagger endpoint
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
@@ -55,13 +190,49 @@ func main() {
 		c.JSON(200, gin.H{
 			"message": "Blog API is running!",
 			"endpoints": gin.H{
-				"GET /posts":       "List all blog posts",
-				"GET /posts/:slug": "Get a specific blog post",
-				"GET /rss":         "RSS feed",
-				"GET /health":      "Health check",
-				"GET /health/ready": "Readiness check",
-				"GET /health/live":  "Liveness check",
-				"GET /swagger/":     "API documentation",
+				"GET /posts":                              "List all blog posts",
+				"GET /posts/:slug":                        "Get a specific blog post",
+				"GET /posts/:slug/related":                "Related posts",
+				"GET /posts/:slug/assets/*path":           "Post images and files",
+				"POST /posts":                             "Create a blog post",
+				"PUT /posts/:slug":                        "Replace a blog post",
+				"PATCH /posts/:slug":                      "Update a blog post",
+				"DELETE /posts/:slug":                     "Delete a blog post",
+				"GET /archive":                            "Post counts by year and month",
+				"GET /archive/:year":                      "Posts from a year",
+				"GET /archive/:year/:month":               "Posts from a month",
+				"GET /series":                             "List all series",
+				"GET /series/:name":                       "Get a series",
+				"GET /authors":                            "List all authors",
+				"GET /authors/:id":                        "Get an author and their posts",
+				"GET /rss":                                "RSS feed",
+				"GET /atom":                               "Atom feed",
+				"GET /feed.json":                          "JSON Feed",
+				"GET /assets/highlight.css":               "Syntax highlighting stylesheet",
+				"GET /images/*path":                       "Resized post images",
+				"POST /hub":                               "WebSub hub",
+				"GET /sitemap.xml":                        "Sitemap",
+				"GET /robots.txt":                         "Crawler rules",
+				"GET /health":                             "Health check",
+				"GET /health/ready":                       "Readiness check",
+				"GET /health/live":                        "Liveness check",
+				"POST /hooks/reload":                      "Trigger a content reload",
+				"GET /hooks/reload/:id":                   "Reload status",
+				"GET /webhooks/deliveries":                "Webhook delivery log",
+				"GET /webhooks/deliveries/:id":            "Webhook delivery",
+				"POST /webhooks/deliveries/:id/redeliver": "Redeliver a webhook",
+				"GET /posts/:slug/comments":               "Approved comments on a post",
+				"POST /posts/:slug/comments":              "Comment on a post",
+				"GET /comments":                           "Comment moderation queue",
+				"PATCH /comments/:id":                     "Moderate a comment",
+				"DELETE /comments/:id":                    "Delete a comment",
+				"GET /comments/export":                    "Export comments",
+				"POST /comments/backup":                   "Back up comments",
+				"POST /webmention":                        "Webmention endpoint",
+				"GET /webmention/:id":                     "Webmention status",
+				"GET /posts/:slug/mentions":               "Verified Webmentions of a post",
+				"GET /metrics":                            "Prometheus metrics",
+				"GET /swagger/":                           "API documentation",
 			},
 		})
 	})
@@ -70,15 +241,146 @@ func main() {
 	r.GET("/health", healthHandler.HealthCheck)
 	r.GET("/health/ready", healthHandler.ReadinessCheck)
 	r.GET("/health/live", healthHandler.LivenessCheck)
+	r.GET("/metrics", metricsHandler.GetMetrics)
 
 	r.GET("/posts", postHandler.GetAllPosts)
 	r.GET("/posts/", func(c *gin.Context) {
 		c.JSON(404, gin.H{"error": "Post not found"})
 	})
 	r.GET("/posts/:slug", postHandler.GetPostBySlug)
+	r.GET("/posts/:slug/related", postHandler.GetRelatedPosts)
+
+	requireWrite := middleware.RequireScope(authenticator, auth.ScopePostsWrite)
+	r.POST("/posts", requireWrite, postHandler.CreatePost)
+	r.PUT("/posts/:slug", requireWrite, postHandler.UpdatePost)
+	r.PATCH("/posts/:slug", requireWrite, postHandler.PatchPost)
+	r.DELETE("/posts/:slug", requireWrite, postHandler.DeletePost)
+	archiveHandler := handlers.NewArchiveHandler(postService)
+	r.GET("/archive", archiveHandler.GetArchive)
+	r.GET("/archive/:year", archiveHandler.GetYear)
+	r.GET("/archive/:year/:month", archiveHandler.GetMonth)
+
+	seriesHandler := handlers.NewSeriesHandler(postService)
+	r.GET("/series", seriesHandler.GetAllSeries)
+	r.GET("/series/:name", seriesHandler.GetSeries)
+
+	authorHandler := handlers.NewAuthorHandler(postService)
+	r.GET("/authors", authorHandler.GetAllAuthors)
+	r.GET("/authors/:id", authorHandler.GetAuthor)
+
 	r.GET("/rss", postHandler.GetRSSFeed)
+	r.GET("/atom", postHandler.GetAtomFeed)
+	r.GET("/feed.json", postHandler.GetJSONFeed)
+
+	sitemapHandler := handlers.NewSitemapHandler(postService, handlers.SitemapConfig{
+		BaseURL:    handlers.SiteURL,
+		RobotsFile: cfg.Robots.File,
+		Disallow:   cfg.Robots.Disallow,
+	})
+	r.GET("/sitemap.xml", sitemapHandler.GetSitemap)
+	r.GET("/sitemap.xml.gz", sitemapHandler.GetSitemap)
+	r.GET("/sitemaps/:file", sitemapHandler.GetSitemapPart)
+	r.GET("/robots.txt", sitemapHandler.GetRobots)
+
+	assetsHandler := handlers.NewAssetsHandler(postService, cfg.HighlightTheme)
+	r.GET("/posts/:slug/assets/*path", assetsHandler.GetPostAsset)
+	r.GET("/assets/highlight.css", assetsHandler.GetHighlightCSS)
+
+	if cfg.Images.Enabled {
+		cache, err := imaging.NewCache(cfg.Images.CacheDir, cfg.Images.CacheSize)
+		if err != nil {
+			log.Fatalf("Failed to open image cache: %v", err)
+		}
+		r.GET("/images/*path", handlers.NewImageHandler(postService, cache, cfg.Images.Sizes).GetImage)
+	}
+
+	var redirectTable *redirects.Table
+	if cfg.RedirectsFile != "" {
+		if redirectTable, err = redirects.Load(cfg.RedirectsFile); err != nil {
+			log.Fatalf("Failed to load redirects: %v", err)
+		}
+		fmt.Printf("Loaded %d redirects from %s\n", redirectTable.Len(), cfg.RedirectsFile)
+	}
+	r.NoRoute(handlers.NewRedirectHandler(redirectTable).NotFound)
+
+	if hub != nil {
+		r.POST("/hub", handlers.NewWebSubHandler(hub).Subscribe)
+	}
+
+	if cfg.Hooks.Secret != "" {
+		hookHandler := handlers.NewHookHandler(cfg.Hooks.Secret, services.NewReloadService(postService, cfg.Hooks.Debounce))
+		r.POST("/hooks/reload", hookHandler.TriggerReload)
+		r.GET("/hooks/reload/:id", hookHandler.GetReloadStatus)
+	}
+
+	requireAdmin := middleware.RequireScope(authenticator, auth.ScopeAdmin)
 
-	fmt.Println("Blog API starting on port 8080...")
+	if cfg.Comments.Enabled {
+		commentStore, err := comments.NewStore(comments.StoreConfig{
+			File:           cfg.Comments.File,
+			BackupDir:      cfg.Comments.BackupDir,
+			BackupInterval: cfg.Comments.BackupInterval,
+			BackupKeep:     cfg.Comments.BackupKeep,
+			MaxLength:      cfg.Comments.MaxLength,
+		}, metrics.Default)
+		if err != nil {
+			log.Fatalf("Failed to configure comments: %v", err)
+		}
+		go commentStore.Run(context.Background())
+
+		commentLimiter, err := newCommentRateLimiter(cfg, authenticator)
+		if err != nil {
+			log.Fatalf("Failed to configure comment rate limiting: %v", err)
+		}
+
+		commentHandler := handlers.NewCommentHandler(commentStore, postService)
+		r.GET("/posts/:slug/comments", commentHandler.GetComments)
+		r.POST("/posts/:slug/comments", middleware.RateLimit(commentLimiter), commentHandler.CreateComment)
+		r.GET("/comments", requireAdmin, commentHandler.ListComments)
+		r.GET("/comments/export", requireAdmin, commentHandler.ExportComments)
+		r.POST("/comments/backup", requireAdmin, commentHandler.BackupComments)
+		r.PATCH("/comments/:id", requireAdmin, commentHandler.ModerateComment)
+		r.DELETE("/comments/:id", requireAdmin, commentHandler.DeleteComment)
+	}
+
+	if cfg.Webmention.Enabled {
+		receiver, err := webmention.NewReceiver(webmention.ReceiverConfig{
+			File:         cfg.Webmention.File,
+			Timeout:      cfg.Webmention.Timeout,
+			MaxPending:   cfg.Webmention.MaxPending,
+			RejectedTTL:  cfg.Webmention.RejectedTTL,
+			AllowPrivate: cfg.Webmention.AllowPrivate,
+		}, metrics.Default)
+		if err != nil {
+			log.Fatalf("Failed to configure webmentions: %v", err)
+		}
+		go receiver.Run(context.Background())
+
+		webmentionHandler := handlers.NewWebmentionHandler(receiver, postService, handlers.SiteURL)
+		r.POST("/webmention", webmentionHandler.ReceiveWebmention)
+		r.GET("/webmention/:id", webmentionHandler.GetWebmention)
+		r.GET("/posts/:slug/mentions", webmentionHandler.GetMentions)
+		postHandler.SetWebmentionURL(handlers.SiteURL + "/webmention")
+	}
+
+	if cfg.Webmention.Send {
+		sender := webmention.NewSender(webmention.SenderConfig{
+			SiteURL:      handlers.SiteURL,
+			Timeout:      cfg.Webmention.Timeout,
+			AllowPrivate: cfg.Webmention.AllowPrivate,
+		}, postService, metrics.Default)
+		postService.Subscribe(sender.HandlePostEvent)
+		go sender.Run(context.Background())
+	}
+
+	if dispatcher != nil {
+		webhookHandler := handlers.NewWebhookHandler(dispatcher)
+		r.GET("/webhooks/deliveries", requireAdmin, webhookHandler.ListDeliveries)
+		r.GET("/webhooks/deliveries/:id", requireAdmin, webhookHandler.GetDelivery)
+		r.POST("/webhooks/deliveries/:id/redeliver", requireAdmin, webhookHandler.Redeliver)
+	}
+
+	fmt.Printf("Blog API starting on port %s...\n", cfg.Port)
 	fmt.Println("Endpoints:")
 	fmt.Println("  GET /        - API info")
 	fmt.Println("  GET /health  - Health check")
@@ -86,8 +388,168 @@ func main() {
 	fmt.Println("  GET /health/live  - Liveness check")
 	fmt.Println("  GET /posts   - List all posts")
 	fmt.Println("  GET /posts/:slug - Get specific post")
+	fmt.Println("  GET /posts/:slug/related - Related posts")
+	fmt.Println("  GET /posts/:slug/comments - Approved comments on a post")
+	fmt.Println("  POST /posts/:slug/comments - Comment on a post")
+	fmt.Println("  GET /posts/:slug/mentions - Verified Webmentions of a post")
+	fmt.Println("  GET /posts/:slug/assets/*path - Post images and files")
+	fmt.Println("  POST /posts  - Create post")
+	fmt.Println("  PUT /posts/:slug - Replace post")
+	fmt.Println("  PATCH /posts/:slug - Update post")
+	fmt.Println("  DELETE /posts/:slug - Delete post")
+	fmt.Println("  GET /archive - Post counts by year and month")
+	fmt.Println("  GET /archive/:year - Posts from a year")
+	fmt.Println("  GET /archive/:year/:month - Posts from a month")
+	fmt.Println("  GET /series  - List all series")
+	fmt.Println("  GET /series/:name - Get a series")
+	fmt.Println("  GET /authors - List all authors")
+	fmt.Println("  GET /authors/:id - Get an author and their posts")
 	fmt.Println("  GET /rss     - RSS feed")
+	fmt.Println("  GET /atom    - Atom feed")
+	fmt.Println("  GET /feed.json - JSON Feed")
+	fmt.Println("  GET /assets/highlight.css - Syntax highlighting stylesheet")
+	fmt.Println("  GET /images/*path - Resized post images")
+	fmt.Println("  POST /hub    - WebSub hub")
+	fmt.Println("  POST /webmention - Webmention endpoint")
+	fmt.Println("  GET /webmention/:id - Webmention status")
+	fmt.Println("  GET /sitemap.xml - Sitemap")
+	fmt.Println("  GET /robots.txt - Crawler rules")
+	fmt.Println("  POST /hooks/reload - Trigger a content reload")
+	fmt.Println("  GET /hooks/reload/:id - Reload status")
+	fmt.Println("  GET /webhooks/deliveries - Webhook delivery log")
+	fmt.Println("  GET /webhooks/deliveries/:id - Webhook delivery")
+	fmt.Println("  POST /webhooks/deliveries/:id/redeliver - Redeliver a webhook")
+	fmt.Println("  GET /comments - Comment moderation queue")
+	fmt.Println("  PATCH /comments/:id - Moderate a comment")
+	fmt.Println("  DELETE /comments/:id - Delete a comment")
+	fmt.Println("  GET /comments/export - Export comments")
+	fmt.Println("  POST /comments/backup - Back up comments")
+	fmt.Println("  GET /metrics - Prometheus metrics")
 	fmt.Println("  GET /swagger/ - API documentation")
 
-	r.Run(":8080")
+	r.Run(":" + cfg.Port)
+}
+
+// newPostStore returns the content source selected by cfg.ContentSource:
+// posts on disk, posts embedded at build time, disk posts layered over the
+// embedded ones, or posts committed to a git repository
+func newPostStore(cfg config.Config) (store.PostStore, error) {
+	layout := store.Layout{SlugSources: cfg.Layout.SlugSources, Ignore: cfg.Layout.Ignore}
+	if err := layout.Validate(); err != nil {
+		return nil, err
+	}
+	newFileStore := func() store.PostStore {
+		fileStore := store.NewFileStore(cfg.PostsDir)
+		fileStore.SetLayout(layout)
+		return fileStore
+	}
+	newFSStore := func() store.PostStore {
+		fsStore := store.NewFSStore(embeddedContent, "posts")
+		fsStore.SetLayout(layout)
+		return fsStore
+	}
+
+	switch cfg.ContentSource {
+	case "disk":
+		return newFileStore(), nil
+	case "embedded":
+		return newFSStore(), nil
+	case "overlay":
+		return store.NewOverlayStore(newFileStore(), newFSStore()), nil
+	case "git":
+		return store.NewGitStore(store.GitConfig{
+			Path:   cfg.Git.RepoPath,
+			Dir:    cfg.Git.PostsDir,
+			Branch: cfg.Git.Branch,
+			Remote: cfg.Git.Remote,
+			Layout: &layout,
+		})
+	default:
+		return nil, fmt.Errorf("unknown content source %q", cfg.ContentSource)
+	}
+}
+
+// newAuthenticator builds the authenticator for protected endpoints from the
+// configured API keys file and JWT settings
+func newAuthenticator(cfg config.AuthConfig) (*auth.Authenticator, error) {
+	var apiKeys []auth.APIKey
+	if cfg.APIKeysFile != "" {
+		keys, err := auth.LoadAPIKeys(cfg.APIKeysFile)
+		if err != nil {
+			return nil, err
+		}
+		apiKeys = keys
+	}
+
+	var verifier *auth.JWTVerifier
+	if cfg.JWTSecret != "" || cfg.JWKSFile != "" {
+		v, err := auth.NewJWTVerifier(auth.JWTConfig{
+			Secret:   []byte(cfg.JWTSecret),
+			JWKSFile: cfg.JWKSFile,
+			Issuer:   cfg.JWTIssuer,
+			Audience: cfg.JWTAudience,
+			Leeway:   cfg.JWTLeeway,
+		})
+		if err != nil {
+			return nil, err
+		}
+		verifier = v
+	}
+
+	if len(apiKeys) == 0 && verifier == nil {
+		fmt.Println("Warning: no API keys or JWT settings configured; protected endpoints will reject all requests")
+	}
+
+	return auth.NewAuthenticator(apiKeys, verifier), nil
+}
+
+// newDispatcher builds the outgoing webhook dispatcher from the configured
+// subscriptions file, returning nil when no file is configured
+func newDispatcher(cfg config.WebhooksConfig) (*webhook.Dispatcher, error) {
+	if cfg.File == "" {
+		return nil, nil
+	}
+
+	subs, err := webhook.LoadSubscriptions(cfg.File)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook.NewDispatcher(webhook.DispatcherConfig{
+		Subscriptions:  subs,
+		LogFile:        cfg.LogFile,
+		MaxAttempts:    cfg.MaxAttempts,
+		InitialBackoff: cfg.InitialBackoff,
+		Timeout:        cfg.Timeout,
+	}, metrics.Default)
+}
+
+// newCommentRateLimiter builds the limiter on posting comments, which
+// applies to every client alike on top of the site-wide limits
+func newCommentRateLimiter(cfg config.Config, authenticator *auth.Authenticator) (*middleware.RateLimiter, error) {
+	limit := middleware.Limit{PerMinute: cfg.Comments.PerMinute, Burst: cfg.Comments.Burst}
+	return middleware.NewRateLimiter(middleware.RateLimitConfig{
+		IP:             limit,
+		APIKey:         limit,
+		Routes:         map[string]middleware.Limit{"/posts/:slug/comments": limit},
+		TrustedProxies: cfg.RateLimit.TrustedProxies,
+		MaxClients:     cfg.RateLimit.MaxClients,
+	}, authenticator, metrics.Default)
+}
+
+// newRateLimiter builds the rate limiter from its configuration
+func newRateLimiter(cfg config.RateLimitConfig, authenticator *auth.Authenticator) (*middleware.RateLimiter, error) {
+	routes := make(map[string]middleware.Limit, len(cfg.Routes))
+	for route, limit := range cfg.Routes {
+		routes[route] = middleware.Limit{PerMinute: limit.PerMinute, Burst: limit.Burst}
+	}
+
+	return middleware.NewRateLimiter(middleware.RateLimitConfig{
+		IP:             middleware.Limit{PerMinute: cfg.PerMinute, Burst: cfg.Burst},
+		APIKey:         middleware.Limit{PerMinute: cfg.KeyPerMinute, Burst: cfg.KeyBurst},
+		Routes:         routes,
+		Exempt:         []string{"/health", "/health/ready", "/health/live", "/metrics"},
+		TrustedProxies: cfg.TrustedProxies,
+		MaxClients:     cfg.MaxClients,
+	}, authenticator, metrics.Default)
 }
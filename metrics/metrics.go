@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Registry holds named counters with optional labels
+type Registry struct {
+	mu       sync.Mutex
+	help     map[string]string
+	counters map[string]map[string]uint64
+}
+
+// Default is the registry served at /metrics
+var Default = NewRegistry()
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{
+		help:     make(map[string]string),
+		counters: make(map[string]map[string]uint64),
+	}
+}
+
+// Describe sets the help text for a metric
+func (r *Registry) Describe(name, help string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.help[name] = help
+}
+
+// Inc increments the counter name for the given label pairs, given as
+// alternating keys and values
+func (r *Registry) Inc(name string, labels ...string) {
+	r.Add(name, 1, labels...)
+}
+
+// Add increases the counter name by delta for the given label pairs
+func (r *Registry) Add(name string, delta uint64, labels ...string) {
+	key := formatLabels(labels)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	series, ok := r.counters[name]
+	if !ok {
+		series = make(map[string]uint64)
+		r.counters[name] = series
+	}
+	series[key] += delta
+}
+
+// Get returns the current value of a counter
+func (r *Registry) Get(name string, labels ...string) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.counters[name][formatLabels(labels)]
+}
+
+// WriteText writes all counters in the Prometheus text exposition format
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.counters))
+	for name := range r.counters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if help, ok := r.help[name]; ok {
+			if _, err := fmt.Fprintf(w, "# HELP %s %s\n", name, help); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "# TYPE %s counter\n", name); err != nil {
+			return err
+		}
+
+		series := r.counters[name]
+		keys := make([]string, 0, len(series))
+		for key := range series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if _, err := fmt.Fprintf(w, "%s%s %d\n", name, key, series[key]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// formatLabels renders label pairs as {k="v",...}
+func formatLabels(labels []string) string {
+	if len(labels) < 2 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(labels[i+1])
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, labels[i], value))
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"blog-api/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+const principalKey = "principal"
+
+// RequireScope middleware authenticates the request and rejects it unless
+// the caller holds scope. Every authenticated request is audit logged.
+func RequireScope(authenticator *auth.Authenticator, scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, err := authenticator.Authenticate(c.Request)
+		if err != nil {
+			log.Printf("audit: denied %s %s from %s: %v", c.Request.Method, c.Request.URL.Path, c.ClientIP(), err)
+
+			message := "Invalid credentials"
+			if errors.Is(err, auth.ErrNoCredentials) {
+				message = "Authentication required"
+			}
+			c.Header("WWW-Authenticate", `Bearer realm="blog-api"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": message})
+			return
+		}
+
+		if !principal.HasScope(scope) {
+			log.Printf("audit: %s %s denied %s %s: missing scope %s", principal.Type, principal.ID, c.Request.Method, c.Request.URL.Path, scope)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Missing required scope: " + scope})
+			return
+		}
+
+		c.Set(principalKey, principal)
+		c.Next()
+
+		log.Printf("audit: %s %s %s %s -> %d", principal.Type, principal.ID, c.Request.Method, c.Request.URL.Path, c.Writer.Status())
+	}
+}
+
+// GetPrincipal returns the principal set by RequireScope, if any
+func GetPrincipal(c *gin.Context) (*auth.Principal, bool) {
+	value, ok := c.Get(principalKey)
+	if !ok {
+		return nil, false
+	}
+	principal, ok := value.(*auth.Principal)
+	return principal, ok
+}
@@ -0,0 +1,255 @@
+package middleware
+
+import (
+	"container/list"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"blog-api/auth"
+	"blog-api/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Limit is a token bucket refilling PerMinute tokens a minute up to Burst
+type Limit struct {
+	PerMinute int
+	Burst     int
+}
+
+// RateLimitConfig configures the rate limiter
+type RateLimitConfig struct {
+	// IP applies to anonymous clients, identified by address
+	IP Limit
+	// APIKey applies to clients presenting valid credentials
+	APIKey Limit
+	// Routes overrides the limit for specific route patterns, e.g. "/rss".
+	// Each route gets its own bucket per client.
+	Routes map[string]Limit
+	// Exempt lists route patterns that are never limited, such as probes
+	Exempt []string
+	// TrustedProxies lists the CIDRs whose CF-Connecting-IP and
+	// X-Forwarded-For headers are believed
+	TrustedProxies []string
+	// MaxClients bounds the number of buckets kept; the least recently used
+	// bucket is evicted when it is exceeded
+	MaxClients int
+}
+
+// RateLimiter tracks token buckets per client and route
+type RateLimiter struct {
+	cfg           RateLimitConfig
+	trusted       []*net.IPNet
+	authenticator *auth.Authenticator
+	registry      *metrics.Registry
+	now           func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*list.Element
+	lru     *list.List
+}
+
+// bucket is a single token bucket
+type bucket struct {
+	key    string
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a RateLimiter. authenticator may be nil, in which
+// case every client is limited by address.
+func NewRateLimiter(cfg RateLimitConfig, authenticator *auth.Authenticator, registry *metrics.Registry) (*RateLimiter, error) {
+	rl := &RateLimiter{
+		cfg:           cfg,
+		authenticator: authenticator,
+		registry:      registry,
+		now:           time.Now,
+		buckets:       make(map[string]*list.Element),
+		lru:           list.New(),
+	}
+
+	for _, cidr := range cfg.TrustedProxies {
+		if !strings.Contains(cidr, "/") {
+			if ip := net.ParseIP(cidr); ip != nil && ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+		}
+		rl.trusted = append(rl.trusted, network)
+	}
+
+	if rl.cfg.MaxClients <= 0 {
+		rl.cfg.MaxClients = 10000
+	}
+
+	registry.Describe("blog_api_rate_limit_rejections_total", "Requests rejected by the rate limiter")
+	registry.Describe("blog_api_rate_limit_evictions_total", "Rate limit buckets evicted to bound memory")
+
+	return rl, nil
+}
+
+// RateLimit middleware rejects requests that exceed the client's limit with
+// 429 and reports the client's quota in RateLimit-* headers
+func RateLimit(rl *RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == "OPTIONS" || rl.isExempt(c.FullPath()) {
+			c.Next()
+			return
+		}
+
+		clientType, clientID := rl.identify(c.Request)
+
+		limit := rl.cfg.IP
+		if clientType != "ip" {
+			limit = rl.cfg.APIKey
+		}
+
+		route := "default"
+		if routeLimit, ok := rl.cfg.Routes[c.FullPath()]; ok {
+			route = c.FullPath()
+			limit = routeLimit
+		}
+
+		allowed, remaining, reset, retryAfter := rl.take(clientType+":"+clientID+"|"+route, limit)
+
+		c.Header("RateLimit-Limit", strconv.Itoa(limit.Burst))
+		c.Header("RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(reset))
+
+		if !allowed {
+			rl.registry.Inc("blog_api_rate_limit_rejections_total", "route", route, "client", clientType)
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// isExempt reports whether route is never rate limited
+func (rl *RateLimiter) isExempt(route string) bool {
+	for _, exempt := range rl.cfg.Exempt {
+		if route == exempt {
+			return true
+		}
+	}
+	return false
+}
+
+// identify returns the client type and identifier used to pick a bucket
+func (rl *RateLimiter) identify(r *http.Request) (string, string) {
+	if rl.authenticator != nil && (r.Header.Get("X-API-Key") != "" || r.Header.Get("Authorization") != "") {
+		if principal, err := rl.authenticator.Authenticate(r); err == nil {
+			return principal.Type, principal.ID
+		}
+	}
+	return "ip", rl.clientIP(r)
+}
+
+// take removes a token from the bucket for key. It returns whether the
+// request is allowed, the whole tokens left, the seconds until the bucket is
+// full again and, for rejected requests, the seconds until a token is free.
+func (rl *RateLimiter) take(key string, limit Limit) (bool, int, int, int) {
+	now := rl.now()
+	rate := float64(limit.PerMinute) / 60
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	var b *bucket
+	if elem, ok := rl.buckets[key]; ok {
+		rl.lru.MoveToFront(elem)
+		b = elem.Value.(*bucket)
+		b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+		b.last = now
+	} else {
+		b = &bucket{key: key, tokens: float64(limit.Burst), last: now}
+		rl.buckets[key] = rl.lru.PushFront(b)
+		rl.evict()
+	}
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	reset := 0
+	retryAfter := 0
+	if rate > 0 {
+		reset = int(math.Ceil((float64(limit.Burst) - b.tokens) / rate))
+		if !allowed {
+			retryAfter = int(math.Ceil((1 - b.tokens) / rate))
+		}
+	}
+
+	return allowed, int(b.tokens), reset, retryAfter
+}
+
+// evict drops least recently used buckets beyond MaxClients. The caller must
+// hold rl.mu.
+func (rl *RateLimiter) evict() {
+	for rl.lru.Len() > rl.cfg.MaxClients {
+		oldest := rl.lru.Back()
+		rl.lru.Remove(oldest)
+		delete(rl.buckets, oldest.Value.(*bucket).key)
+		rl.registry.Inc("blog_api_rate_limit_evictions_total")
+	}
+}
+
+// clientIP returns the address of the client. Forwarding headers are only
+// honoured when the request arrives from a trusted proxy.
+func (rl *RateLimiter) clientIP(r *http.Request) string {
+	remote, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remote = r.RemoteAddr
+	}
+
+	if !rl.isTrusted(remote) {
+		return remote
+	}
+
+	if cf := strings.TrimSpace(r.Header.Get("CF-Connecting-IP")); net.ParseIP(cf) != nil {
+		return cf
+	}
+
+	// Walk X-Forwarded-For from the right, skipping our own proxies, so a
+	// client cannot spoof its address by prepending entries
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		if !rl.isTrusted(hop) {
+			return hop
+		}
+	}
+
+	return remote
+}
+
+// isTrusted reports whether addr is within a trusted proxy range
+func (rl *RateLimiter) isTrusted(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, network := range rl.trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
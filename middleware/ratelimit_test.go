@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"blog-api/auth"
+	"blog-api/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestRouter returns a router limited by rl with /posts and /rss routes
+func newTestRouter(rl *RateLimiter) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RateLimit(rl))
+	r.GET("/posts", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/rss", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func doRequest(r *gin.Engine, path, remoteAddr string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", path, nil)
+	req.RemoteAddr = remoteAddr
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimit_TokenBucket(t *testing.T) {
+	registry := metrics.NewRegistry()
+	rl, err := NewRateLimiter(RateLimitConfig{
+		IP:     Limit{PerMinute: 60, Burst: 2},
+		Routes: map[string]Limit{"/rss": {PerMinute: 6, Burst: 1}},
+	}, nil, registry)
+	if err != nil {
+		t.Fatalf("NewRateLimiter failed: %v", err)
+	}
+
+	now := time.Now()
+	rl.now = func() time.Time { return now }
+	r := newTestRouter(rl)
+
+	for i := 0; i < 2; i++ {
+		if w := doRequest(r, "/posts", "1.2.3.4:1000", nil); w.Code != http.StatusOK {
+			t.Fatalf("Request %d: expected 200, got %d", i, w.Code)
+		}
+	}
+
+	w := doRequest(r, "/posts", "1.2.3.4:1000", nil)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429 once the burst is spent, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "1" {
+		t.Errorf("Expected Retry-After 1, got %q", w.Header().Get("Retry-After"))
+	}
+	if w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("Expected RateLimit-Remaining 0, got %q", w.Header().Get("RateLimit-Remaining"))
+	}
+
+	// The stricter /rss limit has its own bucket
+	if w := doRequest(r, "/rss", "1.2.3.4:1000", nil); w.Code != http.StatusOK {
+		t.Errorf("Expected /rss to have a separate bucket, got %d", w.Code)
+	}
+	if w := doRequest(r, "/rss", "1.2.3.4:1000", nil); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected second /rss request to be limited, got %d", w.Code)
+	}
+
+	// Another client is unaffected
+	if w := doRequest(r, "/posts", "5.6.7.8:1000", nil); w.Code != http.StatusOK {
+		t.Errorf("Expected other client to be allowed, got %d", w.Code)
+	}
+
+	// Tokens refill over time
+	now = now.Add(time.Second)
+	if w := doRequest(r, "/posts", "1.2.3.4:1000", nil); w.Code != http.StatusOK {
+		t.Errorf("Expected a refilled token after one second, got %d", w.Code)
+	}
+
+	if got := registry.Get("blog_api_rate_limit_rejections_total", "route", "default", "client", "ip"); got != 1 {
+		t.Errorf("Expected 1 recorded rejection, got %d", got)
+	}
+}
+
+func TestRateLimit_TrustedProxies(t *testing.T) {
+	rl, err := NewRateLimiter(RateLimitConfig{
+		IP:             Limit{PerMinute: 60, Burst: 1},
+		TrustedProxies: []string{"10.0.0.0/8"},
+	}, nil, metrics.NewRegistry())
+	if err != nil {
+		t.Fatalf("NewRateLimiter failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		remote  string
+		headers map[string]string
+		want    string
+	}{
+		{"untrusted peer ignores headers", "1.2.3.4:1", map[string]string{"CF-Connecting-IP": "9.9.9.9"}, "1.2.3.4"},
+		{"cloudflare header", "10.0.0.1:1", map[string]string{"CF-Connecting-IP": "9.9.9.9"}, "9.9.9.9"},
+		{"rightmost untrusted hop", "10.0.0.1:1", map[string]string{"X-Forwarded-For": "6.6.6.6, 8.8.8.8, 10.0.0.2"}, "8.8.8.8"},
+		{"no headers", "10.0.0.1:1", nil, "10.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = tt.remote
+		for k, v := range tt.headers {
+			req.Header.Set(k, v)
+		}
+		if got := rl.clientIP(req); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestRateLimit_APIKeyAndEviction(t *testing.T) {
+	authenticator := auth.NewAuthenticator([]auth.APIKey{
+		{ID: "editor", Hash: auth.HashAPIKey("key"), Scopes: []string{auth.ScopePostsWrite}},
+	}, nil)
+
+	registry := metrics.NewRegistry()
+	rl, err := NewRateLimiter(RateLimitConfig{
+		IP:         Limit{PerMinute: 60, Burst: 1},
+		APIKey:     Limit{PerMinute: 60, Burst: 3},
+		MaxClients: 2,
+	}, authenticator, registry)
+	if err != nil {
+		t.Fatalf("NewRateLimiter failed: %v", err)
+	}
+	r := newTestRouter(rl)
+
+	headers := map[string]string{"X-API-Key": "key"}
+	for i := 0; i < 3; i++ {
+		if w := doRequest(r, "/posts", "1.2.3.4:1", headers); w.Code != http.StatusOK {
+			t.Fatalf("Request %d with API key: expected 200, got %d", i, w.Code)
+		}
+	}
+
+	doRequest(r, "/posts", "2.2.2.2:1", nil)
+	doRequest(r, "/posts", "3.3.3.3:1", nil)
+
+	if got := len(rl.buckets); got != 2 {
+		t.Errorf("Expected buckets to be bounded at 2, got %d", got)
+	}
+	if got := registry.Get("blog_api_rate_limit_evictions_total"); got != 1 {
+		t.Errorf("Expected 1 eviction, got %d", got)
+	}
+}
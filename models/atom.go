@@ -0,0 +1,161 @@
+package models
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+)
+
+// AtomFeed represents an Atom feed
+type AtomFeed struct {
+	Title    string
+	Subtitle string
+	Link     string
+	ID       string
+	Lang     string
+	Updated  time.Time
+	// SelfURL and HubURL advertise the feed's WebSub hub when set
+	SelfURL string
+	HubURL  string
+	Entries []AtomEntry
+}
+
+// AtomEntry represents an Atom feed entry
+type AtomEntry struct {
+	Title      string
+	Link       string
+	ID         string
+	Lang       string
+	Published  time.Time
+	Updated    time.Time
+	Summary    string
+	Content    string
+	Categories []string
+	Authors    []AtomPerson
+}
+
+// AtomPerson represents an Atom author
+type AtomPerson struct {
+	Name string
+	URI  string
+}
+
+// ToXML converts the Atom feed to XML format
+func (f *AtomFeed) ToXML() string {
+	var entries strings.Builder
+	for _, entry := range f.Entries {
+		var categories strings.Builder
+		for _, category := range entry.Categories {
+			categories.WriteString(fmt.Sprintf(`
+		<category term="%s"/>`, html.EscapeString(category)))
+		}
+
+		var authors strings.Builder
+		for _, author := range entry.Authors {
+			uri := ""
+			if author.URI != "" {
+				uri = fmt.Sprintf(`
+			<uri>%s</uri>`, html.EscapeString(author.URI))
+			}
+			authors.WriteString(fmt.Sprintf(`
+		<author>
+			<name>%s</name>%s
+		</author>`, html.EscapeString(author.Name), uri))
+		}
+
+		entries.WriteString(fmt.Sprintf(`
+	<entry%s>
+		<title>%s</title>
+		<link rel="alternate" href="%s"/>
+		<id>%s</id>%s
+		<published>%s</published>
+		<updated>%s</updated>
+		<summary>%s</summary>
+		<content type="text">%s</content>%s
+	</entry>`,
+			xmlLang(entry.Lang, f.Lang),
+			html.EscapeString(entry.Title),
+			html.EscapeString(entry.Link),
+			html.EscapeString(entry.ID),
+			authors.String(),
+			entry.Published.Format(time.RFC3339),
+			entry.Updated.Format(time.RFC3339),
+			html.EscapeString(entry.Summary),
+			html.EscapeString(entry.Content),
+			categories.String(),
+		))
+	}
+
+	var links strings.Builder
+	if f.SelfURL != "" {
+		links.WriteString(fmt.Sprintf(`
+	<link rel="self" type="application/atom+xml" href="%s"/>`, html.EscapeString(f.SelfURL)))
+	}
+	if f.HubURL != "" {
+		links.WriteString(fmt.Sprintf(`
+	<link rel="hub" href="%s"/>`, html.EscapeString(f.HubURL)))
+	}
+
+	updated := f.Updated
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom"%s>
+	<title>%s</title>
+	<subtitle>%s</subtitle>
+	<link rel="alternate" href="%s"/>%s
+	<id>%s</id>
+	<updated>%s</updated>
+	<generator>Blog API</generator>%s
+</feed>`,
+		xmlLang(f.Lang, ""),
+		html.EscapeString(f.Title),
+		html.EscapeString(f.Subtitle),
+		html.EscapeString(f.Link),
+		links.String(),
+		html.EscapeString(f.ID),
+		updated.Format(time.RFC3339),
+		entries.String(),
+	)
+}
+
+// xmlLang returns an xml:lang attribute for lang, or nothing when lang is
+// unset or inherited from the enclosing element
+func xmlLang(lang, inherited string) string {
+	if lang == "" || lang == inherited {
+		return ""
+	}
+	return fmt.Sprintf(` xml:lang="%s"`, html.EscapeString(lang))
+}
+
+// BlogPostToAtomEntry converts a BlogPost to an AtomEntry
+func BlogPostToAtomEntry(post BlogPost, baseURL string) AtomEntry {
+	link := fmt.Sprintf("%s/posts/%s", strings.TrimRight(baseURL, "/"), post.Slug)
+
+	published := time.Time(post.Date)
+	updated := published
+	if t, err := time.Parse(time.RFC3339, post.Updated); err == nil && t.After(published) {
+		updated = t
+	}
+
+	var authors []AtomPerson
+	for _, author := range post.Authors {
+		authors = append(authors, AtomPerson{Name: author.Name, URI: AuthorURL(author, baseURL)})
+	}
+
+	return AtomEntry{
+		Title:      post.Title,
+		Link:       link,
+		ID:         link,
+		Lang:       post.Lang,
+		Published:  published,
+		Updated:    updated,
+		Summary:    post.Excerpt,
+		Content:    post.Content,
+		Categories: post.Tags,
+		Authors:    authors,
+	}
+}
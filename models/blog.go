@@ -1,13 +1,53 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
 
-// Custom time type that serializes as date only
+// DateOnly is a post date. Dates at midnight UTC, as given without a time
+// of day on a UTC site, serialize as YYYY-MM-DD; others keep their time and
+// offset as RFC3339, so they read back as the same instant.
 type DateOnly time.Time
 
+// String formats d as YYYY-MM-DD, or as RFC3339 when it has a time of day
+// or is not in UTC
+func (d DateOnly) String() string {
+	t := time.Time(d)
+	if t.Location() == time.UTC && t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+		return t.Format("2006-01-02")
+	}
+	return t.Format(time.RFC3339)
+}
+
 // MarshalJSON implements the json.Marshaler interface
 func (d DateOnly) MarshalJSON() ([]byte, error) {
-	return []byte(`"` + time.Time(d).Format("2006-01-02") + `"`), nil
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, accepting
+// YYYY-MM-DD, read as midnight UTC, or RFC3339
+func (d *DateOnly) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		if t, err = time.Parse(time.RFC3339, value); err != nil {
+			return err
+		}
+	}
+	*d = DateOnly(t)
+	return nil
 }
 
 // IsZero reports whether d represents the zero time instant
@@ -22,32 +62,269 @@ func (d DateOnly) Equal(u DateOnly) bool {
 
 // BlogPost represents a blog post with metadata
 type BlogPost struct {
-	Slug        string    `json:"slug" example:"hello-world"`
-	Title       string    `json:"title" example:"Hello World"`
-	Date        DateOnly  `json:"date" example:"2024-01-01"`
-	Tags        []string  `json:"tags,omitempty" example:"go,api,blog"`
-	Content     string    `json:"content" example:"This is the full content of the blog post..."`
-	Excerpt     string    `json:"excerpt,omitempty" example:"This is a short excerpt..."`
-	PublishDate string    `json:"publish_date" example:"2024-01-01T12:00:00Z"`
+	Slug           string   `json:"slug" example:"hello-world"`
+	CanonicalURL   string   `json:"canonical_url" example:"https://blog-api.murray.kiwi/posts/hello-world"`
+	Aliases        []string `json:"aliases,omitempty" example:"hello-wrold"`
+	Lang           string   `json:"lang" example:"en-us"`
+	TranslationKey string   `json:"translation_key,omitempty" example:"hello-world"`
+	Title          string   `json:"title" example:"Hello World"`
+	Date           DateOnly `json:"date" example:"2024-01-01"`
+	Tags           []string `json:"tags,omitempty" example:"go,api,blog"`
+	Section        string   `json:"section,omitempty" example:"2025"`
+	Content        string   `json:"content" example:"This is the full content of the blog post..."`
+	HTML           string   `json:"html,omitempty" example:"<p>This is the full content of the blog post...</p>"`
+	Excerpt        string   `json:"excerpt,omitempty" example:"This is a short excerpt..."`
+	// PublishDate is the day of Date, kept for older clients.
+	//
+	// Deprecated: use Date.
+	PublishDate  string     `json:"publish_date" example:"2024-01-01"`
+	Updated      string     `json:"updated,omitempty" example:"2024-01-02T09:30:00Z"`
+	Authors      []Author   `json:"authors,omitempty"`
+	Contributors []string   `json:"contributors,omitempty" example:"Scott Murray"`
+	Series       string     `json:"series,omitempty" example:"Kubernetes from Scratch"`
+	SeriesOrder  int        `json:"series_order,omitempty" example:"2"`
+	WordCount    int        `json:"word_count" example:"1250"`
+	ReadingTime  int        `json:"reading_time" example:"7"`
+	TOC          []TOCEntry `json:"toc,omitempty"`
+	ETag         string     `json:"-"`
+
+	// Navigation, filled in for single-post responses
+	Previous      *PostSummary   `json:"previous,omitempty"`
+	Next          *PostSummary   `json:"next,omitempty"`
+	SeriesContext *SeriesContext `json:"series_context,omitempty"`
+	// Translations lists the post in every language it is written in, for
+	// hreflang alternates
+	Translations []Translation `json:"translations,omitempty"`
 }
 
 // BlogPostMeta represents blog post metadata without content
 type BlogPostMeta struct {
-	Slug        string    `json:"slug" example:"hello-world"`
-	Title       string    `json:"title" example:"Hello World"`
-	Date        DateOnly  `json:"date" example:"2024-01-01"`
-	Tags        []string  `json:"tags,omitempty" example:"go,api,blog"`
-	Excerpt     string    `json:"excerpt,omitempty" example:"This is a short excerpt..."`
-	PublishDate string    `json:"publish_date" example:"2024-01-01T12:00:00Z"`
+	Slug         string      `json:"slug" example:"hello-world"`
+	CanonicalURL string      `json:"canonical_url" example:"https://blog-api.murray.kiwi/posts/hello-world"`
+	Lang         string      `json:"lang" example:"en-us"`
+	Title        string      `json:"title" example:"Hello World"`
+	Date         DateOnly    `json:"date" example:"2024-01-01"`
+	Tags         []string    `json:"tags,omitempty" example:"go,api,blog"`
+	Section      string      `json:"section,omitempty" example:"2025"`
+	Authors      []AuthorRef `json:"authors,omitempty"`
+	Excerpt      string      `json:"excerpt,omitempty" example:"This is a short excerpt..."`
+	// PublishDate is the day of Date, kept for older clients.
+	//
+	// Deprecated: use Date.
+	PublishDate string `json:"publish_date" example:"2024-01-01"`
+	Updated     string `json:"updated,omitempty" example:"2024-01-02T09:30:00Z"`
+	Series      string `json:"series,omitempty" example:"Kubernetes from Scratch"`
+	SeriesOrder int    `json:"series_order,omitempty" example:"2"`
+	WordCount   int    `json:"word_count" example:"1250"`
+	ReadingTime int    `json:"reading_time" example:"7"`
+}
+
+// NewBlogPostMeta returns the metadata of a post
+func NewBlogPostMeta(post BlogPost) BlogPostMeta {
+	return BlogPostMeta{
+		Slug:         post.Slug,
+		CanonicalURL: post.CanonicalURL,
+		Lang:         post.Lang,
+		Title:        post.Title,
+		Date:         post.Date,
+		Tags:         post.Tags,
+		Section:      post.Section,
+		Authors:      NewAuthorRefs(post.Authors),
+		Excerpt:      post.Excerpt,
+		PublishDate:  post.PublishDate,
+		Updated:      post.Updated,
+		Series:       post.Series,
+		SeriesOrder:  post.SeriesOrder,
+		WordCount:    post.WordCount,
+		ReadingTime:  post.ReadingTime,
+	}
+}
+
+// TOCEntry is a heading in a post's table of contents
+type TOCEntry struct {
+	Level    int        `json:"level" example:"2"`
+	Text     string     `json:"text" example:"Getting Started"`
+	ID       string     `json:"id" example:"getting-started"`
+	Children []TOCEntry `json:"children,omitempty"`
+}
+
+// Translation is a post in one language
+type Translation struct {
+	Lang string `json:"lang" example:"mi"`
+	Slug string `json:"slug" example:"hello-world.mi"`
+	URL  string `json:"url" example:"https://blog-api.murray.kiwi/posts/hello-world.mi"`
+}
+
+// PostSummary identifies a post for navigation links
+type PostSummary struct {
+	Slug  string   `json:"slug" example:"hello-world"`
+	Title string   `json:"title" example:"Hello World"`
+	Date  DateOnly `json:"date" example:"2024-01-01"`
+}
+
+// NewPostSummary returns the summary of a post
+func NewPostSummary(post BlogPost) *PostSummary {
+	return &PostSummary{
+		Slug:  post.Slug,
+		Title: post.Title,
+		Date:  post.Date,
+	}
+}
+
+// SeriesPart is a post in a series
+type SeriesPart struct {
+	PostSummary
+	Part int `json:"part" example:"1"`
+}
+
+// SeriesSummary describes a series without its parts
+type SeriesSummary struct {
+	Slug  string `json:"slug" example:"kubernetes-from-scratch"`
+	Name  string `json:"name" example:"Kubernetes from Scratch"`
+	Count int    `json:"count" example:"4"`
+}
+
+// Series is a named sequence of posts, in reading order
+type Series struct {
+	SeriesSummary
+	Parts []SeriesPart `json:"parts"`
+}
+
+// SeriesContext places a post within its series
+type SeriesContext struct {
+	Series
+	Part     int          `json:"part" example:"2"`
+	Previous *PostSummary `json:"previous,omitempty"`
+	Next     *PostSummary `json:"next,omitempty"`
+}
+
+// SeriesListResponse represents the response for listing series
+type SeriesListResponse struct {
+	Series []SeriesSummary `json:"series"`
+	Count  int             `json:"count" example:"2"`
+}
+
+// AuthorRef identifies the author of a post
+type AuthorRef struct {
+	ID   string `json:"id" example:"scott"`
+	Name string `json:"name" example:"Scott Murray"`
+}
+
+// NewAuthorRefs returns references to authors
+func NewAuthorRefs(authors []Author) []AuthorRef {
+	if len(authors) == 0 {
+		return nil
+	}
+	refs := make([]AuthorRef, len(authors))
+	for i, author := range authors {
+		refs[i] = AuthorRef{ID: author.ID, Name: author.Name}
+	}
+	return refs
+}
+
+// Author is a writer's profile
+type Author struct {
+	ID     string       `json:"id" example:"scott"`
+	Name   string       `json:"name" example:"Scott Murray"`
+	Bio    string       `json:"bio,omitempty" example:"Writes about Go and home labs."`
+	Avatar string       `json:"avatar,omitempty" example:"https://blog-api.murray.kiwi/avatars/scott.jpg"`
+	URL    string       `json:"url,omitempty" example:"https://murray.kiwi"`
+	Links  []AuthorLink `json:"links,omitempty"`
+}
+
+// AuthorURL returns the address of an author's own site, or of their page
+// on the blog at baseURL
+func AuthorURL(author Author, baseURL string) string {
+	if author.URL != "" {
+		return author.URL
+	}
+	return fmt.Sprintf("%s/authors/%s", strings.TrimRight(baseURL, "/"), url.PathEscape(author.ID))
+}
+
+// AuthorLink is a link on an author's profile
+type AuthorLink struct {
+	Name string `json:"name" example:"Mastodon"`
+	URL  string `json:"url" example:"https://mastodon.nz/@scott"`
+}
+
+// AuthorSummary is an author with the number of posts they wrote
+type AuthorSummary struct {
+	Author
+	Count int `json:"count" example:"12"`
+}
+
+// AuthorPosts is an author with their posts, newest first
+type AuthorPosts struct {
+	AuthorSummary
+	Posts []BlogPostMeta `json:"posts"`
+}
+
+// AuthorListResponse represents the response for listing authors
+type AuthorListResponse struct {
+	Authors []AuthorSummary `json:"authors"`
+	Count   int             `json:"count" example:"3"`
+}
+
+// Post event types
+const (
+	PostPublished = "post.published"
+	PostUpdated   = "post.updated"
+	PostDeleted   = "post.deleted"
+)
+
+// PostEvent describes a post appearing, changing or disappearing
+type PostEvent struct {
+	Type string       `json:"event" example:"post.published"`
+	Post BlogPostMeta `json:"post"`
+}
+
+// PostInput represents the request body for creating or replacing a post
+type PostInput struct {
+	Slug           string   `json:"slug,omitempty" example:"hello-world"`
+	Title          string   `json:"title" example:"Hello World"`
+	Date           string   `json:"date,omitempty" example:"2024-01-01"`
+	Updated        string   `json:"updated,omitempty" example:"2024-01-02T09:30:00+13:00"`
+	Tags           []string `json:"tags,omitempty" example:"go,api,blog"`
+	Excerpt        string   `json:"excerpt,omitempty" example:"This is a short excerpt..."`
+	Authors        []string `json:"authors,omitempty" example:"scott"`
+	Aliases        []string `json:"aliases,omitempty" example:"hello-wrold"`
+	Lang           string   `json:"lang,omitempty" example:"mi"`
+	TranslationKey string   `json:"translation_key,omitempty" example:"hello-world"`
+	Series         string   `json:"series,omitempty" example:"Kubernetes from Scratch"`
+	SeriesOrder    int      `json:"series_order,omitempty" example:"2"`
+	Content        string   `json:"content" example:"# Hello World"`
+}
+
+// PostPatch represents a partial update to a post; nil fields are left unchanged
+type PostPatch struct {
+	Title          *string   `json:"title,omitempty" example:"Hello World"`
+	Date           *string   `json:"date,omitempty" example:"2024-01-01"`
+	Updated        *string   `json:"updated,omitempty" example:"2024-01-02T09:30:00+13:00"`
+	Tags           *[]string `json:"tags,omitempty" example:"go,api,blog"`
+	Excerpt        *string   `json:"excerpt,omitempty" example:"This is a short excerpt..."`
+	Authors        *[]string `json:"authors,omitempty" example:"scott"`
+	Aliases        *[]string `json:"aliases,omitempty" example:"hello-wrold"`
+	Lang           *string   `json:"lang,omitempty" example:"mi"`
+	TranslationKey *string   `json:"translation_key,omitempty" example:"hello-world"`
+	Series         *string   `json:"series,omitempty" example:"Kubernetes from Scratch"`
+	SeriesOrder    *int      `json:"series_order,omitempty" example:"2"`
+	Content        *string   `json:"content,omitempty" example:"# Hello World"`
 }
 
 // HealthResponse represents the health check response
 type HealthResponse struct {
-	Status    string `json:"status" example:"healthy"`
-	Timestamp string `json:"timestamp" example:"2024-01-01T12:00:00Z"`
-	Uptime    string `json:"uptime" example:"1h23m45s"`
-	Service   string `json:"service" example:"blog-api"`
-	Version   string `json:"version" example:"1.0.0"`
+	Status    string         `json:"status" example:"healthy"`
+	Timestamp string         `json:"timestamp" example:"2024-01-01T12:00:00Z"`
+	Uptime    string         `json:"uptime" example:"1h23m45s"`
+	Service   string         `json:"service" example:"blog-api"`
+	Version   string         `json:"version" example:"1.0.0"`
+	Content   *ContentStatus `json:"content,omitempty"`
+}
+
+// ContentStatus describes the revision of content being served
+type ContentStatus struct {
+	Revision string `json:"revision" example:"4b825dc642cb6eb9a060e54bf8d69288fbee4904"`
+	SyncedAt string `json:"synced_at" example:"2024-01-01T12:00:00Z"`
 }
 
 // ReadinessResponse represents the readiness check response
@@ -68,6 +345,51 @@ type PostsResponse struct {
 	Count int            `json:"count" example:"5"`
 }
 
+// RelatedPost is a post related to another, with its similarity score
+type RelatedPost struct {
+	BlogPostMeta
+	Score float64 `json:"score" example:"0.42"`
+}
+
+// RelatedPostsResponse represents the response for getting related posts
+type RelatedPostsResponse struct {
+	Posts []RelatedPost `json:"posts"`
+	Count int           `json:"count" example:"5"`
+}
+
+// ArchiveMonth is the number of posts in a month
+type ArchiveMonth struct {
+	Month int    `json:"month" example:"6"`
+	Name  string `json:"name" example:"June"`
+	Count int    `json:"count" example:"3"`
+}
+
+// ArchiveYear is the number of posts in a year and in each of its months
+type ArchiveYear struct {
+	Year   int            `json:"year" example:"2025"`
+	Count  int            `json:"count" example:"12"`
+	Months []ArchiveMonth `json:"months"`
+}
+
+// ArchiveResponse represents the response for the post archive
+type ArchiveResponse struct {
+	Years    []ArchiveYear `json:"years"`
+	Total    int           `json:"total" example:"42"`
+	Timezone string        `json:"timezone" example:"Pacific/Auckland"`
+}
+
+// ReloadJob represents a content reload triggered by a webhook
+type ReloadJob struct {
+	ID         string `json:"id" example:"3f2a9c1e8b7d4a6f"`
+	Status     string `json:"status" example:"succeeded"`
+	Requests   int    `json:"requests" example:"3"`
+	Changed    bool   `json:"changed" example:"true"`
+	Error      string `json:"error,omitempty" example:"fetching origin: authentication required"`
+	CreatedAt  string `json:"created_at" example:"2024-01-01T12:00:00Z"`
+	StartedAt  string `json:"started_at,omitempty" example:"2024-01-01T12:00:02Z"`
+	FinishedAt string `json:"finished_at,omitempty" example:"2024-01-01T12:00:03Z"`
+}
+
 // ErrorResponse represents an error response
 type ErrorResponse struct {
 	Error string `json:"error" example:"Something went wrong"`
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// JSONFeedVersion is the JSON Feed specification feeds are written to
+const JSONFeedVersion = "https://jsonfeed.org/version/1.1"
+
+// JSONFeed represents a JSON Feed
+type JSONFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url,omitempty"`
+	FeedURL     string         `json:"feed_url,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Language    string         `json:"language,omitempty"`
+	Hubs        []JSONFeedHub  `json:"hubs,omitempty"`
+	Items       []JSONFeedItem `json:"items"`
+}
+
+// JSONFeedHub advertises a feed's WebSub hub
+type JSONFeedHub struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// JSONFeedItem represents a JSON Feed item
+type JSONFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url"`
+	Title         string           `json:"title"`
+	ContentHTML   string           `json:"content_html,omitempty"`
+	ContentText   string           `json:"content_text,omitempty"`
+	Summary       string           `json:"summary,omitempty"`
+	DatePublished string           `json:"date_published"`
+	DateModified  string           `json:"date_modified,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+	Authors       []JSONFeedAuthor `json:"authors,omitempty"`
+	Language      string           `json:"language,omitempty"`
+}
+
+// JSONFeedAuthor represents a JSON Feed author
+type JSONFeedAuthor struct {
+	Name   string `json:"name"`
+	URL    string `json:"url,omitempty"`
+	Avatar string `json:"avatar,omitempty"`
+}
+
+// BlogPostToJSONFeedItem converts a BlogPost to a JSONFeedItem. The item
+// carries the post's rendered HTML when it has some, and its markdown
+// otherwise.
+func BlogPostToJSONFeedItem(post BlogPost, baseURL string) JSONFeedItem {
+	link := fmt.Sprintf("%s/posts/%s", strings.TrimRight(baseURL, "/"), post.Slug)
+
+	published := time.Time(post.Date)
+	item := JSONFeedItem{
+		ID:            link,
+		URL:           link,
+		Title:         post.Title,
+		Summary:       post.Excerpt,
+		DatePublished: published.Format(time.RFC3339),
+		Tags:          post.Tags,
+		Language:      post.Lang,
+	}
+	if post.HTML != "" {
+		item.ContentHTML = post.HTML
+	} else {
+		item.ContentText = post.Content
+	}
+	if t, err := time.Parse(time.RFC3339, post.Updated); err == nil && t.After(published) {
+		item.DateModified = t.Format(time.RFC3339)
+	}
+	for _, author := range post.Authors {
+		item.Authors = append(item.Authors, JSONFeedAuthor{
+			Name:   author.Name,
+			URL:    AuthorURL(author, baseURL),
+			Avatar: author.Avatar,
+		})
+	}
+
+	return item
+}
@@ -13,7 +13,12 @@ type RSSFeed struct {
 	Link        string
 	Description string
 	Language    string
-	Items       []RSSItem
+	// LastBuildDate is when the content last changed; zero means now
+	LastBuildDate time.Time
+	// SelfURL and HubURL advertise the feed's WebSub hub when set
+	SelfURL string
+	HubURL  string
+	Items   []RSSItem
 }
 
 // RSSItem represents an RSS feed item
@@ -23,44 +28,80 @@ type RSSItem struct {
 	Description string
 	PubDate     string
 	GUID        string
+	// Creators are the names of the item's authors
+	Creators []string
 }
 
 // ToXML converts the RSS feed to XML format
 func (f *RSSFeed) ToXML() string {
 	var items strings.Builder
+	hasCreators := false
 	for _, item := range f.Items {
+		var creators strings.Builder
+		for _, creator := range item.Creators {
+			creators.WriteString(fmt.Sprintf(`
+			<dc:creator>%s</dc:creator>`, html.EscapeString(creator)))
+			hasCreators = true
+		}
+
 		items.WriteString(fmt.Sprintf(`
 		<item>
 			<title>%s</title>
 			<link>%s</link>
 			<description><![CDATA[%s]]></description>
 			<pubDate>%s</pubDate>
-			<guid>%s</guid>
+			<guid>%s</guid>%s
 		</item>`,
 			html.EscapeString(item.Title),
 			html.EscapeString(item.Link),
 			item.Description,
 			item.PubDate,
 			html.EscapeString(item.GUID),
+			creators.String(),
 		))
 	}
 
+	lastBuildDate := f.LastBuildDate
+	if lastBuildDate.IsZero() {
+		lastBuildDate = time.Now()
+	}
+
+	namespaces := ""
+	if f.SelfURL != "" || f.HubURL != "" {
+		namespaces = ` xmlns:atom="http://www.w3.org/2005/Atom"`
+	}
+	if hasCreators {
+		namespaces += ` xmlns:dc="http://purl.org/dc/elements/1.1/"`
+	}
+
+	var links strings.Builder
+	if f.SelfURL != "" {
+		links.WriteString(fmt.Sprintf(`
+		<atom:link rel="self" type="application/rss+xml" href="%s"/>`, html.EscapeString(f.SelfURL)))
+	}
+	if f.HubURL != "" {
+		links.WriteString(fmt.Sprintf(`
+		<atom:link rel="hub" href="%s"/>`, html.EscapeString(f.HubURL)))
+	}
+
 	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0">
+<rss version="2.0"%s>
 	<channel>
 		<title>%s</title>
 		<link>%s</link>
 		<description>%s</description>
 		<language>%s</language>
 		<lastBuildDate>%s</lastBuildDate>
-		<generator>Blog API</generator>%s
+		<generator>Blog API</generator>%s%s
 	</channel>
 </rss>`,
+		namespaces,
 		html.EscapeString(f.Title),
 		html.EscapeString(f.Link),
 		html.EscapeString(f.Description),
 		html.EscapeString(f.Language),
-		time.Now().Format(time.RFC1123Z),
+		lastBuildDate.Format(time.RFC1123Z),
+		links.String(),
 		items.String(),
 	)
 }
@@ -74,11 +115,17 @@ func BlogPostToRSSItem(post BlogPost, baseURL string) RSSItem {
 		description = post.Excerpt
 	}
 
+	var creators []string
+	for _, author := range post.Authors {
+		creators = append(creators, author.Name)
+	}
+
 	return RSSItem{
 		Title:       post.Title,
 		Link:        link,
 		Description: description,
 		PubDate:     time.Time(post.Date).Format(time.RFC1123Z),
 		GUID:        link,
+		Creators:    creators,
 	}
 }
@@ -0,0 +1,56 @@
+// Package netutil holds helpers for handling URLs supplied by clients and
+// fetching them safely
+package netutil
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress is returned when a URL resolves to an address that is
+// not on the public internet
+var ErrPrivateAddress = errors.New("refusing to connect to a private address")
+
+// IsWebURL reports whether raw is an absolute http or https URL
+func IsWebURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// NewClient returns an HTTP client for requesting URLs supplied by others.
+// Unless allowPrivate is set it refuses to connect to loopback, private,
+// link-local and other non-public addresses, checked after DNS resolution
+// so a hostname cannot be used to get around it. Such a client connects
+// directly, since a proxy would hide where requests go.
+func NewClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		transport.Proxy = nil
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublic(ip) {
+				return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+			}
+			return nil
+		}
+	}
+
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// isPublic reports whether ip is a public internet address
+func isPublic(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast()
+}
@@ -0,0 +1,178 @@
+// Package redirects reads site-wide redirect rules for paths that have
+// moved
+package redirects
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Rule redirects requests for one path to another path or URL
+type Rule struct {
+	From   string
+	To     string
+	Status int
+	// Line is where the rule was read from, for error messages
+	Line int
+}
+
+// Table holds redirect rules keyed by the path they match. Chains of rules
+// are collapsed, so each lookup gives the final destination.
+type Table struct {
+	rules map[string]Rule
+}
+
+// Load reads a redirects file. See Parse for the format.
+func Load(path string) (*Table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rules, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	table, err := New(rules)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return table, nil
+}
+
+// Parse reads rules written one per line as "from to [status]", where from
+// is a path, to is a path or absolute URL and status is 301 (the default),
+// 302, 307 or 308. Blank lines and lines starting with # are skipped.
+func Parse(r io.Reader) ([]Rule, error) {
+	var rules []Rule
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("line %d: expected \"from to [status]\"", n)
+		}
+
+		rule := Rule{From: normalize(fields[0]), To: fields[1], Status: http.StatusMovedPermanently, Line: n}
+		if !strings.HasPrefix(rule.From, "/") {
+			return nil, fmt.Errorf("line %d: %q must be a path starting with /", n, fields[0])
+		}
+		if u, err := url.Parse(rule.To); err != nil || (!u.IsAbs() && !strings.HasPrefix(rule.To, "/")) {
+			return nil, fmt.Errorf("line %d: %q must be a path or absolute URL", n, rule.To)
+		}
+		if len(fields) == 3 {
+			status, err := strconv.Atoi(fields[2])
+			if err != nil || !isRedirect(status) {
+				return nil, fmt.Errorf("line %d: status must be 301, 302, 307 or 308", n)
+			}
+			rule.Status = status
+		}
+
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// New builds a table from rules, refusing duplicate paths and loops. A
+// chain of rules is collapsed into one redirect to its end, which keeps the
+// status of its first rule.
+func New(rules []Rule) (*Table, error) {
+	byPath := make(map[string]Rule, len(rules))
+	for _, rule := range rules {
+		rule.From = normalize(rule.From)
+		if first, ok := byPath[rule.From]; ok {
+			return nil, fmt.Errorf("line %d: %s is already redirected on line %d", rule.Line, rule.From, first.Line)
+		}
+		byPath[rule.From] = rule
+	}
+
+	froms := make([]string, 0, len(byPath))
+	for from := range byPath {
+		froms = append(froms, from)
+	}
+	sort.Strings(froms)
+
+	collapsed := make(map[string]Rule, len(byPath))
+	for _, from := range froms {
+		rule := byPath[from]
+		seen := map[string]bool{from: true}
+		chain := []string{from}
+		for {
+			next, ok := byPath[localPath(rule.To)]
+			if !ok {
+				break
+			}
+			if seen[next.From] {
+				return nil, fmt.Errorf("line %d: redirect loop %s -> %s", byPath[from].Line, strings.Join(chain, " -> "), next.From)
+			}
+			seen[next.From] = true
+			chain = append(chain, next.From)
+			rule.To = next.To
+		}
+		collapsed[from] = rule
+	}
+
+	return &Table{rules: collapsed}, nil
+}
+
+// Lookup returns the rule for a request path, if there is one
+func (t *Table) Lookup(path string) (Rule, bool) {
+	if t == nil {
+		return Rule{}, false
+	}
+	rule, ok := t.rules[normalize(path)]
+	return rule, ok
+}
+
+// Len returns the number of rules
+func (t *Table) Len() int {
+	if t == nil {
+		return 0
+	}
+	return len(t.rules)
+}
+
+// normalize drops a trailing slash, so /old/ and /old are one path
+func normalize(path string) string {
+	if len(path) > 1 {
+		return strings.TrimSuffix(path, "/")
+	}
+	return path
+}
+
+// localPath returns the path a destination leads to on this site, or "" for
+// an absolute URL
+func localPath(to string) string {
+	if !strings.HasPrefix(to, "/") || strings.HasPrefix(to, "//") {
+		return ""
+	}
+	u, err := url.Parse(to)
+	if err != nil {
+		return ""
+	}
+	return normalize(u.Path)
+}
+
+// isRedirect reports whether status is a redirect a rule may use
+func isRedirect(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package redirects
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	rules, err := Parse(strings.NewReader(`
+# Old feed locations
+/feed.xml      /rss
+/blog/about/   https://example.com/about  302
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	want := []Rule{
+		{From: "/feed.xml", To: "/rss", Status: 301, Line: 3},
+		{From: "/blog/about", To: "https://example.com/about", Status: 302, Line: 4},
+	}
+	if len(rules) != len(want) {
+		t.Fatalf("Expected %d rules, got %+v", len(want), rules)
+	}
+	for i := range want {
+		if rules[i] != want[i] {
+			t.Errorf("Rule %d: expected %+v, got %+v", i, want[i], rules[i])
+		}
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, input := range []string{
+		"/only-one-field",
+		"/a /b 301 extra",
+		"relative /b",
+		"/a relative",
+		"/a /b 200",
+		"/a /b moved",
+	} {
+		if _, err := Parse(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected an error for %q", input)
+		}
+	}
+}
+
+func TestNew_CollapsesChains(t *testing.T) {
+	table, err := New([]Rule{
+		{From: "/a", To: "/b", Status: 302},
+		{From: "/b", To: "/c?x=1", Status: 301},
+		{From: "/c", To: "/posts/final", Status: 301},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	rule, ok := table.Lookup("/a/")
+	if !ok || rule.To != "/posts/final" || rule.Status != 302 {
+		t.Errorf("Expected /a to go straight to /posts/final with 302, got %+v, %v", rule, ok)
+	}
+	if _, ok := table.Lookup("/posts/final"); ok {
+		t.Error("Expected no rule for the destination")
+	}
+}
+
+func TestNew_RejectsLoopsAndDuplicates(t *testing.T) {
+	tests := map[string][]Rule{
+		"self":      {{From: "/a", To: "/a/", Line: 1}},
+		"cycle":     {{From: "/a", To: "/b", Line: 1}, {From: "/b", To: "/c", Line: 2}, {From: "/c", To: "/a", Line: 3}},
+		"tail":      {{From: "/a", To: "/b", Line: 1}, {From: "/b", To: "/c", Line: 2}, {From: "/c", To: "/b", Line: 3}},
+		"duplicate": {{From: "/a", To: "/b", Line: 1}, {From: "/a/", To: "/c", Line: 2}},
+	}
+	for name, rules := range tests {
+		if _, err := New(rules); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	// Absolute URLs leave the site, so they never loop
+	if _, err := New([]Rule{{From: "/a", To: "https://example.com/a"}}); err != nil {
+		t.Errorf("Expected an external redirect to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// ErrUnknownTheme is returned for a highlighting theme that does not exist
+var ErrUnknownTheme = errors.New("unknown highlight theme")
+
+// DefaultTheme is the highlighting theme used when none is chosen
+const DefaultTheme = "github"
+
+// Themes returns the names of the available highlighting themes
+func Themes() []string {
+	return styles.Names()
+}
+
+// ThemeCSS returns the stylesheet for highlighted code in theme
+func ThemeCSS(theme string) ([]byte, error) {
+	style, ok := styles.Registry[theme]
+	if !ok {
+		return nil, ErrUnknownTheme
+	}
+
+	var buf bytes.Buffer
+	if err := chromahtml.New(chromahtml.WithClasses(true)).WriteCSS(&buf, style); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// codeBlockRenderer renders fenced code with class-based syntax
+// highlighting. The info string may follow the language with attributes in
+// braces: line ranges to highlight, and linenos to number the lines, as in
+// "go {3-5,8 linenos}".
+type codeBlockRenderer struct{}
+
+// RegisterFuncs implements renderer.NodeRenderer
+func (r *codeBlockRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, r.render)
+}
+
+func (r *codeBlockRenderer) render(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	block := node.(*ast.FencedCodeBlock)
+
+	var info string
+	if block.Info != nil {
+		info = string(block.Info.Segment.Value(source))
+	}
+	language, highlight, lineNumbers := parseFenceInfo(info)
+
+	var code strings.Builder
+	lines := block.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		code.Write(line.Value(source))
+	}
+
+	lexer := lexers.Get(language)
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	lexer = chroma.Coalesce(lexer)
+
+	iterator, err := lexer.Tokenise(nil, code.String())
+	if err != nil {
+		return ast.WalkStop, err
+	}
+
+	formatter := chromahtml.New(
+		chromahtml.WithClasses(true),
+		chromahtml.WithLineNumbers(lineNumbers),
+		chromahtml.HighlightLines(highlight),
+	)
+
+	if language == "" {
+		w.WriteString(`<div class="highlight">`)
+	} else {
+		w.WriteString(`<div class="highlight" data-lang="` + html.EscapeString(language) + `">`)
+	}
+	if err := formatter.Format(w, styles.Fallback, iterator); err != nil {
+		return ast.WalkStop, err
+	}
+	w.WriteString("</div>\n")
+
+	return ast.WalkSkipChildren, nil
+}
+
+// parseFenceInfo splits a fence info string into its language, the line
+// ranges to highlight and whether to number lines
+func parseFenceInfo(info string) (string, [][2]int, bool) {
+	info = strings.TrimSpace(info)
+
+	var language, attributes string
+	if start := strings.Index(info, "{"); start >= 0 {
+		language = strings.TrimSpace(info[:start])
+		attributes = strings.Trim(info[start:], "{} ")
+	} else if fields := strings.Fields(info); len(fields) > 0 {
+		language = fields[0]
+	}
+
+	var highlight [][2]int
+	lineNumbers := false
+	for _, attribute := range strings.FieldsFunc(attributes, func(r rune) bool { return r == ' ' || r == ',' }) {
+		if attribute == "linenos" || attribute == "linenos=true" {
+			lineNumbers = true
+			continue
+		}
+
+		from, to, isRange := strings.Cut(attribute, "-")
+		first, err := strconv.Atoi(from)
+		if err != nil {
+			continue
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(to); err != nil || last < first {
+				continue
+			}
+		}
+		highlight = append(highlight, [2]int{first, last})
+	}
+
+	return strings.ToLower(language), highlight, lineNumbers
+}
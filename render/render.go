@@ -0,0 +1,289 @@
+// Package render converts post markdown to HTML
+package render
+
+import (
+	"bytes"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Renderer converts markdown to HTML, highlighting fenced code and giving
+// headings the same anchor IDs as the table of contents
+type Renderer struct {
+	md goldmark.Markdown
+}
+
+// New creates a Renderer
+func New() *Renderer {
+	return &Renderer{
+		md: goldmark.New(
+			goldmark.WithExtensions(extension.GFM),
+			goldmark.WithParserOptions(
+				parser.WithASTTransformers(
+					util.Prioritized(headingIDs{}, 100),
+					util.Prioritized(imageURLs{}, 200),
+				),
+			),
+			goldmark.WithRendererOptions(
+				renderer.WithNodeRenderers(util.Prioritized(&codeBlockRenderer{}, 100)),
+			),
+		),
+	}
+}
+
+// ImageResolver maps the relative URL of an image in a post to the URL it
+// is served from, returning "" to leave it unchanged, and optionally a
+// srcset of resized copies
+type ImageResolver func(dest string) (src, srcset string)
+
+// resolverKey holds the ImageResolver for the document being rendered
+var resolverKey = parser.NewContextKey()
+
+// Render converts markdown to HTML. Raw HTML in the markdown is omitted.
+// Relative image URLs are rewritten by resolve, which may be nil.
+func (r *Renderer) Render(markdown string, resolve ImageResolver) (string, error) {
+	ctx := parser.NewContext()
+	if resolve != nil {
+		ctx.Set(resolverKey, resolve)
+	}
+
+	var buf bytes.Buffer
+	if err := r.md.Convert([]byte(markdown), &buf, parser.WithContext(ctx)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Anchors derives GitHub-style anchor IDs from heading text, adding a
+// numeric suffix to repeats
+type Anchors struct {
+	used map[string]int
+}
+
+// NewAnchors creates an empty set of anchors
+func NewAnchors() *Anchors {
+	return &Anchors{used: make(map[string]int)}
+}
+
+// Anchor returns the anchor ID for a heading
+func (a *Anchors) Anchor(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			b.WriteRune('-')
+		}
+	}
+
+	id := b.String()
+	if n := a.used[id]; n > 0 {
+		a.used[id] = n + 1
+		id += "-" + strconv.Itoa(n)
+	} else {
+		a.used[id] = 1
+	}
+	return id
+}
+
+// Heading is a heading in a document
+type Heading struct {
+	Level int
+	// Text is the heading's plain text
+	Text string
+	// ID is the anchor ID the heading is rendered with
+	ID string
+}
+
+// Headings returns the headings of a document in order, parsed the same
+// way Render parses it so their anchor IDs match the rendered HTML
+func (r *Renderer) Headings(markdown string) []Heading {
+	source := []byte(markdown)
+	doc := r.md.Parser().Parse(text.NewReader(source))
+
+	var headings []Heading
+	ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := node.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+		id, _ := heading.AttributeString("id")
+		idBytes, _ := id.([]byte)
+		headings = append(headings, Heading{
+			Level: heading.Level,
+			Text:  plainText(heading, source),
+			ID:    string(idBytes),
+		})
+		return ast.WalkSkipChildren, nil
+	})
+	return headings
+}
+
+// Paragraphs returns the plain text of each paragraph of a document in
+// order, including those in lists and quotes. Headings, code blocks,
+// tables, images and raw HTML are left out.
+func (r *Renderer) Paragraphs(markdown string) []string {
+	source := []byte(markdown)
+	doc := r.md.Parser().Parse(text.NewReader(source))
+
+	var paragraphs []string
+	ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node.(type) {
+		case *ast.Heading, *ast.CodeBlock, *ast.FencedCodeBlock, *ast.HTMLBlock, *east.Table:
+			return ast.WalkSkipChildren, nil
+		case *ast.Paragraph, *ast.TextBlock:
+			if text := nodeText(node, source, false); text != "" {
+				paragraphs = append(paragraphs, text)
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return paragraphs
+}
+
+// Text returns the plain text of a document's prose, meaning its headings,
+// paragraphs and table cells, and separately the text of its code blocks.
+// Raw HTML is left out of both.
+func (r *Renderer) Text(markdown string) (prose, code string) {
+	source := []byte(markdown)
+	doc := r.md.Parser().Parse(text.NewReader(source))
+
+	var proseText, codeText strings.Builder
+	ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node.(type) {
+		case *ast.HTMLBlock:
+			return ast.WalkSkipChildren, nil
+		case *ast.CodeBlock, *ast.FencedCodeBlock:
+			lines := node.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				segment := lines.At(i)
+				codeText.Write(segment.Value(source))
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.Heading, *ast.Paragraph, *ast.TextBlock, *east.TableCell:
+			proseText.WriteString(plainText(node, source))
+			proseText.WriteByte('\n')
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return proseText.String(), codeText.String()
+}
+
+// headingIDs gives each heading an anchor ID derived from its plain text,
+// matching the IDs in a post's table of contents
+type headingIDs struct{}
+
+// Transform implements parser.ASTTransformer
+func (headingIDs) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	anchors := NewAnchors()
+	ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if heading, ok := node.(*ast.Heading); ok && entering {
+			heading.SetAttributeString("id", []byte(anchors.Anchor(plainText(heading, source))))
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// imageURLs rewrites relative image URLs with the document's ImageResolver
+type imageURLs struct{}
+
+// Transform implements parser.ASTTransformer
+func (imageURLs) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	resolve, ok := pc.Get(resolverKey).(ImageResolver)
+	if !ok {
+		return
+	}
+
+	ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if image, ok := node.(*ast.Image); ok && entering && IsRelativeURL(string(image.Destination)) {
+			src, srcset := resolve(string(image.Destination))
+			if src != "" {
+				image.Destination = []byte(src)
+			}
+			if srcset != "" {
+				image.SetAttributeString("srcset", []byte(srcset))
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// IsRelativeURL reports whether dest is a path relative to the document,
+// rather than an absolute URL, a rooted path or a fragment
+func IsRelativeURL(dest string) bool {
+	if dest == "" || strings.HasPrefix(dest, "/") || strings.HasPrefix(dest, "#") || strings.HasPrefix(dest, "?") {
+		return false
+	}
+	u, err := url.Parse(dest)
+	return err == nil && u.Scheme == "" && u.Host == ""
+}
+
+// plainText returns the text of node's inline content without markup or
+// raw HTML, with backslash escapes and entities resolved as they are when
+// rendered
+func plainText(node ast.Node, source []byte) string {
+	return nodeText(node, source, true)
+}
+
+// nodeText returns the text of node's inline content, including image alt
+// text if images is set
+func nodeText(node ast.Node, source []byte, images bool) string {
+	var b strings.Builder
+	ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		case *ast.Image:
+			if !images {
+				return ast.WalkSkipChildren, nil
+			}
+		case *ast.Text:
+			b.Write(textValue(n.Segment.Value(source), n.IsRaw()))
+			if n.SoftLineBreak() || n.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(textValue(n.Value, n.IsRaw() || n.IsCode()))
+		case *ast.AutoLink:
+			b.Write(n.Label(source))
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(b.String())
+}
+
+// textValue resolves the backslash escapes and entities in text, unless it
+// is raw, as in code spans
+func textValue(value []byte, raw bool) []byte {
+	if raw {
+		return value
+	}
+	value = util.UnescapePunctuations(value)
+	value = util.ResolveNumericReferences(value)
+	return util.ResolveEntityNames(value)
+}
@@ -0,0 +1,169 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFenceInfo(t *testing.T) {
+	tests := []struct {
+		info        string
+		language    string
+		highlight   [][2]int
+		lineNumbers bool
+	}{
+		{"", "", nil, false},
+		{"go", "go", nil, false},
+		{"Go {3-5}", "go", [][2]int{{3, 5}}, false},
+		{"python {1,4-6 linenos}", "python", [][2]int{{1, 1}, {4, 6}}, true},
+		{"{2}", "", [][2]int{{2, 2}}, false},
+		{"js {5-3 x}", "js", nil, false},
+	}
+
+	for _, tt := range tests {
+		language, highlight, lineNumbers := parseFenceInfo(tt.info)
+		if language != tt.language || lineNumbers != tt.lineNumbers || len(highlight) != len(tt.highlight) {
+			t.Errorf("parseFenceInfo(%q) = %q, %v, %v; want %q, %v, %v", tt.info, language, highlight, lineNumbers, tt.language, tt.highlight, tt.lineNumbers)
+			continue
+		}
+		for i := range highlight {
+			if highlight[i] != tt.highlight[i] {
+				t.Errorf("parseFenceInfo(%q) highlight = %v, want %v", tt.info, highlight, tt.highlight)
+				break
+			}
+		}
+	}
+}
+
+func TestRenderHighlightsCode(t *testing.T) {
+	html, err := New().Render("# Intro\n\n```go {2 linenos}\npackage main\nfunc main() {}\n```\n", nil)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	for _, want := range []string{
+		`<h1 id="intro">Intro</h1>`,
+		`<div class="highlight" data-lang="go">`,
+		`<span class="kn">package</span>`,
+		`class="line hl"`,
+		`class="ln"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Render output missing %q:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "style=") {
+		t.Errorf("Render output uses inline styles:\n%s", html)
+	}
+}
+
+func TestRenderUnknownLanguage(t *testing.T) {
+	html, err := New().Render("```nosuchlang\n<b>x</b>\n```\n", nil)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	if !strings.Contains(html, `data-lang="nosuchlang"`) || !strings.Contains(html, "&lt;b&gt;x&lt;/b&gt;") {
+		t.Errorf("unknown language not rendered as escaped plain text:\n%s", html)
+	}
+}
+
+func TestRenderOmitsRawHTML(t *testing.T) {
+	html, err := New().Render("<script>alert(1)</script>\n\ntext\n", nil)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if strings.Contains(html, "<script>") {
+		t.Errorf("raw HTML was rendered:\n%s", html)
+	}
+}
+
+func TestAnchors(t *testing.T) {
+	anchors := NewAnchors()
+	for _, tt := range []struct{ text, want string }{
+		{"Getting Started", "getting-started"},
+		{"What's New?", "whats-new"},
+		{"Getting Started", "getting-started-1"},
+		{"Getting Started", "getting-started-2"},
+	} {
+		if got := anchors.Anchor(tt.text); got != tt.want {
+			t.Errorf("Anchor(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestHeadings(t *testing.T) {
+	markdown := "Intro\n=====\n\n> ## Quoted\n\n    ## indented code\n\n## Use \\*stars\\* &amp; `a\\*b`\n\n## Intro\n"
+	want := []Heading{
+		{Level: 1, Text: "Intro", ID: "intro"},
+		{Level: 2, Text: "Quoted", ID: "quoted"},
+		{Level: 2, Text: "Use *stars* & a\\*b", ID: "use-stars--ab"},
+		{Level: 2, Text: "Intro", ID: "intro-1"},
+	}
+
+	headings := New().Headings(markdown)
+	if len(headings) != len(want) {
+		t.Fatalf("Headings() = %+v, want %+v", headings, want)
+	}
+	for i := range want {
+		if headings[i] != want[i] {
+			t.Errorf("Headings()[%d] = %+v, want %+v", i, headings[i], want[i])
+		}
+	}
+
+	html, err := New().Render(markdown, nil)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	for _, heading := range headings {
+		if !strings.Contains(html, `id="`+heading.ID+`"`) {
+			t.Errorf("Expected rendered HTML to contain heading ID %q:\n%s", heading.ID, html)
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	markdown := "# Title\n\nSee ![a chart](c.png) and [docs](https://x.y) <b>now</b>.\n\n" +
+		"<div>\nhidden words\n</div>\n\n| a | b |\n|---|---|\n| one | two |\n\n" +
+		"    indented()\n\n```go\nfenced()\n```\n\n- ~~list~~ item\n"
+
+	prose, code := New().Text(markdown)
+	if got := strings.Fields(prose); strings.Join(got, " ") != "Title See a chart and docs now. a b one two list item" {
+		t.Errorf("Text() prose = %q", prose)
+	}
+	if code != "indented()\nfenced()\n" {
+		t.Errorf("Text() code = %q", code)
+	}
+}
+
+func TestThemeCSS(t *testing.T) {
+	css, err := ThemeCSS(DefaultTheme)
+	if err != nil {
+		t.Fatalf("ThemeCSS(%q) failed: %v", DefaultTheme, err)
+	}
+	if !strings.Contains(string(css), ".chroma") {
+		t.Errorf("stylesheet has no .chroma rules:\n%s", css)
+	}
+
+	if _, err := ThemeCSS("no-such-theme"); err != ErrUnknownTheme {
+		t.Errorf("ThemeCSS(unknown) error = %v, want ErrUnknownTheme", err)
+	}
+}
+
+func TestIsRelativeURL(t *testing.T) {
+	for dest, want := range map[string]bool{
+		"photo.jpg":                 true,
+		"./img/photo.jpg":           true,
+		"../assets/logo.png":        true,
+		"/images/photo.jpg":         false,
+		"https://example.com/x.jpg": false,
+		"//cdn.example.com/x.jpg":   false,
+		"data:image/png;base64,AA":  false,
+		"#top":                      false,
+		"":                          false,
+	} {
+		if got := IsRelativeURL(dest); got != want {
+			t.Errorf("IsRelativeURL(%q) = %v, want %v", dest, got, want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ResolveAlias returns the slug of the post that lists alias among its
+// former slugs, or ErrPostNotFound
+func (ps *PostService) ResolveAlias(alias string) (string, error) {
+	if err := ps.refresh(); err != nil {
+		return "", err
+	}
+
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	slug, ok := ps.aliases[alias]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrPostNotFound, alias)
+	}
+	return slug, nil
+}
+
+// lockedRebuildAliases maps every post's aliases to its slug. Aliases are
+// only ever followed to a post, never to another alias, so they cannot
+// loop: an alias that is the slug of a post is ignored, and one claimed by
+// several posts goes to the first by slug. Both are reported. The caller
+// must hold ps.mu.
+func (ps *PostService) lockedRebuildAliases() {
+	slugs := make([]string, 0, len(ps.index))
+	for slug := range ps.index {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+
+	aliases := make(map[string]string)
+	for _, slug := range slugs {
+		for _, alias := range ps.index[slug].post.Aliases {
+			if alias == slug {
+				continue
+			}
+			if _, exists := ps.index[alias]; exists {
+				fmt.Printf("Warning: alias %q of %s is the slug of another post; ignoring it\n", alias, slug)
+				continue
+			}
+			if other, claimed := aliases[alias]; claimed {
+				fmt.Printf("Warning: alias %q is claimed by %s and %s; redirecting to %s\n", alias, other, slug, other)
+				continue
+			}
+			aliases[alias] = slug
+		}
+	}
+
+	ps.aliases = aliases
+}
+
+// parseAliases parses a list of former slugs. Aliases may be written as
+// paths, /posts/old-slug/, which are reduced to the slug.
+func parseAliases(value string) []string {
+	var aliases []string
+	for _, alias := range parseTags(value) {
+		alias = strings.Trim(alias, "/")
+		alias = strings.TrimPrefix(alias, "posts/")
+		if alias != "" && !strings.Contains(alias, "/") {
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"blog-api/models"
+	"blog-api/store"
+)
+
+func TestResolveAlias(t *testing.T) {
+	memory := store.NewMemoryStore()
+	memory.Put("current", []byte("---\ntitle: \"Current\"\naliases: [\"old\", \"/posts/older/\", \"current\", \"other\"]\n---\n\nBody"))
+	memory.Put("other", []byte("---\ntitle: \"Other\"\naliases: [\"current\", \"old\"]\n---\n\nBody"))
+	service := NewPostServiceWithStore(memory)
+	service.SetSiteURL("https://blog.example/")
+
+	for alias, want := range map[string]string{"old": "current", "older": "current"} {
+		if slug, err := service.ResolveAlias(alias); err != nil || slug != want {
+			t.Errorf("ResolveAlias(%q) = %q, %v; want %q", alias, slug, err, want)
+		}
+	}
+
+	// Slugs of real posts are never aliases, so aliases cannot loop
+	for _, alias := range []string{"current", "other", "missing"} {
+		if _, err := service.ResolveAlias(alias); !errors.Is(err, ErrPostNotFound) {
+			t.Errorf("ResolveAlias(%q): expected ErrPostNotFound, got %v", alias, err)
+		}
+	}
+
+	post, err := service.GetPostBySlug("current")
+	if err != nil {
+		t.Fatalf("GetPostBySlug failed: %v", err)
+	}
+	if post.CanonicalURL != "https://blog.example/posts/current" {
+		t.Errorf("Unexpected canonical URL %q", post.CanonicalURL)
+	}
+}
+
+func TestUpdatePost_RenameKeepsAlias(t *testing.T) {
+	service := NewPostService(t.TempDir())
+
+	post, _ := service.CreatePost(models.PostInput{Title: "Typo Nmae", Date: "2025-06-05", Aliases: []string{"first-draft"}})
+	if _, err := service.UpdatePost(post.Slug, models.PostInput{Slug: "typo-name", Title: "Typo Name"}, ""); err != nil {
+		t.Fatalf("UpdatePost failed: %v", err)
+	}
+
+	renamed, err := service.GetPostBySlug("typo-name")
+	if err != nil {
+		t.Fatalf("GetPostBySlug failed: %v", err)
+	}
+	if want := []string{"first-draft", "typo-nmae"}; !reflect.DeepEqual(renamed.Aliases, want) {
+		t.Errorf("Expected aliases %v, got %v", want, renamed.Aliases)
+	}
+	if slug, err := service.ResolveAlias("typo-nmae"); err != nil || slug != "typo-name" {
+		t.Errorf("Expected the old slug to resolve to the new one, got %q, %v", slug, err)
+	}
+
+	// Renaming back drops the alias that is now the slug
+	if _, err := service.UpdatePost("typo-name", models.PostInput{Slug: "typo-nmae", Title: "Typo Name"}, ""); err != nil {
+		t.Fatalf("UpdatePost failed: %v", err)
+	}
+	back, _ := service.GetPostBySlug("typo-nmae")
+	if want := []string{"first-draft", "typo-name"}; !reflect.DeepEqual(back.Aliases, want) {
+		t.Errorf("Expected aliases %v, got %v", want, back.Aliases)
+	}
+
+	_, err = service.CreatePost(models.PostInput{Title: "Bad", Date: "2025-06-05", Aliases: []string{"Not A Slug"}})
+	if !errors.Is(err, ErrInvalidPost) {
+		t.Errorf("Expected ErrInvalidPost for an invalid alias, got %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"blog-api/models"
+)
+
+// GetArchive returns post counts grouped by year and month in location,
+// newest first
+func (ps *PostService) GetArchive(location *time.Location) (models.ArchiveResponse, error) {
+	posts, err := ps.GetAllPosts(false)
+	if err != nil {
+		return models.ArchiveResponse{}, err
+	}
+
+	counts := make(map[int]map[time.Month]int)
+	for _, post := range posts {
+		date := time.Time(post.Date).In(location)
+		if counts[date.Year()] == nil {
+			counts[date.Year()] = make(map[time.Month]int)
+		}
+		counts[date.Year()][date.Month()]++
+	}
+
+	archive := models.ArchiveResponse{
+		Years: make([]models.ArchiveYear, 0, len(counts)),
+		Total: len(posts),
+	}
+	for year, months := range counts {
+		entry := models.ArchiveYear{Year: year}
+		for month, count := range months {
+			entry.Months = append(entry.Months, models.ArchiveMonth{
+				Month: int(month),
+				Name:  month.String(),
+				Count: count,
+			})
+			entry.Count += count
+		}
+		sort.Slice(entry.Months, func(i, j int) bool {
+			return entry.Months[i].Month > entry.Months[j].Month
+		})
+		archive.Years = append(archive.Years, entry)
+	}
+	sort.Slice(archive.Years, func(i, j int) bool {
+		return archive.Years[i].Year > archive.Years[j].Year
+	})
+
+	return archive, nil
+}
+
+// GetPostsInPeriod returns the posts dated within year, or within month of
+// year when month is not zero, in location, newest first
+func (ps *PostService) GetPostsInPeriod(year, month int, location *time.Location) ([]models.BlogPost, error) {
+	posts, err := ps.GetAllPosts(false)
+	if err != nil {
+		return nil, err
+	}
+
+	var matching []models.BlogPost
+	for _, post := range posts {
+		date := time.Time(post.Date).In(location)
+		if date.Year() == year && (month == 0 || int(date.Month()) == month) {
+			matching = append(matching, post)
+		}
+	}
+	return matching, nil
+}
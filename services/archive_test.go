@@ -0,0 +1,48 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"blog-api/store"
+)
+
+func TestArchive(t *testing.T) {
+	memory := store.NewMemoryStore()
+	memory.Put("new-year", []byte("---\ntitle: \"New Year\"\ndate: \"2025-01-31T20:00:00Z\"\n---\n\nBody"))
+	memory.Put("january", []byte("---\ntitle: \"January\"\ndate: \"2025-01-15\"\n---\n\nBody"))
+	memory.Put("last-year", []byte("---\ntitle: \"Last Year\"\ndate: \"2024-12-31\"\n---\n\nBody"))
+
+	service := NewPostServiceWithStore(memory)
+
+	archive, err := service.GetArchive(time.UTC)
+	if err != nil {
+		t.Fatalf("GetArchive failed: %v", err)
+	}
+	if archive.Total != 3 || len(archive.Years) != 2 || archive.Years[0].Year != 2025 || archive.Years[0].Count != 2 {
+		t.Errorf("Unexpected archive %+v", archive)
+	}
+
+	// Thirteen hours ahead, the late-January post falls in February
+	nzdt := time.FixedZone("NZDT", 13*60*60)
+	service.SetLocation(nzdt)
+
+	archive, _ = service.GetArchive(nzdt)
+	months := archive.Years[0].Months
+	if len(months) != 2 || months[0].Month != 2 || months[0].Name != "February" || months[1].Count != 1 {
+		t.Errorf("Unexpected months %+v", months)
+	}
+
+	// Dates without a time belong to the same day in any site time zone
+	posts, err := service.GetPostsInPeriod(2024, 12, nzdt)
+	if err != nil {
+		t.Fatalf("GetPostsInPeriod failed: %v", err)
+	}
+	if len(posts) != 1 || posts[0].Slug != "last-year" {
+		t.Errorf("Unexpected December posts %+v", posts)
+	}
+
+	if posts, _ := service.GetPostsInPeriod(2025, 0, nzdt); len(posts) != 2 {
+		t.Errorf("Expected 2 posts in 2025, got %d", len(posts))
+	}
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"blog-api/imaging"
+	"blog-api/render"
+	"blog-api/store"
+)
+
+// Asset is a file served alongside a post, with a hash of its content
+type Asset struct {
+	store.Asset
+	Hash string
+}
+
+// GetAsset returns a file from a post's bundle or the shared assets
+// directory
+func (ps *PostService) GetAsset(slug, name string) (Asset, error) {
+	if err := ps.refresh(); err != nil {
+		return Asset{}, err
+	}
+
+	ps.mu.RLock()
+	_, exists := ps.index[slug]
+	ps.mu.RUnlock()
+	if !exists {
+		return Asset{}, fmt.Errorf("%w: %s", ErrPostNotFound, slug)
+	}
+
+	asset, err := ps.readAsset(slug, name)
+	if err != nil {
+		return Asset{}, err
+	}
+	return Asset{Asset: asset, Hash: assetHash(asset.Content)}, nil
+}
+
+// readAsset reads an asset from the store, if it holds assets
+func (ps *PostService) readAsset(slug, name string) (store.Asset, error) {
+	assets, ok := ps.store().(store.AssetStore)
+	if !ok {
+		return store.Asset{}, fmt.Errorf("%w: %s", ErrAssetNotFound, name)
+	}
+
+	asset, err := assets.Asset(slug, name)
+	if errors.Is(err, store.ErrNotFound) {
+		return store.Asset{}, fmt.Errorf("%w: %s", ErrAssetNotFound, name)
+	}
+	return asset, err
+}
+
+// SetImageWidths sets the widths offered in the srcset of images in
+// rendered post HTML, served resized from /images. Without any, images have
+// no srcset. Posts already indexed are re-rendered on the next refresh.
+func (ps *PostService) SetImageWidths(widths []int) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	ps.imageWidths = append([]int(nil), widths...)
+	sort.Ints(ps.imageWidths)
+	ps.lockedInvalidate()
+}
+
+// lockedAssetResolver returns a resolver rewriting relative image URLs in
+// slug's content to content-hashed asset URLs, with a srcset of the widths
+// narrower than the image. Images that are not found are left alone. The
+// caller must hold ps.mu.
+func (ps *PostService) lockedAssetResolver(slug string) render.ImageResolver {
+	base := ps.siteURL
+	widths := ps.imageWidths
+	return func(dest string) (string, string) {
+		u, err := url.Parse(dest)
+		if err != nil {
+			return "", ""
+		}
+
+		for _, name := range assetNames(u.Path) {
+			asset, err := ps.readAsset(slug, name)
+			if err != nil {
+				continue
+			}
+			hash := assetHash(asset.Content)
+
+			src := &url.URL{
+				Path:     "/posts/" + slug + "/assets/" + name,
+				RawQuery: "v=" + hash,
+				Fragment: u.Fragment,
+			}
+			return base + src.String(), imageSrcset(base, slug, name, hash, asset.Content, widths)
+		}
+		return "", ""
+	}
+}
+
+// imageSrcset lists resized copies of an image at each width narrower than
+// it, followed by the image itself. Images that cannot be resized have none.
+func imageSrcset(base, slug, name, hash string, content []byte, widths []int) string {
+	if len(widths) == 0 {
+		return ""
+	}
+	_, size, err := imaging.Format(content)
+	if err != nil {
+		return ""
+	}
+
+	var candidates []string
+	for _, width := range widths {
+		if width >= size.X {
+			break
+		}
+		resized := &url.URL{
+			Path:     "/images/" + slug + "/" + name,
+			RawQuery: "w=" + strconv.Itoa(width) + "&v=" + hash,
+		}
+		candidates = append(candidates, fmt.Sprintf("%s%s %dw", base, resized.String(), width))
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	original := &url.URL{
+		Path:     "/posts/" + slug + "/assets/" + name,
+		RawQuery: "v=" + hash,
+	}
+	candidates = append(candidates, fmt.Sprintf("%s%s %dw", base, original.String(), size.X))
+	return strings.Join(candidates, ", ")
+}
+
+// assetNames returns the asset names a relative path in a post may refer
+// to. Shared assets can be referenced as assets/<name> from a flat post or
+// ../assets/<name> from a bundle, as well as by name alone.
+func assetNames(p string) []string {
+	p = path.Clean(p)
+	names := []string{p}
+	for _, prefix := range []string{"assets/", "../assets/"} {
+		if name, ok := strings.CutPrefix(p, prefix); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// assetHash returns a short hash of an asset's content, for cache busting
+func assetHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:8])
+}
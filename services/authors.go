@@ -0,0 +1,190 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"blog-api/models"
+)
+
+// LoadAuthors reads author profiles from a JSON file holding a list of
+// authors
+func LoadAuthors(path string) ([]models.Author, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var authors []models.Author
+	if err := json.Unmarshal(data, &authors); err != nil {
+		return nil, fmt.Errorf("parsing authors file: %w", err)
+	}
+	return authors, nil
+}
+
+// SetAuthors sets the profiles of the site's authors. Every author needs an
+// ID, unique among them; one without a name is named by their ID. Posts
+// already indexed are re-parsed on the next refresh.
+func (ps *PostService) SetAuthors(authors []models.Author) error {
+	byID := make(map[string]models.Author, len(authors))
+	for _, author := range authors {
+		author.ID = strings.TrimSpace(author.ID)
+		if author.ID == "" {
+			return fmt.Errorf("author %q has no id", author.Name)
+		}
+		if _, exists := byID[author.ID]; exists {
+			return fmt.Errorf("author %q is listed more than once", author.ID)
+		}
+		if author.Name == "" {
+			author.Name = author.ID
+		}
+		byID[author.ID] = author
+	}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	ps.authors = byID
+	ps.lockedInvalidate()
+	return nil
+}
+
+// SetDefaultAuthor sets the author of posts that do not name one, by ID or
+// name. An empty author leaves those posts without an author.
+func (ps *PostService) SetDefaultAuthor(author string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	ps.defaultAuthor = strings.TrimSpace(author)
+	ps.lockedInvalidate()
+}
+
+// GetAllAuthors returns every author with a profile or a post, by name,
+// with the number of posts each wrote
+func (ps *PostService) GetAllAuthors() ([]models.AuthorSummary, error) {
+	if err := ps.refresh(); err != nil {
+		return nil, err
+	}
+
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	byID := make(map[string]*models.AuthorSummary)
+	for id, author := range ps.authors {
+		byID[id] = &models.AuthorSummary{Author: author}
+	}
+	for _, entry := range ps.index {
+		for _, author := range entry.post.Authors {
+			summary, ok := byID[author.ID]
+			if !ok {
+				summary = &models.AuthorSummary{Author: author}
+				byID[author.ID] = summary
+			}
+			summary.Count++
+		}
+	}
+
+	summaries := make([]models.AuthorSummary, 0, len(byID))
+	for _, summary := range byID {
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		a, b := strings.ToLower(summaries[i].Name), strings.ToLower(summaries[j].Name)
+		if a != b {
+			return a < b
+		}
+		return summaries[i].ID < summaries[j].ID
+	})
+	return summaries, nil
+}
+
+// GetAuthor returns an author and their posts, newest first
+func (ps *PostService) GetAuthor(id string) (models.AuthorPosts, error) {
+	if err := ps.refresh(); err != nil {
+		return models.AuthorPosts{}, err
+	}
+
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	author, found := ps.authors[id]
+	var posts []models.BlogPost
+	for _, entry := range ps.index {
+		for _, a := range entry.post.Authors {
+			if a.ID == id {
+				if !found {
+					author, found = a, true
+				}
+				posts = append(posts, entry.post)
+				break
+			}
+		}
+	}
+	if !found {
+		return models.AuthorPosts{}, fmt.Errorf("%w: %s", ErrAuthorNotFound, id)
+	}
+
+	sort.Slice(posts, func(i, j int) bool {
+		return time.Time(posts[i].Date).After(time.Time(posts[j].Date))
+	})
+
+	result := models.AuthorPosts{
+		AuthorSummary: models.AuthorSummary{Author: author, Count: len(posts)},
+		Posts:         make([]models.BlogPostMeta, 0, len(posts)),
+	}
+	for _, post := range posts {
+		result.Posts = append(result.Posts, models.NewBlogPostMeta(post))
+	}
+	return result, nil
+}
+
+// lockedAddAuthors replaces the authors named in a post's frontmatter with
+// their profiles, giving a post that names none the default author. A name
+// with no profile becomes an author of its own, with an ID made from the
+// name. The caller must hold ps.mu.
+func (ps *PostService) lockedAddAuthors(post *models.BlogPost) {
+	names := make([]string, 0, len(post.Authors))
+	for _, author := range post.Authors {
+		names = append(names, author.ID)
+	}
+	if len(names) == 0 && ps.defaultAuthor != "" {
+		names = append(names, ps.defaultAuthor)
+	}
+
+	post.Authors = nil
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		author, ok := ps.lockedAuthor(name)
+		if !ok && len(ps.authors) > 0 {
+			fmt.Printf("Warning: post %s names author %q, who has no profile\n", post.Slug, name)
+		}
+		if seen[author.ID] {
+			continue
+		}
+		seen[author.ID] = true
+		post.Authors = append(post.Authors, author)
+	}
+}
+
+// lockedAuthor finds the profile of an author by ID, by the ID their name
+// would be slugified to, or by name. Without a profile it returns a bare
+// author and false. The caller must hold ps.mu.
+func (ps *PostService) lockedAuthor(name string) (models.Author, bool) {
+	if author, ok := ps.authors[name]; ok {
+		return author, true
+	}
+	id := slugify(name)
+	if author, ok := ps.authors[id]; ok {
+		return author, true
+	}
+	for _, author := range ps.authors {
+		if strings.EqualFold(author.Name, name) {
+			return author, true
+		}
+	}
+	return models.Author{ID: id, Name: name}, false
+}
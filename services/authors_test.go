@@ -0,0 +1,125 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"blog-api/models"
+	"blog-api/store"
+)
+
+func TestAuthors(t *testing.T) {
+	memory := store.NewMemoryStore()
+	memory.Put("first", []byte("---\ntitle: \"First\"\ndate: \"2025-01-01\"\nauthor: scott\n---\n\nOne"))
+	memory.Put("second", []byte("---\ntitle: \"Second\"\ndate: \"2025-02-01\"\nauthors: [\"Scott Murray\", \"Jane Doe\"]\n---\n\nTwo"))
+	memory.Put("third", []byte("---\ntitle: \"Third\"\ndate: \"2025-03-01\"\n---\n\nThree"))
+
+	service := NewPostServiceWithStore(memory)
+	if err := service.SetAuthors([]models.Author{
+		{ID: "scott", Name: "Scott Murray", URL: "https://murray.kiwi"},
+		{ID: "guest"},
+	}); err != nil {
+		t.Fatalf("SetAuthors failed: %v", err)
+	}
+	service.SetDefaultAuthor("scott")
+
+	second, err := service.GetPostBySlug("second")
+	if err != nil {
+		t.Fatalf("GetPostBySlug failed: %v", err)
+	}
+	if len(second.Authors) != 2 || second.Authors[0].ID != "scott" || second.Authors[0].URL != "https://murray.kiwi" {
+		t.Errorf("Expected Scott's profile found by name, got %+v", second.Authors)
+	}
+	if jane := second.Authors[1]; jane.ID != "jane-doe" || jane.Name != "Jane Doe" {
+		t.Errorf("Expected an author without a profile to be named as given, got %+v", second.Authors[1])
+	}
+
+	if third, _ := service.GetPostBySlug("third"); len(third.Authors) != 1 || third.Authors[0].ID != "scott" {
+		t.Errorf("Expected the default author, got %+v", third.Authors)
+	}
+
+	scott, err := service.GetAuthor("scott")
+	if err != nil {
+		t.Fatalf("GetAuthor failed: %v", err)
+	}
+	if scott.Count != 3 || scott.Posts[0].Slug != "third" || scott.Posts[2].Slug != "first" {
+		t.Errorf("Expected Scott's three posts newest first, got %+v", scott)
+	}
+
+	all, err := service.GetAllAuthors()
+	if err != nil {
+		t.Fatalf("GetAllAuthors failed: %v", err)
+	}
+	var names []string
+	for _, author := range all {
+		names = append(names, author.ID)
+	}
+	if strings.Join(names, ",") != "guest,jane-doe,scott" || all[0].Count != 0 || all[1].Count != 1 {
+		t.Errorf("Unexpected authors %+v", all)
+	}
+
+	if _, err := service.GetAuthor("nobody"); !errors.Is(err, ErrAuthorNotFound) {
+		t.Errorf("Expected ErrAuthorNotFound, got %v", err)
+	}
+
+	rss, _ := service.GenerateRSSFeed("Blog", "https://example.com", "", "")
+	if xml := rss.ToXML(); !strings.Contains(xml, "<dc:creator>Jane Doe</dc:creator>") || !strings.Contains(xml, `xmlns:dc=`) {
+		t.Errorf("Expected dc:creator in the RSS feed, got %s", xml)
+	}
+
+	atom, _ := service.GenerateAtomFeed("Blog", "https://example.com", "", "")
+	if xml := atom.ToXML(); !strings.Contains(xml, "<uri>https://example.com/authors/jane-doe</uri>") {
+		t.Errorf("Expected Jane's author page in the Atom feed, got %s", xml)
+	}
+
+	feed, _ := service.GenerateJSONFeed("Blog", "https://example.com", "", "")
+	if len(feed.Items) != 3 || feed.Items[1].Title != "Second" {
+		t.Fatalf("Unexpected JSON feed items %+v", feed.Items)
+	}
+	authors := feed.Items[1].Authors
+	if len(authors) != 2 || authors[0].URL != "https://murray.kiwi" || authors[1].URL != "https://example.com/authors/jane-doe" {
+		t.Errorf("Unexpected JSON feed authors %+v", authors)
+	}
+	if feed.Items[1].ContentHTML != "<p>Two</p>\n" {
+		t.Errorf("Expected rendered HTML in the JSON feed, got %q", feed.Items[1].ContentHTML)
+	}
+}
+
+func TestSetAuthors_Invalid(t *testing.T) {
+	service := NewPostServiceWithStore(store.NewMemoryStore())
+	if err := service.SetAuthors([]models.Author{{Name: "No ID"}}); err == nil {
+		t.Error("Expected an error for an author without an id")
+	}
+	if err := service.SetAuthors([]models.Author{{ID: "a"}, {ID: "a"}}); err == nil {
+		t.Error("Expected an error for a duplicate id")
+	}
+}
+
+func TestPatchPost_Authors(t *testing.T) {
+	memory := store.NewMemoryStore()
+	memory.Put("hello", []byte("---\ntitle: \"Hello\"\ndate: \"2025-01-01\"\nauthor: scott\n---\n\nHi"))
+	service := NewPostServiceWithStore(memory)
+
+	// Patching other fields leaves the author key alone
+	title := "Hi there"
+	if _, err := service.PatchPost("hello", models.PostPatch{Title: &title}, ""); err != nil {
+		t.Fatalf("PatchPost failed: %v", err)
+	}
+	doc, _ := memory.Get("hello")
+	if !strings.Contains(string(doc.Content), `author: "scott"`) {
+		t.Errorf("Expected the author key to be kept, got %s", doc.Content)
+	}
+
+	post, err := service.PatchPost("hello", models.PostPatch{Authors: &[]string{"scott", "Jane Doe"}}, "")
+	if err != nil {
+		t.Fatalf("PatchPost failed: %v", err)
+	}
+	if len(post.Authors) != 2 || post.Authors[1].ID != "jane-doe" {
+		t.Errorf("Expected two authors, got %+v", post.Authors)
+	}
+	doc, _ = memory.Get("hello")
+	if content := string(doc.Content); strings.Contains(content, "author:") || !strings.Contains(content, `authors: ["scott", "Jane Doe"]`) {
+		t.Errorf("Expected an authors list, got %s", content)
+	}
+}
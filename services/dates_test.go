@@ -0,0 +1,143 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"blog-api/models"
+	"blog-api/store"
+)
+
+func TestPostDates(t *testing.T) {
+	memory := store.NewMemoryStore()
+	memory.Put("timed", []byte("---\ntitle: \"Timed\"\ndate: \"2025-06-05 09:30\"\nupdated: \"2025-06-07T08:00:00+02:00\"\n---\n\nBody"))
+	memory.Put("dated", []byte("---\ntitle: \"Dated\"\ndate: \"2025-06-01\"\n---\n\nBody"))
+
+	auckland := time.FixedZone("NZST", 12*60*60)
+	service := NewPostServiceWithStore(memory)
+	service.SetLocation(auckland)
+
+	post, err := service.GetPostBySlug("timed")
+	if err != nil {
+		t.Fatalf("GetPostBySlug failed: %v", err)
+	}
+
+	// Times without an offset are in the site time zone
+	expected := time.Date(2025, 6, 5, 9, 30, 0, 0, auckland)
+	if !time.Time(post.Date).Equal(expected) {
+		t.Errorf("Expected %v, got %v", expected, time.Time(post.Date))
+	}
+	if post.Updated != "2025-06-07T08:00:00+02:00" {
+		t.Errorf("Expected the frontmatter updated time, got %q", post.Updated)
+	}
+
+	data, _ := json.Marshal(post)
+	if !strings.Contains(string(data), `"date":"2025-06-05T09:30:00+12:00"`) {
+		t.Errorf("Expected the date to keep its time and offset, got %s", data)
+	}
+
+	var decoded models.BlogPost
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to decode post: %v", err)
+	}
+	if !time.Time(decoded.Date).Equal(expected) {
+		t.Errorf("Expected the date to round-trip, got %v", time.Time(decoded.Date))
+	}
+
+	// Outside UTC a date-only post keeps its offset, so it reads back as
+	// the same instant
+	dated, _ := service.GetPostBySlug("dated")
+	if data, _ := json.Marshal(dated.Date); string(data) != `"2025-06-01T00:00:00+12:00"` {
+		t.Errorf("Expected a date-only post to keep the site offset, got %s", data)
+	}
+
+	feed, err := service.GenerateAtomFeed("Blog", "https://blog.example", "", "")
+	if err != nil {
+		t.Fatalf("GenerateAtomFeed failed: %v", err)
+	}
+	if len(feed.Entries) != 2 || !feed.Entries[0].Updated.Equal(time.Date(2025, 6, 7, 6, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected entries %+v", feed.Entries)
+	}
+	if !feed.Updated.Equal(feed.Entries[0].Updated) {
+		t.Errorf("Expected the feed to be updated with its newest entry, got %v", feed.Updated)
+	}
+
+	// Replacing a post keeps its date as written
+	if _, err := service.UpdatePost("dated", models.PostInput{Title: "Dated"}, ""); err != nil {
+		t.Fatalf("UpdatePost failed: %v", err)
+	}
+	if doc, _ := memory.Get("dated"); !strings.Contains(string(doc.Content), `date: "2025-06-01"`) {
+		t.Errorf("Expected the date to be written back unchanged, got:\n%s", doc.Content)
+	}
+
+	// Replacing a post keeps its updated time unless a new one is given
+	post, err = service.UpdatePost("timed", models.PostInput{Title: "Timed", Content: "Edited"}, "")
+	if err != nil {
+		t.Fatalf("UpdatePost failed: %v", err)
+	}
+	if post.Updated != "2025-06-07T08:00:00+02:00" {
+		t.Errorf("Expected the updated time to survive a replace, got %q", post.Updated)
+	}
+	updated := "2025-06-08 10:00"
+	post, err = service.PatchPost("timed", models.PostPatch{Updated: &updated}, "")
+	if err != nil {
+		t.Fatalf("PatchPost failed: %v", err)
+	}
+	if post.Updated != "2025-06-08T10:00:00+12:00" {
+		t.Errorf("Expected the patched updated time, got %q", post.Updated)
+	}
+	invalid := "yesterday"
+	if _, err := service.PatchPost("timed", models.PostPatch{Updated: &invalid}, ""); !errors.Is(err, ErrInvalidPost) {
+		t.Errorf("Expected an invalid updated time to be refused, got %v", err)
+	}
+
+	// Editing a post keeps its time of day
+	title := "Retitled"
+	post, err = service.PatchPost("timed", models.PostPatch{Title: &title}, "")
+	if err != nil {
+		t.Fatalf("PatchPost failed: %v", err)
+	}
+	if !time.Time(post.Date).Equal(expected) {
+		t.Errorf("Expected the date to survive an edit, got %v", time.Time(post.Date))
+	}
+}
+
+func TestPostDates_RoundTrip(t *testing.T) {
+	memory := store.NewMemoryStore()
+	memory.Put("dated", []byte("---\ntitle: \"Dated\"\ndate: \"2025-06-01\"\n---\n\nBody"))
+	memory.Put("midnight", []byte("---\ntitle: \"Midnight\"\ndate: \"2025-06-01T00:00:00+12:00\"\n---\n\nBody"))
+	memory.Put("timed", []byte("---\ntitle: \"Timed\"\ndate: \"2025-06-05 09:30\"\n---\n\nBody"))
+
+	for _, location := range []*time.Location{time.UTC, time.FixedZone("NZST", 12*60*60)} {
+		service := NewPostServiceWithStore(memory)
+		service.SetLocation(location)
+
+		for _, slug := range []string{"dated", "midnight", "timed"} {
+			post, err := service.GetPostBySlug(slug)
+			if err != nil {
+				t.Fatalf("GetPostBySlug(%s) failed: %v", slug, err)
+			}
+
+			data, err := json.Marshal(post.Date)
+			if err != nil {
+				t.Fatalf("Failed to encode %s: %v", slug, err)
+			}
+			var decoded models.DateOnly
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("Failed to decode %s: %v", data, err)
+			}
+			if !decoded.Equal(post.Date) {
+				t.Errorf("%s in %s: expected %v to round-trip, got %s as %v", slug, location, time.Time(post.Date), data, time.Time(decoded))
+			}
+		}
+	}
+
+	service := NewPostServiceWithStore(memory)
+	dated, _ := service.GetPostBySlug("dated")
+	if data, _ := json.Marshal(dated.Date); string(data) != `"2025-06-01"` {
+		t.Errorf("Expected a date-only post on a UTC site to serialize as a date, got %s", data)
+	}
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"errors"
+
+	"blog-api/store"
+)
+
+var (
+	// ErrPostNotFound is returned when no post exists for a slug
+	ErrPostNotFound = errors.New("post not found")
+
+	// ErrPostExists is returned when a write would overwrite another post
+	ErrPostExists = errors.New("post already exists")
+
+	// ErrPreconditionFailed is returned when an If-Match ETag no longer
+	// matches the stored post
+	ErrPreconditionFailed = errors.New("post has been modified")
+
+	// ErrInvalidPost is returned when post input fails validation
+	ErrInvalidPost = errors.New("invalid post")
+
+	// ErrSeriesNotFound is returned when no posts belong to a series
+	ErrSeriesNotFound = errors.New("series not found")
+
+	// ErrAuthorNotFound is returned when no profile or post names an author
+	ErrAuthorNotFound = errors.New("author not found")
+
+	// ErrAssetNotFound is returned when a post has no asset with a name
+	ErrAssetNotFound = errors.New("asset not found")
+
+	// ErrInvalidLanguage is returned for a malformed language tag
+	ErrInvalidLanguage = errors.New("invalid language tag")
+
+	// ErrReadOnly is returned when writing to a read-only content source
+	ErrReadOnly = store.ErrReadOnly
+)
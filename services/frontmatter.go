@@ -0,0 +1,137 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+)
+
+var frontmatterRegex = regexp.MustCompile(`(?s)^---\s*\n(.*?)\n---\s*\n(.*)`)
+
+// frontmatterField is a single key/value pair from a post's frontmatter
+type frontmatterField struct {
+	Key   string
+	Value string
+}
+
+// splitFrontmatter separates the frontmatter block from the markdown body.
+// Documents without frontmatter are returned as markdown only.
+func splitFrontmatter(content string) (string, string) {
+	matches := frontmatterRegex.FindStringSubmatch(content)
+	if len(matches) == 3 {
+		return matches[1], matches[2]
+	}
+	return "", content
+}
+
+// parseFrontmatter parses "key: value" lines in document order, stripping
+// matching surrounding quotes from values
+func parseFrontmatter(frontmatter string) []frontmatterField {
+	var fields []frontmatterField
+
+	for _, line := range strings.Split(frontmatter, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		key := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[1])
+
+		if len(value) >= 2 && ((strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"")) ||
+			(strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'"))) {
+			value = value[1 : len(value)-1]
+		}
+
+		fields = append(fields, frontmatterField{Key: key, Value: value})
+	}
+
+	return fields
+}
+
+// parseTags parses a tag list written either as ["a", "b"] or as a, b
+func parseTags(value string) []string {
+	var tags []string
+
+	bracketed := strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]")
+	if bracketed {
+		value = strings.Trim(value, "[]")
+	}
+
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimSpace(tag)
+		if bracketed {
+			tag = strings.Trim(tag, "\"'")
+		}
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// formatTags renders a tag list in the bracketed form read by parseTags
+func formatTags(tags []string) string {
+	quoted := make([]string, len(tags))
+	for i, tag := range tags {
+		quoted[i] = `"` + tag + `"`
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// setField replaces the value of key, appending it if absent. An empty value
+// removes the key.
+func setField(fields []frontmatterField, key, value string) []frontmatterField {
+	for i, field := range fields {
+		if field.Key != key {
+			continue
+		}
+		if value == "" {
+			return append(fields[:i], fields[i+1:]...)
+		}
+		fields[i].Value = value
+		return fields
+	}
+	if value == "" {
+		return fields
+	}
+	return append(fields, frontmatterField{Key: key, Value: value})
+}
+
+// getField returns the value of key, or "" if it is not set
+func getField(fields []frontmatterField, key string) string {
+	for _, field := range fields {
+		if field.Key == key {
+			return field.Value
+		}
+	}
+	return ""
+}
+
+// renderDocument writes frontmatter fields and a markdown body back out as a
+// post file. List values are written verbatim; everything else is quoted.
+func renderDocument(fields []frontmatterField, markdown string) []byte {
+	var b strings.Builder
+
+	if len(fields) > 0 {
+		b.WriteString("---\n")
+		for _, field := range fields {
+			value := field.Value
+			if !(strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]")) {
+				value = `"` + value + `"`
+			}
+			b.WriteString(field.Key + ": " + value + "\n")
+		}
+		b.WriteString("---\n\n")
+	}
+
+	b.WriteString(strings.TrimSpace(markdown))
+	b.WriteString("\n")
+
+	return []byte(b.String())
+}
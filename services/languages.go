@@ -0,0 +1,153 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"blog-api/models"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultLanguage is the language of posts that do not give one, until
+// SetLanguages says otherwise
+const DefaultLanguage = "en-us"
+
+// SetLanguages sets the languages the site is written in, the first being
+// the language of posts that do not give one. A post file named
+// <slug>.<lang>.md with one of these languages is the translation of slug
+// into lang. Posts already indexed are re-parsed on the next refresh.
+func (ps *PostService) SetLanguages(languages []string) error {
+	normalized := make([]string, 0, len(languages))
+	for _, lang := range languages {
+		lang, err := NormalizeLanguage(lang)
+		if err != nil {
+			return err
+		}
+		normalized = append(normalized, lang)
+	}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	ps.languages = normalized
+	ps.lockedInvalidate()
+	return nil
+}
+
+// Languages returns the languages the site is written in, the default
+// first
+func (ps *PostService) Languages() []string {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	return append([]string(nil), ps.lockedLanguages()...)
+}
+
+// lockedLanguages returns the site's languages. The caller must hold ps.mu.
+func (ps *PostService) lockedLanguages() []string {
+	if len(ps.languages) == 0 {
+		return []string{DefaultLanguage}
+	}
+	return ps.languages
+}
+
+// GetTranslations returns every translation of the post with slug, or of
+// the posts whose translation key is slug, in the site's default language
+// first and then by language
+func (ps *PostService) GetTranslations(slug string) ([]models.Translation, error) {
+	if err := ps.refresh(); err != nil {
+		return nil, err
+	}
+
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	key := slug
+	if entry, ok := ps.index[slug]; ok {
+		key = entry.post.TranslationKey
+	}
+
+	translations := ps.lockedTranslations(key)
+	if len(translations) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrPostNotFound, slug)
+	}
+	return translations, nil
+}
+
+// lockedTranslations returns the posts sharing a translation key. The
+// caller must hold ps.mu.
+func (ps *PostService) lockedTranslations(key string) []models.Translation {
+	var translations []models.Translation
+	for _, entry := range ps.index {
+		if entry.post.TranslationKey == key {
+			translations = append(translations, models.Translation{
+				Lang: entry.post.Lang,
+				Slug: entry.post.Slug,
+				URL:  entry.post.CanonicalURL,
+			})
+		}
+	}
+
+	defaultLang := ps.lockedLanguages()[0]
+	sort.Slice(translations, func(i, j int) bool {
+		a, b := translations[i], translations[j]
+		if (a.Lang == defaultLang) != (b.Lang == defaultLang) {
+			return a.Lang == defaultLang
+		}
+		if a.Lang != b.Lang {
+			return a.Lang < b.Lang
+		}
+		return a.Slug < b.Slug
+	})
+	return translations
+}
+
+// lockedAddLanguage fills in a post's language and translation key where
+// its frontmatter leaves them out. A slug ending in .<lang>, for one of the
+// site's languages or the post's own, gives both; otherwise the post is in
+// the default language and its slug is its key. The caller must hold ps.mu.
+func (ps *PostService) lockedAddLanguage(post *models.BlogPost) {
+	languages := ps.lockedLanguages()
+	if post.Lang != "" {
+		languages = append([]string{post.Lang}, languages...)
+	}
+
+	key, suffix := post.Slug, ""
+	for _, lang := range languages {
+		if base, ok := strings.CutSuffix(post.Slug, "."+lang); ok && base != "" {
+			key, suffix = base, lang
+			break
+		}
+	}
+
+	if post.Lang == "" {
+		post.Lang = suffix
+	}
+	if post.Lang == "" {
+		post.Lang = ps.lockedLanguages()[0]
+	}
+	if post.TranslationKey == "" {
+		post.TranslationKey = key
+	}
+}
+
+// NormalizeLanguage checks that tag is a BCP 47 language tag, such as mi
+// or en-NZ, and returns it in lower case
+func NormalizeLanguage(tag string) (string, error) {
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+	if _, err := language.Parse(tag); err != nil || tag == "" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidLanguage, tag)
+	}
+	return strings.ToLower(tag), nil
+}
+
+// MatchesLanguage reports whether a post in lang is wanted by a request for
+// want. A request without a region, such as en, matches every region.
+func MatchesLanguage(lang, want string) bool {
+	want, err := NormalizeLanguage(want)
+	if err != nil {
+		return false
+	}
+	return lang == want || strings.HasPrefix(lang, want+"-")
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"blog-api/models"
+	"blog-api/store"
+)
+
+// newLanguageTestService returns a service over posts in English and te reo
+// Māori, with translations linked by file name and by translation key
+func newLanguageTestService(t *testing.T) *PostService {
+	t.Helper()
+	memory := store.NewMemoryStore()
+	memory.Put("hello", []byte("---\ntitle: \"Hello\"\ndate: \"2025-06-01\"\n---\n\nHi"))
+	memory.Put("hello.mi", []byte("---\ntitle: \"Kia ora\"\ndate: \"2025-06-02\"\n---\n\nKia ora"))
+	memory.Put("welcome", []byte("---\ntitle: \"Welcome\"\ndate: \"2025-06-03\"\ntranslation_key: \"greeting\"\n---\n\nWelcome"))
+	memory.Put("nau-mai", []byte("---\ntitle: \"Nau mai\"\ndate: \"2025-06-04\"\nlang: \"mi\"\ntranslation_key: \"greeting\"\n---\n\nNau mai"))
+	memory.Put("release.go", []byte("---\ntitle: \"Release\"\ndate: \"2025-06-05\"\n---\n\nNot a language"))
+
+	service := NewPostServiceWithStore(memory)
+	if err := service.SetLanguages([]string{"en-NZ", "mi"}); err != nil {
+		t.Fatalf("SetLanguages failed: %v", err)
+	}
+	return service
+}
+
+func TestPostLanguages(t *testing.T) {
+	service := newLanguageTestService(t)
+
+	tests := map[string][2]string{
+		"hello":      {"en-nz", "hello"},
+		"hello.mi":   {"mi", "hello"},
+		"welcome":    {"en-nz", "greeting"},
+		"nau-mai":    {"mi", "greeting"},
+		"release.go": {"en-nz", "release.go"},
+	}
+	for slug, want := range tests {
+		post, err := service.GetPostBySlug(slug)
+		if err != nil {
+			t.Fatalf("GetPostBySlug(%q) failed: %v", slug, err)
+		}
+		if post.Lang != want[0] || post.TranslationKey != want[1] {
+			t.Errorf("%s: expected lang %q and key %q, got %q and %q", slug, want[0], want[1], post.Lang, post.TranslationKey)
+		}
+	}
+
+	post, _ := service.GetPostBySlug("hello.mi")
+	want := []models.Translation{
+		{Lang: "en-nz", Slug: "hello", URL: "/posts/hello"},
+		{Lang: "mi", Slug: "hello.mi", URL: "/posts/hello.mi"},
+	}
+	if !reflect.DeepEqual(post.Translations, want) {
+		t.Errorf("Expected translations %+v, got %+v", want, post.Translations)
+	}
+	if post, _ := service.GetPostBySlug("release.go"); post.Translations != nil {
+		t.Errorf("Expected no translations for an untranslated post, got %+v", post.Translations)
+	}
+
+	translations, err := service.GetTranslations("greeting")
+	if err != nil || len(translations) != 2 || translations[0].Slug != "welcome" {
+		t.Errorf("Expected the greeting translations, default language first, got %+v, %v", translations, err)
+	}
+	if _, err := service.GetTranslations("missing"); !errors.Is(err, ErrPostNotFound) {
+		t.Errorf("Expected ErrPostNotFound, got %v", err)
+	}
+
+	if err := service.SetLanguages([]string{"not a language"}); !errors.Is(err, ErrInvalidLanguage) {
+		t.Errorf("Expected ErrInvalidLanguage, got %v", err)
+	}
+}
+
+func TestLanguageNavigation(t *testing.T) {
+	service := newLanguageTestService(t)
+
+	tests := map[string][2]string{
+		"hello":    {"", "welcome"},
+		"hello.mi": {"", "nau-mai"},
+		"nau-mai":  {"hello.mi", ""},
+	}
+	for slug, want := range tests {
+		post, err := service.GetPostBySlug(slug)
+		if err != nil {
+			t.Fatalf("GetPostBySlug(%s) failed: %v", slug, err)
+		}
+		var previous, next string
+		if post.Previous != nil {
+			previous = post.Previous.Slug
+		}
+		if post.Next != nil {
+			next = post.Next.Slug
+		}
+		if previous != want[0] || next != want[1] {
+			t.Errorf("%s: expected previous %q and next %q in its language, got %q and %q", slug, want[0], want[1], previous, next)
+		}
+	}
+}
+
+func TestLanguageFeeds(t *testing.T) {
+	service := newLanguageTestService(t)
+
+	rss, err := service.GenerateRSSFeed("Blog", "https://blog.example", "", "")
+	if err != nil {
+		t.Fatalf("GenerateRSSFeed failed: %v", err)
+	}
+	if rss.Language != "en-nz" || len(rss.Items) != 5 {
+		t.Errorf("Expected every post in the default language feed, got %q with %d items", rss.Language, len(rss.Items))
+	}
+
+	rss, _ = service.GenerateRSSFeed("Blog", "https://blog.example", "", "MI")
+	if rss.Language != "mi" || len(rss.Items) != 2 || rss.Items[0].Title != "Nau mai" {
+		t.Errorf("Expected only the Māori posts, got %q with %+v", rss.Language, rss.Items)
+	}
+
+	atom, _ := service.GenerateAtomFeed("Blog", "https://blog.example", "", "en")
+	if atom.Lang != "en" || atom.ID != "https://blog.example/?lang=en" || len(atom.Entries) != 3 {
+		t.Errorf("Expected the English posts, got %q %q with %d entries", atom.Lang, atom.ID, len(atom.Entries))
+	}
+}
+
+func TestMatchesLanguage(t *testing.T) {
+	tests := []struct {
+		lang, want string
+		match      bool
+	}{
+		{"en-nz", "en", true},
+		{"en-nz", "en-NZ", true},
+		{"en-nz", "en-us", false},
+		{"en", "en-nz", false},
+		{"mi", "mi", true},
+		{"mis", "mi", false},
+		{"mi", "", false},
+	}
+	for _, tt := range tests {
+		if got := MatchesLanguage(tt.lang, tt.want); got != tt.match {
+			t.Errorf("MatchesLanguage(%q, %q) = %v, want %v", tt.lang, tt.want, got, tt.match)
+		}
+	}
+}
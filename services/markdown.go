@@ -0,0 +1,155 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"blog-api/models"
+	"blog-api/render"
+)
+
+// ReadingConfig sets how reading time is estimated. Code is read more
+// slowly than prose, so it has its own speed.
+type ReadingConfig struct {
+	WordsPerMinute     int
+	CodeWordsPerMinute int
+}
+
+// DefaultReadingConfig is a typical adult reading speed, halved for code
+var DefaultReadingConfig = ReadingConfig{WordsPerMinute: 200, CodeWordsPerMinute: 100}
+
+// DefaultExcerptLength is the length, in characters, of generated excerpts
+const DefaultExcerptLength = 200
+
+// moreMarker ends the excerpt when present in a post
+const moreMarker = "<!--more-->"
+
+// countWords counts the whitespace-separated words containing a letter or
+// digit
+func countWords(text string) int {
+	count := 0
+	for _, word := range strings.Fields(text) {
+		if strings.IndexFunc(word, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// addReadingStats fills in a post's word count and reading time from its
+// markdown content, and its table of contents from its headings
+func addReadingStats(post *models.BlogPost, cfg ReadingConfig) {
+	if cfg.WordsPerMinute <= 0 {
+		cfg.WordsPerMinute = DefaultReadingConfig.WordsPerMinute
+	}
+	if cfg.CodeWordsPerMinute <= 0 {
+		cfg.CodeWordsPerMinute = DefaultReadingConfig.CodeWordsPerMinute
+	}
+
+	prose, code := htmlRenderer.Text(post.Content)
+	proseWords, codeWords := countWords(prose), countWords(code)
+
+	// Headings come from the same parse as the HTML, so the table of
+	// contents links to the IDs the headings are rendered with
+	var headings []models.TOCEntry
+	for _, heading := range htmlRenderer.Headings(post.Content) {
+		headings = append(headings, models.TOCEntry{
+			Level: heading.Level,
+			Text:  heading.Text,
+			ID:    heading.ID,
+		})
+	}
+
+	post.WordCount = proseWords
+	minutes := float64(proseWords)/float64(cfg.WordsPerMinute) + float64(codeWords)/float64(cfg.CodeWordsPerMinute)
+	post.ReadingTime = int(math.Ceil(minutes))
+	if post.ReadingTime == 0 && proseWords+codeWords > 0 {
+		post.ReadingTime = 1
+	}
+	post.TOC = nestHeadings(headings)
+}
+
+// htmlRenderer renders post content, highlighting fenced code
+var htmlRenderer = render.New()
+
+// addHTML fills in a post's rendered HTML from its markdown content,
+// rewriting relative image URLs with resolve
+func addHTML(post *models.BlogPost, resolve render.ImageResolver) {
+	html, err := htmlRenderer.Render(post.Content, resolve)
+	if err != nil {
+		fmt.Printf("Error rendering post %s: %v\n", post.Slug, err)
+		return
+	}
+	post.HTML = html
+}
+
+// nestHeadings arranges a flat list of headings into a tree, placing each
+// heading under the nearest preceding heading of a higher level
+func nestHeadings(headings []models.TOCEntry) []models.TOCEntry {
+	var root []models.TOCEntry
+	var stack []*[]models.TOCEntry
+	var levels []int
+
+	for _, heading := range headings {
+		for len(levels) > 0 && levels[len(levels)-1] >= heading.Level {
+			levels = levels[:len(levels)-1]
+			stack = stack[:len(stack)-1]
+		}
+
+		siblings := &root
+		if len(stack) > 0 {
+			parent := stack[len(stack)-1]
+			siblings = &(*parent)[len(*parent)-1].Children
+		}
+		*siblings = append(*siblings, heading)
+
+		stack = append(stack, siblings)
+		levels = append(levels, heading.Level)
+	}
+
+	return root
+}
+
+// generateExcerpt returns the plain text of the paragraphs at the start of
+// a post, as rendered. Text before a <!--more--> marker is used whole;
+// otherwise the text is cut at the last word boundary within length
+// characters.
+func generateExcerpt(markdown string, length int) string {
+	if length <= 0 {
+		length = DefaultExcerptLength
+	}
+
+	before, _, hasMarker := strings.Cut(markdown, moreMarker)
+	if hasMarker {
+		markdown = before
+	}
+
+	var words []string
+	runes := 0
+	for _, paragraph := range htmlRenderer.Paragraphs(markdown) {
+		for _, word := range strings.Fields(paragraph) {
+			words = append(words, word)
+			runes += len([]rune(word)) + 1
+		}
+		if !hasMarker && runes > length {
+			break
+		}
+	}
+
+	excerpt := strings.Join(words, " ")
+	if hasMarker || len([]rune(excerpt)) <= length {
+		return excerpt
+	}
+
+	cut := []rune(excerpt)[:length]
+	if i := strings.LastIndexFunc(string(cut), unicode.IsSpace); i > 0 {
+		excerpt = string(cut)[:i]
+	} else {
+		excerpt = string(cut)
+	}
+	return strings.TrimRightFunc(excerpt, func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSpace(r)
+	}) + "..."
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"blog-api/models"
+)
+
+func TestAddReadingStats(t *testing.T) {
+	post := models.BlogPost{Content: "# Intro\n\nSome **bold** words and a [link](https://example.com).\n\n" +
+		"## Setup\n\n```go\nfunc main() { fmt.Println(\"hi\") }\n```\n\n" +
+		"### Install `tool`\n\n- one\n- two\n\n## Setup\n\nAgain.\n\n# Wrap-up!"}
+
+	addReadingStats(&post, ReadingConfig{WordsPerMinute: 10, CodeWordsPerMinute: 1})
+
+	// Heading and prose words count; code does not
+	if post.WordCount != 15 {
+		t.Errorf("Expected 15 words, got %d", post.WordCount)
+	}
+	// 15 prose words at 10 wpm plus 3 code words at 1 wpm
+	if post.ReadingTime != 5 {
+		t.Errorf("Expected 5 minutes, got %d", post.ReadingTime)
+	}
+
+	if len(post.TOC) != 2 || post.TOC[0].ID != "intro" || post.TOC[1].ID != "wrap-up" {
+		t.Fatalf("Unexpected top-level TOC %+v", post.TOC)
+	}
+	children := post.TOC[0].Children
+	if len(children) != 2 || children[0].ID != "setup" || children[1].ID != "setup-1" {
+		t.Fatalf("Expected repeated headings to get unique anchors, got %+v", children)
+	}
+	if len(children[0].Children) != 1 || children[0].Children[0].Text != "Install tool" || children[0].Children[0].ID != "install-tool" {
+		t.Errorf("Unexpected nested heading %+v", children[0].Children)
+	}
+}
+
+func TestGenerateExcerpt(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		length   int
+		expected string
+	}{
+		{
+			name:     "markdown stripped",
+			markdown: "# Title\n\n![diagram](d.png)\n\nSee **the** [docs](https://x.y) and `code`.\n\n```\nskipped()\n```\n\n> Quoted _text_",
+			length:   200,
+			expected: "See the docs and code. Quoted text",
+		},
+		{
+			name:     "escapes and entities resolved",
+			markdown: "A \\*literal\\* star &amp; an &lt;tag&gt;.\n\n    indented code\n\n| a | b |\n|---|---|\n| 1 | 2 |\n\n- Tom\n- Jerry",
+			length:   200,
+			expected: "A *literal* star & an <tag>. Tom Jerry",
+		},
+		{
+			name:     "word boundary",
+			markdown: "The quick brown fox jumps over the lazy dog.",
+			length:   17,
+			expected: "The quick brown...",
+		},
+		{
+			name:     "multi-byte runes",
+			markdown: "Café crème brûlée à la façon de grand-mère",
+			length:   12,
+			expected: "Café crème...",
+		},
+		{
+			name:     "more marker",
+			markdown: "First paragraph.\n\nSecond paragraph.\n\n<!--more-->\n\nThe rest.",
+			length:   10,
+			expected: "First paragraph. Second paragraph.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if excerpt := generateExcerpt(tt.markdown, tt.length); excerpt != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, excerpt)
+			}
+		})
+	}
+}
+
+func TestAddHTMLMatchesTOC(t *testing.T) {
+	post := models.BlogPost{Content: "## Using `go test` with [links](https://example.com)\n\n## Why *this*?\n\n## Why *this*?\n\n" +
+		"Setext\n------\n\n> ## Quoted\n\n    ## not a heading\n\n## Use \\*stars\\*\n"}
+
+	addReadingStats(&post, DefaultReadingConfig)
+	addHTML(&post, nil)
+
+	if len(post.TOC) != 6 {
+		t.Fatalf("Expected 6 TOC entries, got %+v", post.TOC)
+	}
+	if text := post.TOC[5].Text; text != "Use *stars*" {
+		t.Errorf("Expected escapes to be resolved in TOC text, got %q", text)
+	}
+	for _, entry := range post.TOC {
+		if !strings.Contains(post.HTML, `id="`+entry.ID+`"`) {
+			t.Errorf("Expected rendered HTML to contain heading ID %q:\n%s", entry.ID, post.HTML)
+		}
+	}
+}
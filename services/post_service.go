@@ -1,52 +1,103 @@
 package services
 
 import (
+	"context"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path/filepath"
-	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"blog-api/models"
+	"blog-api/store"
 )
 
 // PostService handles blog post operations
 type PostService struct {
 	postsDir string
+
+	storeOnce sync.Once
+	postStore store.PostStore
+
+	mu     sync.RWMutex
+	index  map[string]indexedPost
+	loaded bool
+	events []models.PostEvent
+
+	// related is rebuilt from the index whenever it is nil
+	related        map[string][]relatedPost
+	relatedWeights *RelatedWeights
+
+	// aliases maps old slugs to current ones, rebuilt whenever it is nil
+	aliases map[string]string
+
+	reading       ReadingConfig
+	excerptLength int
+	location      *time.Location
+	siteURL       string
+	imageWidths   []int
+	languages     []string
+	authors       map[string]models.Author
+	defaultAuthor string
+
+	subscribersMu sync.RWMutex
+	subscribers   []func(models.PostEvent)
 }
 
-// NewPostService creates a new PostService instance
+// indexedPost is a parsed post held in the in-memory index, along with the
+// store version used to detect changes
+type indexedPost struct {
+	version string
+	post    models.BlogPost
+}
+
+// NewPostService creates a new PostService instance backed by postsDir
 func NewPostService(postsDir string) *PostService {
-	if _, err := os.Stat(postsDir); os.IsNotExist(err) {
-		os.Mkdir(postsDir, 0755)
+	return &PostService{
+		postsDir:  postsDir,
+		postStore: store.NewFileStore(postsDir),
 	}
+}
+
+// NewPostServiceWithStore creates a new PostService instance backed by s
+func NewPostServiceWithStore(s store.PostStore) *PostService {
 	return &PostService{
-		postsDir: postsDir,
+		postStore: s,
 	}
 }
 
-// GetAllPosts loads all blog posts from the posts directory
+// store returns the post store, defaulting to a FileStore over postsDir
+func (ps *PostService) store() store.PostStore {
+	ps.storeOnce.Do(func() {
+		if ps.postStore == nil {
+			ps.postStore = store.NewFileStore(ps.postsDir)
+		}
+	})
+	return ps.postStore
+}
+
+// GetAllPosts returns all blog posts, newest first
 func (ps *PostService) GetAllPosts(includeContent bool) ([]models.BlogPost, error) {
 	var posts []models.BlogPost
 
-	files, err := ioutil.ReadDir(ps.postsDir)
-	if err != nil {
+	if err := ps.refresh(); err != nil {
 		return posts, err
 	}
 
-	for _, file := range files {
-		if filepath.Ext(file.Name()) == ".md" {
-			post, err := ps.loadPostFromFile(filepath.Join(ps.postsDir, file.Name()), includeContent)
-			if err != nil {
-				fmt.Printf("Error loading post %s: %v\n", file.Name(), err)
-				continue
-			}
-			posts = append(posts, post)
+	ps.mu.RLock()
+	for _, entry := range ps.index {
+		post := entry.post
+		if !includeContent {
+			post.Content = ""
+			post.TOC = nil
+			post.HTML = ""
 		}
+		posts = append(posts, post)
 	}
+	ps.mu.RUnlock()
 
 	sort.Slice(posts, func(i, j int) bool {
 		return time.Time(posts[i].Date).After(time.Time(posts[j].Date))
@@ -55,94 +106,375 @@ func (ps *PostService) GetAllPosts(includeContent bool) ([]models.BlogPost, erro
 	return posts, nil
 }
 
-// GetPostBySlug loads a specific post by its slug
+// GetPostBySlug returns a specific post by its slug
 func (ps *PostService) GetPostBySlug(slug string) (models.BlogPost, error) {
-	filename := slug + ".md"
-	filePath := filepath.Join(ps.postsDir, filename)
+	if err := ps.refresh(); err != nil {
+		return models.BlogPost{}, err
+	}
+
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	entry, ok := ps.index[slug]
+	if !ok {
+		return models.BlogPost{}, fmt.Errorf("%w: %s", ErrPostNotFound, slug)
+	}
+
+	post := entry.post
+	ps.lockedAddNavigation(&post)
+	if translations := ps.lockedTranslations(post.TranslationKey); len(translations) > 1 {
+		post.Translations = translations
+	}
+	return post, nil
+}
+
+// Subscribe registers fn to be called after posts are published, updated
+// or deleted, whether through the API or in the underlying store. Changes
+// found while first loading the index are not reported.
+func (ps *PostService) Subscribe(fn func(models.PostEvent)) {
+	ps.subscribersMu.Lock()
+	defer ps.subscribersMu.Unlock()
+	ps.subscribers = append(ps.subscribers, fn)
+}
+
+// unlockAndPublish releases ps.mu and then delivers the events recorded
+// while it was held, so subscribers may safely call back into the service
+func (ps *PostService) unlockAndPublish() {
+	events := ps.events
+	ps.events = nil
+	ps.mu.Unlock()
+
+	if len(events) == 0 {
+		return
+	}
+
+	ps.subscribersMu.RLock()
+	subscribers := ps.subscribers
+	ps.subscribersMu.RUnlock()
+
+	for _, event := range events {
+		for _, fn := range subscribers {
+			fn(event)
+		}
+	}
+}
+
+// lockedRecord queues an event for delivery once ps.mu is released. The
+// caller must hold ps.mu.
+func (ps *PostService) lockedRecord(eventType string, post models.BlogPost) {
+	if !ps.loaded {
+		return
+	}
+	ps.events = append(ps.events, models.PostEvent{Type: eventType, Post: models.NewBlogPostMeta(post)})
+}
+
+// SetReadingConfig changes how reading time is estimated. Posts already
+// indexed are re-analysed on the next refresh.
+func (ps *PostService) SetReadingConfig(cfg ReadingConfig) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	ps.reading = cfg
+	ps.lockedInvalidate()
+}
+
+// SetExcerptLength changes the length, in characters, of generated
+// excerpts. Posts already indexed are re-parsed on the next refresh.
+func (ps *PostService) SetExcerptLength(length int) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	ps.excerptLength = length
+	ps.lockedInvalidate()
+}
+
+// SetLocation sets the site time zone, used for dates given without one
+// and for grouping posts by period. Posts already indexed are re-parsed on
+// the next refresh.
+func (ps *PostService) SetLocation(location *time.Location) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	ps.location = location
+	ps.lockedInvalidate()
+}
+
+// SetSiteURL sets the site's URL, such as https://example.com, used for
+// canonical post URLs and prepended to asset paths in rendered post HTML.
+// Without one they are rooted paths. Posts already indexed are re-parsed on
+// the next refresh.
+func (ps *PostService) SetSiteURL(siteURL string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
 
-	return ps.loadPostFromFile(filePath, true)
+	ps.siteURL = strings.TrimSuffix(siteURL, "/")
+	ps.lockedInvalidate()
+}
+
+// lockedInvalidate forgets the versions of indexed posts so they are
+// re-parsed on the next refresh. The caller must hold ps.mu.
+func (ps *PostService) lockedInvalidate() {
+	for slug, entry := range ps.index {
+		entry.version = ""
+		ps.index[slug] = entry
+	}
+}
+
+// Location returns the site time zone
+func (ps *PostService) Location() *time.Location {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	return ps.lockedLocation()
+}
+
+// lockedLocation returns the site time zone. The caller must hold ps.mu.
+func (ps *PostService) lockedLocation() *time.Location {
+	if ps.location == nil {
+		return time.UTC
+	}
+	return ps.location
+}
+
+// parseOptions returns the options posts are parsed with
+func (ps *PostService) parseOptions(includeContent bool) parseOptions {
+	return parseOptions{
+		includeContent: includeContent,
+		excerptLength:  ps.excerptLength,
+		location:       ps.location,
+	}
+}
+
+// Reload pulls the latest content into the store, for stores that sync
+// from elsewhere, and re-indexes it. It reports whether the content
+// revision changed; stores without revisions always report true.
+func (ps *PostService) Reload() (bool, error) {
+	changed := true
+	if syncer, ok := ps.store().(store.Syncer); ok {
+		var err error
+		if changed, err = syncer.Sync(); err != nil {
+			return false, err
+		}
+	}
+
+	if err := ps.refresh(); err != nil {
+		return false, err
+	}
+
+	return changed, nil
+}
+
+// Watch keeps the index up to date with changes reported by the store until
+// ctx is cancelled
+func (ps *PostService) Watch(ctx context.Context) error {
+	events, err := ps.store().Watch(ctx)
+	if err != nil {
+		return err
+	}
+
+	for range events {
+		if err := ps.refresh(); err != nil {
+			fmt.Printf("Error refreshing posts: %v\n", err)
+		}
+	}
+
+	return nil
+}
+
+// refresh brings the in-memory index in line with the store, re-parsing
+// only the documents whose version has changed
+func (ps *PostService) refresh() error {
+	entries, err := ps.store().List()
+	if err != nil {
+		return err
+	}
+
+	ps.mu.Lock()
+	defer ps.unlockAndPublish()
+
+	if ps.index == nil {
+		ps.index = make(map[string]indexedPost)
+	}
+
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		seen[entry.Slug] = true
+
+		if indexed, ok := ps.index[entry.Slug]; ok && indexed.version == entry.Version {
+			continue
+		}
+
+		if err := ps.lockedLoad(entry.Slug); err != nil {
+			fmt.Printf("Error loading post %s: %v\n", entry.Slug, err)
+		}
+	}
+
+	for slug, indexed := range ps.index {
+		if !seen[slug] {
+			delete(ps.index, slug)
+			ps.related = nil
+			ps.aliases = nil
+			ps.lockedRecord(models.PostDeleted, indexed.post)
+		}
+	}
+
+	ps.loaded = true
+	if ps.related == nil {
+		ps.lockedRebuildRelated()
+	}
+	if ps.aliases == nil {
+		ps.lockedRebuildAliases()
+	}
+
+	return nil
+}
+
+// lockedLoad reads and parses a single post into the index. The caller must
+// hold ps.mu.
+func (ps *PostService) lockedLoad(slug string) error {
+	doc, err := ps.store().Get(slug)
+	if err != nil {
+		return err
+	}
+
+	if ps.index == nil {
+		ps.index = make(map[string]indexedPost)
+	}
+
+	post := parsePost(doc, ps.parseOptions(true))
+	addReadingStats(&post, ps.reading)
+	addHTML(&post, ps.lockedAssetResolver(slug))
+	post.CanonicalURL = ps.siteURL + "/posts/" + slug
+	ps.lockedAddLanguage(&post)
+	ps.lockedAddAuthors(&post)
+	previous, existed := ps.index[slug]
+	ps.index[slug] = indexedPost{
+		version: doc.Version,
+		post:    post,
+	}
+	ps.related = nil
+	ps.aliases = nil
+
+	if !existed {
+		ps.lockedRecord(models.PostPublished, post)
+	} else if previous.post.ETag != post.ETag {
+		ps.lockedRecord(models.PostUpdated, post)
+	}
+
+	return nil
 }
 
 // loadPostFromFile loads a blog post from a markdown file
 func (ps *PostService) loadPostFromFile(filePath string, includeContent bool) (models.BlogPost, error) {
-	var post models.BlogPost
-
-	content, err := ioutil.ReadFile(filePath)
+	content, err := os.ReadFile(filePath)
 	if err != nil {
-		return post, err
+		return models.BlogPost{}, err
 	}
 
-	contentStr := string(content)
+	var modTime time.Time
+	if info, err := os.Stat(filePath); err == nil {
+		modTime = info.ModTime()
+	}
 
-	frontmatterRegex := regexp.MustCompile(`(?s)^---\s*\n(.*?)\n---\s*\n(.*)`)
-	matches := frontmatterRegex.FindStringSubmatch(contentStr)
+	doc := store.Document{
+		Entry: store.Entry{
+			Slug:    strings.TrimSuffix(filepath.Base(filePath), ".md"),
+			ModTime: modTime,
+		},
+		Content: content,
+	}
+	post := parsePost(doc, ps.parseOptions(includeContent))
+	if includeContent {
+		addReadingStats(&post, ps.reading)
+		addHTML(&post, nil)
+	}
+	return post, nil
+}
 
-	var frontmatter string
-	var markdown string
+// dateLayouts are the accepted formats for frontmatter dates. Those without
+// an offset are read in the site time zone.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
 
-	if len(matches) == 3 {
-		frontmatter = matches[1]
-		markdown = matches[2]
-	} else {
-		markdown = contentStr
+// parseDate parses a frontmatter date in any of dateLayouts
+func parseDate(value string, location *time.Location) (time.Time, bool) {
+	for _, layout := range dateLayouts {
+		if date, err := time.ParseInLocation(layout, value, location); err == nil {
+			return date, true
+		}
 	}
+	return time.Time{}, false
+}
 
-	post.Slug = strings.TrimSuffix(filepath.Base(filePath), ".md")
+// parseOptions controls how a post document is parsed
+type parseOptions struct {
+	includeContent bool
+	// excerptLength is the length of generated excerpts
+	excerptLength int
+	// location is the time zone of dates given without one; nil means UTC
+	location *time.Location
+}
 
-	if frontmatter != "" {
-		lines := strings.Split(frontmatter, "\n")
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if line == "" {
-				continue
-			}
+// parsePost parses a post document. When the frontmatter has no date, the
+// document's creation time from the store's history is used, falling back to
+// its modification time. Without an excerpt in the frontmatter one is
+// generated.
+func parsePost(doc store.Document, opts parseOptions) models.BlogPost {
+	var post models.BlogPost
 
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) != 2 {
-				continue
-			}
+	location := opts.location
+	if location == nil {
+		location = time.UTC
+	}
 
-			key := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
+	frontmatter, markdown := splitFrontmatter(string(doc.Content))
 
-			if (strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"")) ||
-				(strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'")) {
-				value = value[1 : len(value)-1]
-			}
+	post.Slug = doc.Slug
+	post.Section = doc.Section
+	post.ETag = computeETag(doc.Content)
+	post.Contributors = doc.Authors
+	if !doc.Updated.IsZero() {
+		post.Updated = doc.Updated.UTC().Format(time.RFC3339)
+	}
 
-			switch key {
-			case "title":
-				post.Title = value
-			case "date":
-				if date, err := time.Parse("2006-01-02", value); err == nil {
-					post.Date = models.DateOnly(date)
-					post.PublishDate = value
-				} else if date, err := time.Parse(time.RFC3339, value); err == nil {
-					post.Date = models.DateOnly(date)
-					post.PublishDate = date.Format("2006-01-02")
-				}
-			case "tags":
-				if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
-					value = strings.Trim(value, "[]")
-					tags := strings.Split(value, ",")
-					for _, tag := range tags {
-						tag = strings.TrimSpace(tag)
-						tag = strings.Trim(tag, "\"'")
-						if tag != "" {
-							post.Tags = append(post.Tags, tag)
-						}
-					}
-				} else {
-					tags := strings.Split(value, ",")
-					for _, tag := range tags {
-						tag = strings.TrimSpace(tag)
-						if tag != "" {
-							post.Tags = append(post.Tags, tag)
-						}
-					}
-				}
-			case "excerpt":
-				post.Excerpt = value
+	for _, field := range parseFrontmatter(frontmatter) {
+		switch field.Key {
+		case "title":
+			post.Title = field.Value
+		case "date":
+			if date, ok := parseDate(field.Value, location); ok {
+				post.Date = models.DateOnly(date)
+			}
+		case "updated":
+			if updated, ok := parseDate(field.Value, location); ok {
+				post.Updated = updated.Format(time.RFC3339)
+			}
+		case "tags":
+			post.Tags = append(post.Tags, parseTags(field.Value)...)
+		case "excerpt":
+			post.Excerpt = field.Value
+		case "author", "authors":
+			for _, name := range parseTags(field.Value) {
+				post.Authors = append(post.Authors, models.Author{ID: name, Name: name})
+			}
+		case "aliases":
+			post.Aliases = parseAliases(field.Value)
+		case "lang":
+			if lang, err := NormalizeLanguage(field.Value); err == nil {
+				post.Lang = lang
+			}
+		case "translation_key":
+			post.TranslationKey = field.Value
+		case "series":
+			post.Series = field.Value
+		case "series_order":
+			if order, err := strconv.Atoi(field.Value); err == nil {
+				post.SeriesOrder = order
 			}
 		}
 	}
@@ -151,41 +483,34 @@ func (ps *PostService) loadPostFromFile(filePath string, includeContent bool) (m
 		post.Title = strings.Title(strings.ReplaceAll(post.Slug, "-", " "))
 	}
 
-	if post.Date.IsZero() {
-		if info, err := os.Stat(filePath); err == nil {
-			post.Date = models.DateOnly(info.ModTime())
-			post.PublishDate = info.ModTime().Format("2006-01-02")
-		}
+	fallback := doc.Created
+	if fallback.IsZero() {
+		fallback = doc.ModTime
+	}
+	if post.Date.IsZero() && !fallback.IsZero() {
+		fallback = fallback.In(location)
+		post.Date = models.DateOnly(fallback)
+	}
+	if !post.Date.IsZero() {
+		post.PublishDate = time.Time(post.Date).Format("2006-01-02")
 	}
 
-	if includeContent {
+	if opts.includeContent {
 		post.Content = strings.TrimSpace(markdown)
 	}
 
-	if post.Excerpt == "" && includeContent {
-		lines := strings.Split(markdown, "\n")
-		var excerptLines []string
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if line != "" && !strings.HasPrefix(line, "#") {
-				excerptLines = append(excerptLines, line)
-				if len(excerptLines) >= 2 {
-					break
-				}
-			}
-		}
-		post.Excerpt = strings.Join(excerptLines, " ")
-		if len(post.Excerpt) > 200 {
-			post.Excerpt = post.Excerpt[:200] + "..."
-		}
+	if post.Excerpt == "" {
+		post.Excerpt = generateExcerpt(markdown, opts.excerptLength)
 	}
 
-	return post, nil
+	return post
 }
 
-// GenerateRSSFeed creates an RSS feed from blog posts
-func (ps *PostService) GenerateRSSFeed(title, baseURL, description string) (models.RSSFeed, error) {
-	posts, err := ps.GetAllPosts(true)
+// GenerateRSSFeed creates an RSS feed from blog posts. With a language
+// only the posts in that language are included; otherwise every post is,
+// and the feed is in the site's default language.
+func (ps *PostService) GenerateRSSFeed(title, baseURL, description, lang string) (models.RSSFeed, error) {
+	posts, lang, err := ps.feedPosts(lang)
 	if err != nil {
 		return models.RSSFeed{}, err
 	}
@@ -194,7 +519,7 @@ func (ps *PostService) GenerateRSSFeed(title, baseURL, description string) (mode
 		Title:       title,
 		Link:        baseURL,
 		Description: description,
-		Language:    "en-us",
+		Language:    lang,
 		Items:       make([]models.RSSItem, 0, len(posts)),
 	}
 
@@ -207,6 +532,117 @@ func (ps *PostService) GenerateRSSFeed(title, baseURL, description string) (mode
 		item := models.BlogPostToRSSItem(posts[i], baseURL)
 		feed.Items = append(feed.Items, item)
 	}
+	feed.LastBuildDate = lastModified(posts)
 
 	return feed, nil
 }
+
+// GenerateAtomFeed creates an Atom feed from blog posts, of every post or
+// only those in lang. Entries in another language than the feed say so.
+func (ps *PostService) GenerateAtomFeed(title, baseURL, subtitle, lang string) (models.AtomFeed, error) {
+	filter := lang
+	posts, lang, err := ps.feedPosts(lang)
+	if err != nil {
+		return models.AtomFeed{}, err
+	}
+
+	id := strings.TrimRight(baseURL, "/") + "/"
+	if filter != "" {
+		id += "?lang=" + lang
+	}
+
+	feed := models.AtomFeed{
+		Title:    title,
+		Subtitle: subtitle,
+		Link:     baseURL,
+		ID:       id,
+		Lang:     lang,
+		Updated:  lastModified(posts),
+		Entries:  make([]models.AtomEntry, 0, len(posts)),
+	}
+
+	limit := len(posts)
+	if limit > 20 {
+		limit = 20
+	}
+
+	for i := 0; i < limit; i++ {
+		feed.Entries = append(feed.Entries, models.BlogPostToAtomEntry(posts[i], baseURL))
+	}
+
+	return feed, nil
+}
+
+// GenerateJSONFeed creates a JSON Feed from blog posts, of every post or
+// only those in lang. Items give their language only when it differs from
+// the feed's.
+func (ps *PostService) GenerateJSONFeed(title, baseURL, description, lang string) (models.JSONFeed, error) {
+	posts, lang, err := ps.feedPosts(lang)
+	if err != nil {
+		return models.JSONFeed{}, err
+	}
+
+	feed := models.JSONFeed{
+		Version:     models.JSONFeedVersion,
+		Title:       title,
+		HomePageURL: baseURL,
+		Description: description,
+		Language:    lang,
+		Items:       make([]models.JSONFeedItem, 0, len(posts)),
+	}
+
+	limit := len(posts)
+	if limit > 20 {
+		limit = 20
+	}
+
+	for i := 0; i < limit; i++ {
+		item := models.BlogPostToJSONFeedItem(posts[i], baseURL)
+		if item.Language == lang {
+			item.Language = ""
+		}
+		feed.Items = append(feed.Items, item)
+	}
+
+	return feed, nil
+}
+
+// feedPosts returns the posts for a feed in lang, or every post for a feed
+// in the default language when lang is empty, along with the feed's
+// language
+func (ps *PostService) feedPosts(lang string) ([]models.BlogPost, string, error) {
+	posts, err := ps.GetAllPosts(true)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if lang == "" {
+		return posts, ps.Languages()[0], nil
+	}
+
+	lang, err = NormalizeLanguage(lang)
+	if err != nil {
+		return nil, "", err
+	}
+	var matching []models.BlogPost
+	for _, post := range posts {
+		if MatchesLanguage(post.Lang, lang) {
+			matching = append(matching, post)
+		}
+	}
+	return matching, lang, nil
+}
+
+// lastModified returns the latest date or updated time of posts
+func lastModified(posts []models.BlogPost) time.Time {
+	var latest time.Time
+	for _, post := range posts {
+		if date := time.Time(post.Date); date.After(latest) {
+			latest = date
+		}
+		if updated, err := time.Parse(time.RFC3339, post.Updated); err == nil && updated.After(latest) {
+			latest = updated
+		}
+	}
+	return latest
+}
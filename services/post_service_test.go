@@ -372,7 +372,7 @@ func TestGenerateRSSFeed(t *testing.T) {
 	baseURL := "https://example.com"
 	description := "A test blog"
 
-	feed, err := service.GenerateRSSFeed(title, baseURL, description)
+	feed, err := service.GenerateRSSFeed(title, baseURL, description, "")
 	if err != nil {
 		t.Fatalf("GenerateRSSFeed failed: %v", err)
 	}
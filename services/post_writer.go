@@ -0,0 +1,487 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"blog-api/models"
+	"blog-api/store"
+)
+
+var (
+	// slugRegex allows a language suffix, so translations can be written
+	// as <slug>.<lang>
+	slugRegex        = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*(?:\.[a-z]{2,3}(?:-[a-z0-9]+)*)?$`)
+	slugInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// CreatePost writes a new post. If no slug is given one is derived from the
+// title, with a numeric suffix added when the derived slug is already taken.
+func (ps *PostService) CreatePost(input models.PostInput) (models.BlogPost, error) {
+	if err := ps.refresh(); err != nil {
+		return models.BlogPost{}, err
+	}
+
+	ps.mu.Lock()
+	defer ps.unlockAndPublish()
+
+	if input.Slug == "" {
+		input.Slug = ps.uniqueSlug(slugify(input.Title))
+	} else if _, exists := ps.index[input.Slug]; exists {
+		return models.BlogPost{}, fmt.Errorf("%w: %s", ErrPostExists, input.Slug)
+	}
+
+	if input.Date == "" {
+		input.Date = time.Now().In(ps.lockedLocation()).Format("2006-01-02")
+	}
+
+	if err := validatePostInput(input); err != nil {
+		return models.BlogPost{}, err
+	}
+
+	return ps.writePost(input.Slug, inputFields(nil, input), input.Content)
+}
+
+// UpdatePost replaces an existing post. Any field the input leaves out or
+// empty keeps its current value; an empty list, as opposed to a missing
+// one, clears tags, authors or aliases. If the input carries a different
+// slug the post is renamed and the old slug kept as an alias, so links to
+// it still work. Frontmatter keys the input does not cover are preserved.
+// An empty ifMatch skips the concurrency check.
+func (ps *PostService) UpdatePost(slug string, input models.PostInput, ifMatch string) (models.BlogPost, error) {
+	if err := ps.refresh(); err != nil {
+		return models.BlogPost{}, err
+	}
+
+	ps.mu.Lock()
+	defer ps.unlockAndPublish()
+
+	if _, err := ps.lockedCheckPrecondition(slug, ifMatch); err != nil {
+		return models.BlogPost{}, err
+	}
+
+	doc, err := ps.store().Get(slug)
+	if err != nil {
+		return models.BlogPost{}, err
+	}
+	frontmatter, markdown := splitFrontmatter(string(doc.Content))
+	fields := parseFrontmatter(frontmatter)
+
+	input = keepOmitted(input, storedInput(slug, fields, markdown))
+	ps.lockedFillDefaults(slug, &input)
+	if input.Slug != slug {
+		input.Aliases = renamedAliases(input.Aliases, slug, input.Slug)
+	}
+
+	if err := validatePostInput(input); err != nil {
+		return models.BlogPost{}, err
+	}
+
+	if input.Slug != slug {
+		if _, exists := ps.index[input.Slug]; exists {
+			return models.BlogPost{}, fmt.Errorf("%w: %s", ErrPostExists, input.Slug)
+		}
+	}
+
+	// A slug set in frontmatter is written back, following any rename, so
+	// the post keeps answering to the slug it was given
+	if getField(fields, "slug") != "" {
+		fields = setField(fields, "slug", input.Slug)
+	}
+
+	if input.Slug != slug {
+		return ps.renamePost(slug, input.Slug, inputFields(fields, input), input.Content)
+	}
+	return ps.writePost(slug, inputFields(fields, input), input.Content)
+}
+
+// PatchPost applies a partial update to an existing post. Frontmatter keys
+// not covered by the patch are preserved.
+func (ps *PostService) PatchPost(slug string, patch models.PostPatch, ifMatch string) (models.BlogPost, error) {
+	if err := ps.refresh(); err != nil {
+		return models.BlogPost{}, err
+	}
+
+	ps.mu.Lock()
+	defer ps.unlockAndPublish()
+
+	if _, err := ps.lockedCheckPrecondition(slug, ifMatch); err != nil {
+		return models.BlogPost{}, err
+	}
+
+	doc, err := ps.store().Get(slug)
+	if err != nil {
+		return models.BlogPost{}, err
+	}
+
+	frontmatter, markdown := splitFrontmatter(string(doc.Content))
+	fields := parseFrontmatter(frontmatter)
+
+	input := storedInput(slug, fields, markdown)
+	if patch.Title != nil {
+		input.Title = *patch.Title
+	}
+	if patch.Date != nil {
+		input.Date = *patch.Date
+	}
+	if patch.Updated != nil {
+		input.Updated = *patch.Updated
+	}
+	if patch.Tags != nil {
+		input.Tags = *patch.Tags
+	}
+	if patch.Excerpt != nil {
+		input.Excerpt = *patch.Excerpt
+	}
+	if patch.Authors != nil {
+		input.Authors = *patch.Authors
+	}
+	if patch.Aliases != nil {
+		input.Aliases = *patch.Aliases
+	}
+	if patch.Lang != nil {
+		input.Lang = *patch.Lang
+	}
+	if patch.TranslationKey != nil {
+		input.TranslationKey = *patch.TranslationKey
+	}
+	if patch.Series != nil {
+		input.Series = *patch.Series
+	}
+	if patch.SeriesOrder != nil {
+		input.SeriesOrder = *patch.SeriesOrder
+	}
+	if patch.Content != nil {
+		input.Content = *patch.Content
+	}
+
+	ps.lockedFillDefaults(slug, &input)
+
+	if err := validatePostInput(input); err != nil {
+		return models.BlogPost{}, err
+	}
+
+	return ps.writePost(slug, inputFields(fields, input), input.Content)
+}
+
+// storedInput returns the input that writes a stored post back as it is,
+// from its frontmatter fields and markdown
+func storedInput(slug string, fields []frontmatterField, markdown string) models.PostInput {
+	input := models.PostInput{
+		Slug:           slug,
+		Title:          getField(fields, "title"),
+		Date:           getField(fields, "date"),
+		Updated:        getField(fields, "updated"),
+		Tags:           parseTags(getField(fields, "tags")),
+		Excerpt:        getField(fields, "excerpt"),
+		Authors:        append(parseTags(getField(fields, "author")), parseTags(getField(fields, "authors"))...),
+		Aliases:        parseAliases(getField(fields, "aliases")),
+		Lang:           getField(fields, "lang"),
+		TranslationKey: getField(fields, "translation_key"),
+		Series:         getField(fields, "series"),
+		Content:        markdown,
+	}
+	if order, err := strconv.Atoi(getField(fields, "series_order")); err == nil {
+		input.SeriesOrder = order
+	}
+	return input
+}
+
+// keepOmitted fills each field input leaves out or empty from current. A
+// list given empty rather than left out stays empty.
+func keepOmitted(input, current models.PostInput) models.PostInput {
+	keep := func(value *string, currentValue string) {
+		if *value == "" {
+			*value = currentValue
+		}
+	}
+	keep(&input.Slug, current.Slug)
+	keep(&input.Title, current.Title)
+	keep(&input.Date, current.Date)
+	keep(&input.Updated, current.Updated)
+	keep(&input.Excerpt, current.Excerpt)
+	keep(&input.Lang, current.Lang)
+	keep(&input.TranslationKey, current.TranslationKey)
+	keep(&input.Series, current.Series)
+	keep(&input.Content, current.Content)
+	if input.Tags == nil {
+		input.Tags = current.Tags
+	}
+	if input.Authors == nil {
+		input.Authors = current.Authors
+	}
+	if input.Aliases == nil {
+		input.Aliases = current.Aliases
+	}
+	if input.SeriesOrder == 0 {
+		input.SeriesOrder = current.SeriesOrder
+	}
+	return input
+}
+
+// lockedFillDefaults gives input the title and date a post shows when its
+// frontmatter has none, since posts written by hand may rely on them. The
+// date is kept as written where there is one, so dates stay in the site
+// time zone. The caller must hold ps.mu.
+func (ps *PostService) lockedFillDefaults(slug string, input *models.PostInput) {
+	post := ps.index[slug].post
+	if input.Title == "" {
+		input.Title = post.Title
+	}
+	if input.Date == "" && !post.Date.IsZero() {
+		input.Date = post.Date.String()
+	}
+}
+
+// DeletePost removes a post. An empty ifMatch skips the concurrency check.
+func (ps *PostService) DeletePost(slug, ifMatch string) error {
+	if err := ps.refresh(); err != nil {
+		return err
+	}
+
+	ps.mu.Lock()
+	defer ps.unlockAndPublish()
+
+	if _, err := ps.lockedCheckPrecondition(slug, ifMatch); err != nil {
+		return err
+	}
+
+	return ps.removePost(slug)
+}
+
+// lockedCheckPrecondition looks up slug and compares its ETag against
+// ifMatch. The caller must hold ps.mu.
+func (ps *PostService) lockedCheckPrecondition(slug, ifMatch string) (models.BlogPost, error) {
+	entry, ok := ps.index[slug]
+	if !ok {
+		return models.BlogPost{}, fmt.Errorf("%w: %s", ErrPostNotFound, slug)
+	}
+
+	if ifMatch != "" && ifMatch != "*" && !etagMatches(ifMatch, entry.post.ETag) {
+		return models.BlogPost{}, fmt.Errorf("%w: %s", ErrPreconditionFailed, slug)
+	}
+
+	return entry.post, nil
+}
+
+// writePost writes a post to the store and updates the index. The caller
+// must hold ps.mu.
+func (ps *PostService) writePost(slug string, fields []frontmatterField, markdown string) (models.BlogPost, error) {
+	if err := ps.store().Put(slug, renderDocument(fields, markdown)); err != nil {
+		return models.BlogPost{}, err
+	}
+
+	if err := ps.lockedLoad(slug); err != nil {
+		return models.BlogPost{}, err
+	}
+
+	return ps.index[slug].post, nil
+}
+
+// renamePost moves a post to newSlug with the given content and updates
+// the index. Stores that keep files beside a post move them along with it.
+// Otherwise the post is written at newSlug and deleted at oldSlug; when the
+// old document cannot be deleted, such as a read-only one under an overlay,
+// the new document is removed again so the post stays at oldSlug only. The
+// caller must hold ps.mu.
+func (ps *PostService) renamePost(oldSlug, newSlug string, fields []frontmatterField, markdown string) (models.BlogPost, error) {
+	content := renderDocument(fields, markdown)
+	if renamer, ok := ps.store().(store.Renamer); ok {
+		if err := renamer.Rename(oldSlug, newSlug, content); errors.Is(err, fs.ErrExist) {
+			return models.BlogPost{}, fmt.Errorf("%w: %s", ErrPostExists, newSlug)
+		} else if err != nil {
+			return models.BlogPost{}, err
+		}
+	} else {
+		if err := ps.store().Put(newSlug, content); err != nil {
+			return models.BlogPost{}, err
+		}
+		if err := ps.store().Delete(oldSlug); err != nil && !errors.Is(err, store.ErrNotFound) {
+			if undoErr := ps.store().Delete(newSlug); undoErr != nil {
+				return models.BlogPost{}, fmt.Errorf("%w (removing %s again: %v)", err, newSlug, undoErr)
+			}
+			return models.BlogPost{}, err
+		}
+	}
+
+	if err := ps.lockedLoad(newSlug); err != nil {
+		return models.BlogPost{}, err
+	}
+	if err := ps.removePost(oldSlug); err != nil {
+		return models.BlogPost{}, err
+	}
+	return ps.index[newSlug].post, nil
+}
+
+// removePost deletes a post from the store and drops it from the index. The
+// caller must hold ps.mu.
+func (ps *PostService) removePost(slug string) error {
+	if err := ps.store().Delete(slug); err != nil && !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+	if indexed, ok := ps.index[slug]; ok {
+		delete(ps.index, slug)
+		ps.related = nil
+		ps.aliases = nil
+		ps.lockedRecord(models.PostDeleted, indexed.post)
+	}
+	return nil
+}
+
+// uniqueSlug returns base, or base with the lowest free numeric suffix. The
+// caller must hold ps.mu.
+func (ps *PostService) uniqueSlug(base string) string {
+	slug := base
+	for i := 2; ; i++ {
+		if _, exists := ps.index[slug]; !exists {
+			return slug
+		}
+		slug = base + "-" + strconv.Itoa(i)
+	}
+}
+
+// inputFields merges the known post fields from input into existing
+// frontmatter, keeping any other keys in their original order
+func inputFields(fields []frontmatterField, input models.PostInput) []frontmatterField {
+	fields = setField(fields, "title", input.Title)
+	fields = setField(fields, "date", input.Date)
+	fields = setField(fields, "updated", input.Updated)
+	if len(input.Tags) > 0 {
+		fields = setField(fields, "tags", formatTags(input.Tags))
+	} else {
+		fields = setField(fields, "tags", "")
+	}
+	fields = setField(fields, "excerpt", input.Excerpt)
+	// A single author stays in the author key of a post that uses it
+	if len(input.Authors) == 1 && getField(fields, "author") != "" {
+		fields = setField(fields, "author", input.Authors[0])
+		fields = setField(fields, "authors", "")
+	} else if len(input.Authors) > 0 {
+		fields = setField(fields, "author", "")
+		fields = setField(fields, "authors", formatTags(input.Authors))
+	} else {
+		fields = setField(fields, "author", "")
+		fields = setField(fields, "authors", "")
+	}
+	if len(input.Aliases) > 0 {
+		fields = setField(fields, "aliases", formatTags(input.Aliases))
+	} else {
+		fields = setField(fields, "aliases", "")
+	}
+	fields = setField(fields, "lang", input.Lang)
+	fields = setField(fields, "translation_key", input.TranslationKey)
+	fields = setField(fields, "series", input.Series)
+	if input.SeriesOrder > 0 {
+		fields = setField(fields, "series_order", strconv.Itoa(input.SeriesOrder))
+	} else {
+		fields = setField(fields, "series_order", "")
+	}
+	return fields
+}
+
+// renamedAliases returns the aliases of a post renamed from oldSlug to
+// newSlug: the old slug is added and the new one, if it was an alias,
+// dropped
+func renamedAliases(aliases []string, oldSlug, newSlug string) []string {
+	var renamed []string
+	for _, alias := range aliases {
+		if alias != oldSlug && alias != newSlug {
+			renamed = append(renamed, alias)
+		}
+	}
+	return append(renamed, oldSlug)
+}
+
+// validatePostInput checks that input can be written as frontmatter and read
+// back unchanged
+func validatePostInput(input models.PostInput) error {
+	if !slugRegex.MatchString(input.Slug) {
+		return fmt.Errorf("%w: slug must be lowercase letters, digits and single hyphens, optionally followed by .<lang>", ErrInvalidPost)
+	}
+
+	if strings.TrimSpace(input.Title) == "" {
+		return fmt.Errorf("%w: title is required", ErrInvalidPost)
+	}
+	if strings.ContainsAny(input.Title, "\r\n") {
+		return fmt.Errorf("%w: title must be a single line", ErrInvalidPost)
+	}
+	if strings.ContainsAny(input.Excerpt, "\r\n") {
+		return fmt.Errorf("%w: excerpt must be a single line", ErrInvalidPost)
+	}
+
+	if _, ok := parseDate(input.Date, time.UTC); !ok {
+		return fmt.Errorf("%w: date must be YYYY-MM-DD, YYYY-MM-DD HH:MM or RFC3339", ErrInvalidPost)
+	}
+	if _, ok := parseDate(input.Updated, time.UTC); input.Updated != "" && !ok {
+		return fmt.Errorf("%w: updated must be YYYY-MM-DD, YYYY-MM-DD HH:MM or RFC3339", ErrInvalidPost)
+	}
+
+	for _, tag := range input.Tags {
+		if strings.TrimSpace(tag) == "" || strings.ContainsAny(tag, ",\"'[]\r\n") {
+			return fmt.Errorf("%w: tag %q contains invalid characters", ErrInvalidPost, tag)
+		}
+	}
+
+	for _, author := range input.Authors {
+		if strings.TrimSpace(author) == "" || strings.ContainsAny(author, ",\"'[]\r\n") {
+			return fmt.Errorf("%w: author %q contains invalid characters", ErrInvalidPost, author)
+		}
+	}
+
+	if input.Lang != "" {
+		if _, err := NormalizeLanguage(input.Lang); err != nil {
+			return fmt.Errorf("%w: lang must be a language tag such as mi or en-NZ", ErrInvalidPost)
+		}
+	}
+	if strings.ContainsAny(input.TranslationKey, "\r\n") {
+		return fmt.Errorf("%w: translation_key must be a single line", ErrInvalidPost)
+	}
+
+	if strings.ContainsAny(input.Series, "\r\n") {
+		return fmt.Errorf("%w: series must be a single line", ErrInvalidPost)
+	}
+	if input.SeriesOrder < 0 {
+		return fmt.Errorf("%w: series_order must not be negative", ErrInvalidPost)
+	}
+
+	for _, alias := range input.Aliases {
+		if !slugRegex.MatchString(alias) {
+			return fmt.Errorf("%w: alias %q must be lowercase letters, digits and single hyphens", ErrInvalidPost, alias)
+		}
+	}
+
+	return nil
+}
+
+// slugify derives a slug from a post title
+func slugify(title string) string {
+	slug := slugInvalidChars.ReplaceAllString(strings.ToLower(title), "-")
+	slug = strings.Trim(slug, "-")
+	if slug == "" {
+		slug = "post"
+	}
+	return slug
+}
+
+// computeETag returns a strong ETag for a post file's contents
+func computeETag(content []byte) string {
+	sum := sha256.Sum256(content)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// etagMatches reports whether an If-Match header value lists etag
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimSpace(candidate) == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,371 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"blog-api/models"
+	"blog-api/store"
+)
+
+func TestCreatePost(t *testing.T) {
+	dir := t.TempDir()
+	service := NewPostService(dir)
+
+	post, err := service.CreatePost(models.PostInput{
+		Title:   "Hello: Again",
+		Date:    "2025-06-05",
+		Tags:    []string{"go", "writing"},
+		Content: "# Hello\n\nBody text.",
+	})
+	if err != nil {
+		t.Fatalf("CreatePost failed: %v", err)
+	}
+
+	if post.Slug != "hello-again" {
+		t.Errorf("Expected derived slug 'hello-again', got '%s'", post.Slug)
+	}
+	if post.ETag == "" {
+		t.Error("Created post should have an ETag")
+	}
+
+	// The written file should parse back to the same post
+	loaded, err := service.loadPostFromFile(filepath.Join(dir, "hello-again.md"), true)
+	if err != nil {
+		t.Fatalf("Failed to load written post: %v", err)
+	}
+	if loaded.Title != "Hello: Again" {
+		t.Errorf("Expected title 'Hello: Again', got '%s'", loaded.Title)
+	}
+	if strings.Join(loaded.Tags, ",") != "go,writing" {
+		t.Errorf("Expected tags [go writing], got %v", loaded.Tags)
+	}
+	if loaded.Content != "# Hello\n\nBody text." {
+		t.Errorf("Unexpected content %q", loaded.Content)
+	}
+
+	// No temporary files should be left behind
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("Expected 1 file in posts dir, got %d", len(files))
+	}
+}
+
+func TestCreatePost_SlugConflicts(t *testing.T) {
+	service := NewPostService(t.TempDir())
+
+	input := models.PostInput{Title: "Same Title", Date: "2025-06-05"}
+	if _, err := service.CreatePost(input); err != nil {
+		t.Fatalf("CreatePost failed: %v", err)
+	}
+
+	// Derived slugs get a suffix rather than conflicting
+	second, err := service.CreatePost(input)
+	if err != nil {
+		t.Fatalf("CreatePost failed: %v", err)
+	}
+	if second.Slug != "same-title-2" {
+		t.Errorf("Expected slug 'same-title-2', got '%s'", second.Slug)
+	}
+
+	// Explicit slugs conflict
+	input.Slug = "same-title"
+	if _, err := service.CreatePost(input); !errors.Is(err, ErrPostExists) {
+		t.Errorf("Expected ErrPostExists, got %v", err)
+	}
+}
+
+func TestCreatePost_Validation(t *testing.T) {
+	service := NewPostService(t.TempDir())
+
+	invalid := []models.PostInput{
+		{Title: ""},
+		{Title: "Two\nLines"},
+		{Title: "Bad Slug", Slug: "Bad Slug"},
+		{Title: "Bad Date", Date: "yesterday"},
+		{Title: "Bad Tag", Tags: []string{"a,b"}},
+	}
+
+	for _, input := range invalid {
+		if _, err := service.CreatePost(input); !errors.Is(err, ErrInvalidPost) {
+			t.Errorf("Expected ErrInvalidPost for %+v, got %v", input, err)
+		}
+	}
+}
+
+func TestUpdatePost_IfMatch(t *testing.T) {
+	service := NewPostService(t.TempDir())
+
+	post, err := service.CreatePost(models.PostInput{Title: "Original", Date: "2025-06-05"})
+	if err != nil {
+		t.Fatalf("CreatePost failed: %v", err)
+	}
+
+	updated, err := service.UpdatePost(post.Slug, models.PostInput{Title: "Updated"}, post.ETag)
+	if err != nil {
+		t.Fatalf("UpdatePost failed: %v", err)
+	}
+	if updated.Title != "Updated" {
+		t.Errorf("Expected title 'Updated', got '%s'", updated.Title)
+	}
+	if updated.PublishDate != "2025-06-05" {
+		t.Errorf("Expected date to be kept, got '%s'", updated.PublishDate)
+	}
+
+	// The old ETag is now stale
+	_, err = service.UpdatePost(post.Slug, models.PostInput{Title: "Stale"}, post.ETag)
+	if !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("Expected ErrPreconditionFailed, got %v", err)
+	}
+}
+
+func TestUpdatePost_KeepsOmittedFields(t *testing.T) {
+	service := NewPostService(t.TempDir())
+
+	post, err := service.CreatePost(models.PostInput{
+		Title:          "Original",
+		Date:           "2025-06-05",
+		Updated:        "2025-06-07",
+		Tags:           []string{"go"},
+		Excerpt:        "Short",
+		Authors:        []string{"jane"},
+		Aliases:        []string{"first"},
+		Lang:           "mi",
+		TranslationKey: "original",
+		Series:         "Basics",
+		SeriesOrder:    2,
+		Content:        "Body",
+	})
+	if err != nil {
+		t.Fatalf("CreatePost failed: %v", err)
+	}
+
+	updated, err := service.UpdatePost(post.Slug, models.PostInput{Title: "Renamed title"}, "")
+	if err != nil {
+		t.Fatalf("UpdatePost failed: %v", err)
+	}
+	if updated.Title != "Renamed title" {
+		t.Errorf("Expected the new title, got %q", updated.Title)
+	}
+	if updated.Date.String() != "2025-06-05" || updated.Updated == "" || updated.Updated != post.Updated {
+		t.Errorf("Expected the dates to be kept, got %q and %q", updated.Date, updated.Updated)
+	}
+	if len(updated.Tags) != 1 || updated.Excerpt != "Short" || len(updated.Authors) != 1 || len(updated.Aliases) != 1 {
+		t.Errorf("Expected tags, excerpt, authors and aliases to be kept, got %+v", updated)
+	}
+	if updated.Lang != "mi" || updated.TranslationKey != "original" {
+		t.Errorf("Expected the language to be kept, got %q and %q", updated.Lang, updated.TranslationKey)
+	}
+	if updated.Series != "Basics" || updated.SeriesOrder != 2 || updated.Content != "Body" {
+		t.Errorf("Expected the series and content to be kept, got %+v", updated)
+	}
+
+	// An empty list clears, where a missing one keeps
+	cleared, err := service.UpdatePost(post.Slug, models.PostInput{Tags: []string{}, Aliases: []string{}}, "")
+	if err != nil {
+		t.Fatalf("UpdatePost failed: %v", err)
+	}
+	if len(cleared.Tags) != 0 || len(cleared.Aliases) != 0 || len(cleared.Authors) != 1 {
+		t.Errorf("Expected only tags and aliases to be cleared, got %+v", cleared)
+	}
+}
+
+func TestUpdatePost_Rename(t *testing.T) {
+	dir := t.TempDir()
+	service := NewPostService(dir)
+
+	post, _ := service.CreatePost(models.PostInput{Title: "Old Name", Date: "2025-06-05"})
+	service.CreatePost(models.PostInput{Title: "Taken", Date: "2025-06-05"})
+
+	_, err := service.UpdatePost(post.Slug, models.PostInput{Slug: "taken", Title: "Old Name"}, "")
+	if !errors.Is(err, ErrPostExists) {
+		t.Errorf("Expected ErrPostExists, got %v", err)
+	}
+
+	if _, err := service.UpdatePost(post.Slug, models.PostInput{Slug: "new-name", Title: "New Name"}, ""); err != nil {
+		t.Fatalf("UpdatePost failed: %v", err)
+	}
+
+	if _, err := service.GetPostBySlug("old-name"); !errors.Is(err, ErrPostNotFound) {
+		t.Errorf("Old slug should be gone, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new-name.md")); err != nil {
+		t.Errorf("Renamed post file should exist: %v", err)
+	}
+}
+
+func TestUpdatePost_RenameReadOnly(t *testing.T) {
+	base := store.NewMemoryStore()
+	base.Put("fixed", []byte("---\ntitle: \"Fixed\"\ndate: \"2025-06-05\"\n---\n\nBody"))
+	service := NewPostServiceWithStore(store.NewOverlayStore(store.NewMemoryStore(), base))
+
+	_, err := service.UpdatePost("fixed", models.PostInput{Slug: "moved", Title: "Moved"}, "")
+	if !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("Expected ErrReadOnly, got %v", err)
+	}
+
+	if _, err := service.GetPostBySlug("moved"); !errors.Is(err, ErrPostNotFound) {
+		t.Errorf("A failed rename should not leave the new slug, got %v", err)
+	}
+	if post, err := service.GetPostBySlug("fixed"); err != nil || post.Title != "Fixed" {
+		t.Errorf("Expected the post unchanged at its old slug, got %+v, %v", post, err)
+	}
+}
+
+func TestUpdatePost_FrontmatterSlug(t *testing.T) {
+	dir := t.TempDir()
+	content := "---\ntitle: \"Foo Bar\"\ndate: \"2025-06-05\"\nslug: \"nice\"\ncustom: \"keep me\"\n---\n\nBody"
+	if err := os.WriteFile(filepath.Join(dir, "foo-bar.md"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create post: %v", err)
+	}
+
+	service := NewPostService(dir)
+
+	post, err := service.UpdatePost("nice", models.PostInput{Title: "Nice", Content: "New body"}, "")
+	if err != nil {
+		t.Fatalf("UpdatePost failed: %v", err)
+	}
+	if post.Slug != "nice" || post.Title != "Nice" {
+		t.Errorf("Expected the post to keep its frontmatter slug, got %+v", post)
+	}
+
+	written, _ := os.ReadFile(filepath.Join(dir, "foo-bar.md"))
+	for _, want := range []string{`slug: "nice"`, `custom: "keep me"`, "New body"} {
+		if !strings.Contains(string(written), want) {
+			t.Errorf("Expected %s in the replaced post, got:\n%s", want, written)
+		}
+	}
+	if _, err := service.GetPostBySlug("nice"); err != nil {
+		t.Errorf("Expected the post at its frontmatter slug, got %v", err)
+	}
+
+	// Renaming rewrites the frontmatter slug too
+	if _, err := service.UpdatePost("nice", models.PostInput{Slug: "nicer", Title: "Nicer"}, ""); err != nil {
+		t.Fatalf("UpdatePost failed: %v", err)
+	}
+	if _, err := service.GetPostBySlug("nicer"); err != nil {
+		t.Errorf("Expected the renamed post, got %v", err)
+	}
+	if canonical, err := service.ResolveAlias("nice"); err != nil || canonical != "nicer" {
+		t.Errorf("Expected the old slug to redirect to the new one, got %q, %v", canonical, err)
+	}
+}
+
+func TestWritePost_Series(t *testing.T) {
+	service := NewPostService(t.TempDir())
+
+	post, err := service.CreatePost(models.PostInput{Title: "Part 1", Date: "2025-06-05", Series: "Go Basics", SeriesOrder: 1})
+	if err != nil {
+		t.Fatalf("CreatePost failed: %v", err)
+	}
+	if post, _ := service.GetPostBySlug(post.Slug); post.SeriesContext == nil || post.SeriesContext.Part != 1 {
+		t.Errorf("Expected the post in its series, got %+v", post.SeriesContext)
+	}
+
+	order := 2
+	post, err = service.PatchPost(post.Slug, models.PostPatch{SeriesOrder: &order}, "")
+	if err != nil {
+		t.Fatalf("PatchPost failed: %v", err)
+	}
+	if post.Series != "Go Basics" || post.SeriesOrder != 2 {
+		t.Errorf("Expected the series order to change, got %q %d", post.Series, post.SeriesOrder)
+	}
+
+	post, err = service.UpdatePost(post.Slug, models.PostInput{Title: "Part 1", Series: "Go Basics", SeriesOrder: 3}, "")
+	if err != nil {
+		t.Fatalf("UpdatePost failed: %v", err)
+	}
+	if post.Series != "Go Basics" || post.SeriesOrder != 3 {
+		t.Errorf("Expected PUT to set the series, got %q %d", post.Series, post.SeriesOrder)
+	}
+
+	if _, err := service.UpdatePost(post.Slug, models.PostInput{Title: "Part 1", SeriesOrder: -1}, ""); !errors.Is(err, ErrInvalidPost) {
+		t.Errorf("Expected a negative series order to be refused, got %v", err)
+	}
+}
+
+func TestPatchPost_PreservesUnknownFields(t *testing.T) {
+	dir := t.TempDir()
+	content := "---\ntitle: \"Patch Me\"\ndate: \"2025-06-05\"\ncustom: \"keep me\"\n---\n\nOriginal body"
+	if err := os.WriteFile(filepath.Join(dir, "patch-me.md"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create post: %v", err)
+	}
+
+	service := NewPostService(dir)
+
+	excerpt := "New excerpt"
+	post, err := service.PatchPost("patch-me", models.PostPatch{Excerpt: &excerpt}, "")
+	if err != nil {
+		t.Fatalf("PatchPost failed: %v", err)
+	}
+
+	if post.Excerpt != excerpt || post.Title != "Patch Me" || post.Content != "Original body" {
+		t.Errorf("Unexpected patched post %+v", post)
+	}
+
+	written, _ := os.ReadFile(filepath.Join(dir, "patch-me.md"))
+	if !strings.Contains(string(written), `custom: "keep me"`) {
+		t.Errorf("Unknown frontmatter keys should be preserved, got:\n%s", written)
+	}
+}
+
+func TestDeletePost(t *testing.T) {
+	service := NewPostService(t.TempDir())
+
+	post, _ := service.CreatePost(models.PostInput{Title: "Short Lived", Date: "2025-06-05"})
+
+	if err := service.DeletePost(post.Slug, `"stale"`); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("Expected ErrPreconditionFailed, got %v", err)
+	}
+	if err := service.DeletePost(post.Slug, post.ETag); err != nil {
+		t.Fatalf("DeletePost failed: %v", err)
+	}
+
+	posts, _ := service.GetAllPosts(false)
+	if len(posts) != 0 {
+		t.Errorf("Expected no posts after delete, got %d", len(posts))
+	}
+
+	if err := service.DeletePost(post.Slug, ""); !errors.Is(err, ErrPostNotFound) {
+		t.Errorf("Expected ErrPostNotFound, got %v", err)
+	}
+}
+
+func TestPostEvents(t *testing.T) {
+	memory := store.NewMemoryStore()
+	memory.Put("existing", []byte("---\ntitle: \"Existing\"\n---\n\nBody"))
+
+	service := NewPostServiceWithStore(memory)
+
+	var events []string
+	service.Subscribe(func(event models.PostEvent) {
+		events = append(events, event.Type+" "+event.Post.Slug)
+	})
+
+	// Posts present at first load are not reported
+	service.GetAllPosts(false)
+
+	post, _ := service.CreatePost(models.PostInput{Title: "New", Date: "2025-06-05"})
+	service.UpdatePost(post.Slug, models.PostInput{Title: "New"}, "")
+	service.UpdatePost(post.Slug, models.PostInput{Title: "Changed"}, "")
+	service.DeletePost(post.Slug, "")
+
+	// Changes made directly in the store are reported too
+	memory.Put("external", []byte("External"))
+	memory.Delete("existing")
+	service.GetAllPosts(false)
+
+	expected := []string{
+		"post.published new",
+		"post.updated new",
+		"post.deleted new",
+		"post.published external",
+		"post.deleted existing",
+	}
+	if strings.Join(events, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected events %v, got %v", expected, events)
+	}
+}
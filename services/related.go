@@ -0,0 +1,224 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"blog-api/models"
+)
+
+// maxRelated is how many related posts are kept for each post
+const maxRelated = 20
+
+// RelatedWeights sets how much each signal contributes to a related post's
+// score. Each signal is between 0 and 1.
+type RelatedWeights struct {
+	// Tags weights the Jaccard overlap of the two posts' tags
+	Tags float64
+	// Content weights the TF-IDF cosine similarity of their content
+	Content float64
+	// Recency weights how recent the candidate is, relative to the newest
+	// post, with a half-life of a year
+	Recency float64
+}
+
+// DefaultRelatedWeights favours shared tags, then similar content
+var DefaultRelatedWeights = RelatedWeights{Tags: 0.5, Content: 0.4, Recency: 0.1}
+
+// relatedPost is a precomputed related post and its score
+type relatedPost struct {
+	slug  string
+	score float64
+}
+
+// SetRelatedWeights changes the weights used to rank related posts
+func (ps *PostService) SetRelatedWeights(weights RelatedWeights) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	ps.relatedWeights = &weights
+	ps.related = nil
+}
+
+// GetRelatedPosts returns up to limit posts related to slug, best first
+func (ps *PostService) GetRelatedPosts(slug string, limit int) ([]models.RelatedPost, error) {
+	if err := ps.refresh(); err != nil {
+		return nil, err
+	}
+
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if _, ok := ps.index[slug]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPostNotFound, slug)
+	}
+
+	related := ps.related[slug]
+	if limit > 0 && len(related) > limit {
+		related = related[:limit]
+	}
+
+	posts := make([]models.RelatedPost, 0, len(related))
+	for _, r := range related {
+		posts = append(posts, models.RelatedPost{
+			BlogPostMeta: models.NewBlogPostMeta(ps.index[r.slug].post),
+			Score:        math.Round(r.score*1000) / 1000,
+		})
+	}
+	return posts, nil
+}
+
+// lockedRebuildRelated recomputes the related posts for every post in the
+// index. The caller must hold ps.mu.
+func (ps *PostService) lockedRebuildRelated() {
+	weights := DefaultRelatedWeights
+	if ps.relatedWeights != nil {
+		weights = *ps.relatedWeights
+	}
+
+	slugs := make([]string, 0, len(ps.index))
+	for slug := range ps.index {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+
+	tags := make([]map[string]bool, len(slugs))
+	docs := make([][]string, len(slugs))
+	var newest time.Time
+	for i, slug := range slugs {
+		post := ps.index[slug].post
+		tags[i] = make(map[string]bool, len(post.Tags))
+		for _, tag := range post.Tags {
+			tags[i][strings.ToLower(tag)] = true
+		}
+		docs[i] = tokenize(post.Title + " " + post.Content)
+		if date := time.Time(post.Date); date.After(newest) {
+			newest = date
+		}
+	}
+	vectors := tfidf(docs)
+
+	related := make(map[string][]relatedPost, len(slugs))
+	for i, slug := range slugs {
+		var candidates []relatedPost
+		for j, other := range slugs {
+			if i == j {
+				continue
+			}
+
+			tagScore := jaccard(tags[i], tags[j])
+			contentScore := cosine(vectors[i], vectors[j])
+			if tagScore == 0 && contentScore == 0 {
+				continue
+			}
+
+			age := newest.Sub(time.Time(ps.index[other].post.Date)).Hours() / 24
+			recency := math.Pow(0.5, math.Max(age, 0)/365)
+
+			candidates = append(candidates, relatedPost{
+				slug:  other,
+				score: weights.Tags*tagScore + weights.Content*contentScore + weights.Recency*recency,
+			})
+		}
+
+		sort.SliceStable(candidates, func(a, b int) bool {
+			return candidates[a].score > candidates[b].score
+		})
+		if len(candidates) > maxRelated {
+			candidates = candidates[:maxRelated]
+		}
+		related[slug] = candidates
+	}
+
+	ps.related = related
+}
+
+// stopWords are common English words left out of content similarity
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "that": true, "this": true, "with": true,
+	"you": true, "are": true, "was": true, "but": true, "not": true, "have": true,
+	"from": true, "can": true, "all": true, "your": true, "our": true, "its": true,
+	"will": true, "they": true, "their": true, "there": true, "which": true, "when": true,
+	"what": true, "into": true, "out": true, "more": true, "some": true, "has": true,
+}
+
+// tokenize splits text into lower-case words of three or more characters,
+// skipping stop words
+func tokenize(text string) []string {
+	var tokens []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(word)) >= 3 && !stopWords[word] {
+			tokens = append(tokens, word)
+		}
+	}
+	return tokens
+}
+
+// tfidf returns a normalised TF-IDF vector for each document
+func tfidf(docs [][]string) []map[string]float64 {
+	df := make(map[string]int)
+	for _, doc := range docs {
+		seen := make(map[string]bool)
+		for _, term := range doc {
+			if !seen[term] {
+				seen[term] = true
+				df[term]++
+			}
+		}
+	}
+
+	vectors := make([]map[string]float64, len(docs))
+	for i, doc := range docs {
+		counts := make(map[string]int)
+		for _, term := range doc {
+			counts[term]++
+		}
+
+		vector := make(map[string]float64, len(counts))
+		var norm float64
+		for term, count := range counts {
+			weight := float64(count) / float64(len(doc)) * math.Log(1+float64(len(docs))/float64(df[term]))
+			vector[term] = weight
+			norm += weight * weight
+		}
+		norm = math.Sqrt(norm)
+		for term := range vector {
+			vector[term] /= norm
+		}
+		vectors[i] = vector
+	}
+	return vectors
+}
+
+// cosine returns the cosine similarity of two normalised vectors
+func cosine(a, b map[string]float64) float64 {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	var dot float64
+	for term, weight := range a {
+		dot += weight * b[term]
+	}
+	return dot
+}
+
+// jaccard returns the size of the intersection of two sets over the size of
+// their union
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for tag := range a {
+		if b[tag] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"blog-api/store"
+)
+
+func TestGetRelatedPosts(t *testing.T) {
+	memory := store.NewMemoryStore()
+	memory.Put("k8s-operators", []byte("---\ntitle: \"Writing Kubernetes operators\"\ndate: \"2025-01-01\"\ntags: [kubernetes, go]\n---\n\nControllers reconcile cluster state with custom resources."))
+	memory.Put("k8s-networking", []byte("---\ntitle: \"Kubernetes networking\"\ndate: \"2025-02-01\"\ntags: [kubernetes]\n---\n\nServices and ingress route cluster traffic between pods."))
+	memory.Put("go-generics", []byte("---\ntitle: \"Go generics\"\ndate: \"2025-03-01\"\ntags: [go]\n---\n\nType parameters make reusable containers possible."))
+	memory.Put("sourdough", []byte("---\ntitle: \"Sourdough\"\ndate: \"2025-04-01\"\ntags: [baking]\n---\n\nFlour, water and patience."))
+
+	service := NewPostServiceWithStore(memory)
+
+	related, err := service.GetRelatedPosts("k8s-operators", 5)
+	if err != nil {
+		t.Fatalf("GetRelatedPosts failed: %v", err)
+	}
+	if len(related) != 2 || related[0].Slug != "k8s-networking" || related[1].Slug != "go-generics" {
+		t.Errorf("Unexpected related posts %+v", related)
+	}
+	if related[0].Score <= related[1].Score {
+		t.Errorf("Expected scores in descending order, got %v then %v", related[0].Score, related[1].Score)
+	}
+
+	if related, _ := service.GetRelatedPosts("k8s-operators", 1); len(related) != 1 {
+		t.Errorf("Expected the limit to apply, got %d posts", len(related))
+	}
+
+	// Changes to the index are picked up
+	memory.Put("sourdough", []byte("---\ntitle: \"Sourdough\"\ndate: \"2025-04-01\"\ntags: [kubernetes, go]\n---\n\nFlour, water and patience."))
+	related, _ = service.GetRelatedPosts("k8s-operators", 5)
+	if len(related) != 3 || related[0].Slug != "sourdough" {
+		t.Errorf("Expected the retagged post to rank first, got %+v", related)
+	}
+
+	// Weights change the ranking: with only content counting, the post
+	// sharing words with it ranks first
+	service.SetRelatedWeights(RelatedWeights{Content: 1})
+	related, _ = service.GetRelatedPosts("k8s-operators", 5)
+	if len(related) == 0 || related[0].Slug != "k8s-networking" {
+		t.Errorf("Expected content similarity to decide, got %+v", related)
+	}
+
+	if _, err := service.GetRelatedPosts("missing", 5); !errors.Is(err, ErrPostNotFound) {
+		t.Errorf("Expected ErrPostNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"blog-api/models"
+	"blog-api/persist"
+)
+
+// Reload job statuses
+const (
+	ReloadQueued    = "queued"
+	ReloadRunning   = "running"
+	ReloadSucceeded = "succeeded"
+	ReloadFailed    = "failed"
+)
+
+// maxReloadJobs bounds how many finished jobs are kept for status lookups
+const maxReloadJobs = 100
+
+// Reloader re-indexes a content source
+type Reloader interface {
+	Reload() (bool, error)
+}
+
+// ReloadService runs content reloads in the background. Requests that
+// arrive while a reload is queued join that reload instead of starting
+// another, so a burst of pushes costs a single sync.
+type ReloadService struct {
+	reloader Reloader
+	debounce time.Duration
+
+	mu      sync.Mutex
+	jobs    map[string]*models.ReloadJob
+	order   []string
+	pending *models.ReloadJob
+	running bool
+}
+
+// NewReloadService creates a ReloadService. Each reload waits debounce
+// before starting to collect further requests.
+func NewReloadService(reloader Reloader, debounce time.Duration) *ReloadService {
+	return &ReloadService{
+		reloader: reloader,
+		debounce: debounce,
+		jobs:     make(map[string]*models.ReloadJob),
+	}
+}
+
+// Trigger queues a reload, or joins the one already queued, and returns it
+func (rs *ReloadService) Trigger() models.ReloadJob {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.pending != nil {
+		rs.pending.Requests++
+		return *rs.pending
+	}
+
+	job := &models.ReloadJob{
+		ID:        persist.NewID(),
+		Status:    ReloadQueued,
+		Requests:  1,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	rs.pending = job
+	rs.jobs[job.ID] = job
+	rs.order = append(rs.order, job.ID)
+	rs.prune()
+
+	if !rs.running {
+		rs.running = true
+		go rs.run()
+	}
+
+	return *job
+}
+
+// GetJob returns a job by ID
+func (rs *ReloadService) GetJob(id string) (models.ReloadJob, bool) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	job, ok := rs.jobs[id]
+	if !ok {
+		return models.ReloadJob{}, false
+	}
+	return *job, true
+}
+
+// run processes queued jobs one at a time until none are left
+func (rs *ReloadService) run() {
+	for {
+		time.Sleep(rs.debounce)
+
+		rs.mu.Lock()
+		job := rs.pending
+		if job == nil {
+			rs.running = false
+			rs.mu.Unlock()
+			return
+		}
+		rs.pending = nil
+		job.Status = ReloadRunning
+		job.StartedAt = time.Now().UTC().Format(time.RFC3339)
+		rs.mu.Unlock()
+
+		changed, err := rs.reloader.Reload()
+
+		rs.mu.Lock()
+		job.FinishedAt = time.Now().UTC().Format(time.RFC3339)
+		job.Changed = changed
+		if err != nil {
+			job.Status = ReloadFailed
+			job.Error = err.Error()
+			fmt.Printf("Reload %s failed: %v\n", job.ID, err)
+		} else {
+			job.Status = ReloadSucceeded
+		}
+		rs.mu.Unlock()
+	}
+}
+
+// prune drops the oldest finished jobs beyond maxReloadJobs. The caller
+// must hold rs.mu.
+func (rs *ReloadService) prune() {
+	for len(rs.order) > maxReloadJobs {
+		oldest := rs.jobs[rs.order[0]]
+		if oldest.Status == ReloadQueued || oldest.Status == ReloadRunning {
+			return
+		}
+		delete(rs.jobs, oldest.ID)
+		rs.order = rs.order[1:]
+	}
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingReloader counts reloads and can be made to fail
+type countingReloader struct {
+	mu    sync.Mutex
+	calls int
+	err   error
+}
+
+func (cr *countingReloader) Reload() (bool, error) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	cr.calls++
+	return cr.err == nil, cr.err
+}
+
+// waitForJob polls until a job leaves the queued and running states
+func waitForJob(t *testing.T, rs *ReloadService, id string) string {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		job, _ := rs.GetJob(id)
+		if job.Status == ReloadSucceeded || job.Status == ReloadFailed {
+			return job.Status
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for job %s", id)
+	return ""
+}
+
+func TestReloadService_CoalescesBursts(t *testing.T) {
+	reloader := &countingReloader{}
+	rs := NewReloadService(reloader, 50*time.Millisecond)
+
+	first := rs.Trigger()
+	second := rs.Trigger()
+	third := rs.Trigger()
+
+	if first.ID != second.ID || second.ID != third.ID {
+		t.Errorf("Expected a burst to share one job, got %s, %s, %s", first.ID, second.ID, third.ID)
+	}
+	if third.Requests != 3 {
+		t.Errorf("Expected 3 coalesced requests, got %d", third.Requests)
+	}
+
+	if status := waitForJob(t, rs, first.ID); status != ReloadSucceeded {
+		t.Errorf("Expected job to succeed, got %s", status)
+	}
+	if reloader.calls != 1 {
+		t.Errorf("Expected a single reload, got %d", reloader.calls)
+	}
+
+	// A later request starts a new job
+	next := rs.Trigger()
+	if next.ID == first.ID {
+		t.Error("Expected a new job after the previous one finished")
+	}
+	waitForJob(t, rs, next.ID)
+}
+
+func TestReloadService_ReportsFailures(t *testing.T) {
+	rs := NewReloadService(&countingReloader{err: errors.New("fetch failed")}, 0)
+
+	job := rs.Trigger()
+	if status := waitForJob(t, rs, job.ID); status != ReloadFailed {
+		t.Errorf("Expected job to fail, got %s", status)
+	}
+
+	job, _ = rs.GetJob(job.ID)
+	if job.Error != "fetch failed" {
+		t.Errorf("Expected the error to be recorded, got %q", job.Error)
+	}
+
+	if _, ok := rs.GetJob("unknown"); ok {
+		t.Error("Expected unknown job IDs to be reported missing")
+	}
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"blog-api/models"
+)
+
+// GetAllSeries returns every series, by name
+func (ps *PostService) GetAllSeries() ([]models.SeriesSummary, error) {
+	if err := ps.refresh(); err != nil {
+		return nil, err
+	}
+
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	var summaries []models.SeriesSummary
+	for _, series := range ps.lockedSeries() {
+		summaries = append(summaries, series.SeriesSummary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return strings.ToLower(summaries[i].Name) < strings.ToLower(summaries[j].Name)
+	})
+	return summaries, nil
+}
+
+// GetSeries returns a series and its parts in reading order. name may be
+// the series name or its slug.
+func (ps *PostService) GetSeries(name string) (models.Series, error) {
+	if err := ps.refresh(); err != nil {
+		return models.Series{}, err
+	}
+
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	series, ok := ps.lockedSeries()[slugify(name)]
+	if !ok {
+		return models.Series{}, fmt.Errorf("%w: %s", ErrSeriesNotFound, name)
+	}
+	return series, nil
+}
+
+// lockedAddNavigation fills in the chronological neighbours of post among
+// posts in its language, and its place in its series. The caller must hold
+// ps.mu.
+func (ps *PostService) lockedAddNavigation(post *models.BlogPost) {
+	posts := make([]models.BlogPost, 0, len(ps.index))
+	for _, entry := range ps.index {
+		if entry.post.Lang == post.Lang {
+			posts = append(posts, entry.post)
+		}
+	}
+	sortChronologically(posts)
+
+	for i, p := range posts {
+		if p.Slug != post.Slug {
+			continue
+		}
+		if i > 0 {
+			post.Previous = models.NewPostSummary(posts[i-1])
+		}
+		if i < len(posts)-1 {
+			post.Next = models.NewPostSummary(posts[i+1])
+		}
+		break
+	}
+
+	if post.Series == "" {
+		return
+	}
+
+	series, ok := ps.lockedSeries()[slugify(post.Series)]
+	if !ok {
+		return
+	}
+
+	seriesContext := &models.SeriesContext{Series: series}
+	for i, part := range series.Parts {
+		if part.Slug != post.Slug {
+			continue
+		}
+		seriesContext.Part = part.Part
+		if i > 0 {
+			seriesContext.Previous = &series.Parts[i-1].PostSummary
+		}
+		if i < len(series.Parts)-1 {
+			seriesContext.Next = &series.Parts[i+1].PostSummary
+		}
+	}
+	post.SeriesContext = seriesContext
+}
+
+// lockedSeries groups the indexed posts into series keyed by slug. Parts are
+// ordered by series_order, with unnumbered parts after numbered ones, then
+// by date. The caller must hold ps.mu.
+func (ps *PostService) lockedSeries() map[string]models.Series {
+	members := make(map[string][]models.BlogPost)
+	names := make(map[string]string)
+	for _, entry := range ps.index {
+		if entry.post.Series == "" {
+			continue
+		}
+		slug := slugify(entry.post.Series)
+		members[slug] = append(members[slug], entry.post)
+		if names[slug] == "" || entry.post.Series < names[slug] {
+			names[slug] = entry.post.Series
+		}
+	}
+
+	series := make(map[string]models.Series, len(members))
+	for slug, posts := range members {
+		sort.Slice(posts, func(i, j int) bool {
+			a, b := posts[i].SeriesOrder, posts[j].SeriesOrder
+			if a != b && (a == 0 || b == 0) {
+				return b == 0
+			}
+			if a != b {
+				return a < b
+			}
+			return chronologicallyBefore(posts[i], posts[j])
+		})
+
+		parts := make([]models.SeriesPart, len(posts))
+		for i, post := range posts {
+			parts[i] = models.SeriesPart{PostSummary: *models.NewPostSummary(post), Part: i + 1}
+		}
+
+		series[slug] = models.Series{
+			SeriesSummary: models.SeriesSummary{Slug: slug, Name: names[slug], Count: len(parts)},
+			Parts:         parts,
+		}
+	}
+	return series
+}
+
+// sortChronologically sorts posts oldest first
+func sortChronologically(posts []models.BlogPost) {
+	sort.Slice(posts, func(i, j int) bool {
+		return chronologicallyBefore(posts[i], posts[j])
+	})
+}
+
+// chronologicallyBefore orders posts by date, breaking ties by slug so the
+// order is stable
+func chronologicallyBefore(a, b models.BlogPost) bool {
+	if !time.Time(a.Date).Equal(time.Time(b.Date)) {
+		return time.Time(a.Date).Before(time.Time(b.Date))
+	}
+	return a.Slug < b.Slug
+}
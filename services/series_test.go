@@ -0,0 +1,64 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"blog-api/store"
+)
+
+func TestSeriesAndNavigation(t *testing.T) {
+	memory := store.NewMemoryStore()
+	memory.Put("k8s-part-2", []byte("---\ntitle: \"Part 2\"\ndate: \"2025-01-01\"\nseries: \"Kubernetes from Scratch\"\nseries_order: 2\n---\n\nTwo"))
+	memory.Put("k8s-part-1", []byte("---\ntitle: \"Part 1\"\ndate: \"2025-01-02\"\nseries: \"Kubernetes from Scratch\"\nseries_order: 1\n---\n\nOne"))
+	memory.Put("k8s-extra", []byte("---\ntitle: \"Extra\"\ndate: \"2024-12-01\"\nseries: \"Kubernetes from Scratch\"\n---\n\nBonus"))
+	memory.Put("unrelated", []byte("---\ntitle: \"Unrelated\"\ndate: \"2025-02-01\"\n---\n\nOther"))
+
+	service := NewPostServiceWithStore(memory)
+
+	post, err := service.GetPostBySlug("k8s-part-1")
+	if err != nil {
+		t.Fatalf("GetPostBySlug failed: %v", err)
+	}
+
+	// Previous and next follow dates, regardless of series
+	if post.Previous == nil || post.Previous.Slug != "k8s-part-2" || post.Next == nil || post.Next.Slug != "unrelated" {
+		t.Errorf("Unexpected chronological navigation %+v / %+v", post.Previous, post.Next)
+	}
+
+	ctx := post.SeriesContext
+	if ctx == nil {
+		t.Fatal("Expected series context")
+	}
+	if ctx.Slug != "kubernetes-from-scratch" || ctx.Part != 1 || ctx.Count != 3 || ctx.Previous != nil || ctx.Next == nil || ctx.Next.Slug != "k8s-part-2" {
+		t.Errorf("Unexpected series context %+v", ctx)
+	}
+
+	series, err := service.GetSeries("kubernetes-from-scratch")
+	if err != nil {
+		t.Fatalf("GetSeries failed: %v", err)
+	}
+	var order []string
+	for _, part := range series.Parts {
+		order = append(order, part.Slug)
+	}
+	if len(order) != 3 || order[0] != "k8s-part-1" || order[1] != "k8s-part-2" || order[2] != "k8s-extra" {
+		t.Errorf("Expected numbered parts first, got %v", order)
+	}
+
+	if _, err := service.GetSeries("Kubernetes from Scratch"); err != nil {
+		t.Errorf("Expected lookup by name to work, got %v", err)
+	}
+	if _, err := service.GetSeries("missing"); !errors.Is(err, ErrSeriesNotFound) {
+		t.Errorf("Expected ErrSeriesNotFound, got %v", err)
+	}
+
+	all, _ := service.GetAllSeries()
+	if len(all) != 1 || all[0].Name != "Kubernetes from Scratch" {
+		t.Errorf("Unexpected series list %+v", all)
+	}
+
+	if post, _ := service.GetPostBySlug("unrelated"); post.SeriesContext != nil || post.Next != nil {
+		t.Errorf("Expected no series or next post, got %+v", post)
+	}
+}
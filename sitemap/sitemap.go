@@ -0,0 +1,89 @@
+// Package sitemap renders sitemaps in the sitemaps.org XML format
+package sitemap
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+)
+
+// Limits set by the sitemaps.org protocol for a single sitemap file
+const (
+	MaxURLs  = 50000
+	MaxBytes = 50 << 20
+)
+
+const (
+	urlsetHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+`
+	urlsetFooter = "</urlset>\n"
+	indexHeader  = `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+`
+	indexFooter = "</sitemapindex>\n"
+)
+
+// URL is a page listed in a sitemap
+type URL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+// Split renders urls as one or more sitemap files, starting a new file
+// whenever the next URL would take the current one past maxURLs entries or
+// maxBytes. Zero limits fall back to the protocol maximums.
+func Split(urls []URL, maxURLs, maxBytes int) [][]byte {
+	if maxURLs <= 0 {
+		maxURLs = MaxURLs
+	}
+	if maxBytes <= 0 {
+		maxBytes = MaxBytes
+	}
+
+	var files [][]byte
+	var current strings.Builder
+	count := 0
+
+	flush := func() {
+		current.WriteString(urlsetFooter)
+		files = append(files, []byte(current.String()))
+		current.Reset()
+		count = 0
+	}
+
+	current.WriteString(urlsetHeader)
+	for _, u := range urls {
+		entry := renderEntry("url", u.Loc, u.LastMod)
+		if count > 0 && (count >= maxURLs || current.Len()+len(entry)+len(urlsetFooter) > maxBytes) {
+			flush()
+			current.WriteString(urlsetHeader)
+		}
+		current.WriteString(entry)
+		count++
+	}
+	flush()
+
+	return files
+}
+
+// Index renders a sitemap index listing the sitemap files at locs
+func Index(locs []string, lastMod time.Time) []byte {
+	var b strings.Builder
+	b.WriteString(indexHeader)
+	for _, loc := range locs {
+		b.WriteString(renderEntry("sitemap", loc, lastMod))
+	}
+	b.WriteString(indexFooter)
+	return []byte(b.String())
+}
+
+// renderEntry renders a <url> or <sitemap> element
+func renderEntry(element, loc string, lastMod time.Time) string {
+	if lastMod.IsZero() {
+		return fmt.Sprintf("\t<%s>\n\t\t<loc>%s</loc>\n\t</%s>\n", element, html.EscapeString(loc), element)
+	}
+	return fmt.Sprintf("\t<%s>\n\t\t<loc>%s</loc>\n\t\t<lastmod>%s</lastmod>\n\t</%s>\n",
+		element, html.EscapeString(loc), lastMod.UTC().Format(time.RFC3339), element)
+}
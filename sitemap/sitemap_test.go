@@ -0,0 +1,72 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func urls(n int) []URL {
+	var result []URL
+	for i := 0; i < n; i++ {
+		result = append(result, URL{Loc: fmt.Sprintf("https://blog.example/posts/%d?a=1&b=2", i), LastMod: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)})
+	}
+	return result
+}
+
+func TestSplit(t *testing.T) {
+	files := Split(urls(5), 0, 0)
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 file, got %d", len(files))
+	}
+
+	var urlset struct {
+		URLs []struct {
+			Loc     string `xml:"loc"`
+			LastMod string `xml:"lastmod"`
+		} `xml:"url"`
+	}
+	if err := xml.Unmarshal(files[0], &urlset); err != nil {
+		t.Fatalf("Sitemap is not valid XML: %v", err)
+	}
+	if len(urlset.URLs) != 5 || urlset.URLs[0].Loc != "https://blog.example/posts/0?a=1&b=2" || urlset.URLs[0].LastMod != "2025-01-01T00:00:00Z" {
+		t.Errorf("Unexpected entries %+v", urlset.URLs)
+	}
+
+	if files := Split(urls(5), 2, 0); len(files) != 3 {
+		t.Errorf("Expected the URL limit to split into 3 files, got %d", len(files))
+	}
+
+	files = Split(urls(10), 0, 600)
+	if len(files) < 2 {
+		t.Fatalf("Expected the size limit to split the sitemap, got %d files", len(files))
+	}
+	total := 0
+	for _, file := range files {
+		if len(file) > 600 {
+			t.Errorf("File of %d bytes exceeds the limit", len(file))
+		}
+		total += strings.Count(string(file), "<url>")
+	}
+	if total != 10 {
+		t.Errorf("Expected 10 URLs across files, got %d", total)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	index := Index([]string{"https://blog.example/sitemaps/1.xml", "https://blog.example/sitemaps/2.xml"}, time.Time{})
+
+	var parsed struct {
+		Sitemaps []struct {
+			Loc string `xml:"loc"`
+		} `xml:"sitemap"`
+	}
+	if err := xml.Unmarshal(index, &parsed); err != nil {
+		t.Fatalf("Index is not valid XML: %v", err)
+	}
+	if len(parsed.Sitemaps) != 2 || strings.Contains(string(index), "lastmod") {
+		t.Errorf("Unexpected index %s", index)
+	}
+}
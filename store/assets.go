@@ -0,0 +1,94 @@
+package store
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+)
+
+// A post's assets live in its bundle directory, or for a flat file in a
+// directory of the same name beside it. Assets shared by all posts live in
+// the assets directory.
+const (
+	// SharedAssetsDir is the directory of assets shared by all posts
+	SharedAssetsDir = "assets"
+	// bundleIndex is the post file within a bundle directory
+	bundleIndex = "index.md"
+)
+
+// Asset is a file stored alongside posts, such as an image
+type Asset struct {
+	Name    string
+	ModTime time.Time
+	Content []byte
+}
+
+// AssetStore is implemented by stores that hold files alongside posts
+type AssetStore interface {
+	// Asset returns a file belonging to a post, or ErrNotFound. name is a
+	// slash-separated path looked up first in the post's bundle directory,
+	// then in the shared assets directory.
+	Asset(slug, name string) (Asset, error)
+}
+
+// validSlug reports whether slug can name a post file or directory
+func validSlug(slug string) bool {
+	return slug != "" && !strings.ContainsAny(slug, `/\`) && slug != "." && slug != ".." && slug != SharedAssetsDir
+}
+
+// assetPaths returns the paths, relative to the posts directory, at which
+// an asset of a post whose own assets are in dir may be found, in order of
+// preference. Names that would escape those directories, name hidden files
+// or name a bundle's post have none.
+func assetPaths(dir, name string) []string {
+	if !fs.ValidPath(name) || name == "." || strings.Contains(name, `\`) || hiddenPath(name) {
+		return nil
+	}
+
+	var paths []string
+	if dir != "" && name != bundleIndex {
+		paths = append(paths, path.Join(dir, name))
+	}
+	return append(paths, path.Join(SharedAssetsDir, name))
+}
+
+// readAsset reads an asset of a post whose own assets are in dir
+func readAsset(fsys fs.FS, dir, name string) (Asset, error) {
+	for _, p := range assetPaths(dir, name) {
+		info, err := fs.Stat(fsys, p)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		content, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return Asset{}, err
+		}
+		return Asset{Name: name, ModTime: info.ModTime(), Content: content}, nil
+	}
+	return Asset{}, ErrNotFound
+}
+
+// bundleVersion summarises the files of a bundle directory, so that a
+// bundle's version changes when any of its assets does
+func bundleVersion(fsys fs.FS, dir string) string {
+	var latest time.Time
+	var size int64
+	var count int
+	fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			if info.ModTime().After(latest) {
+				latest = info.ModTime()
+			}
+			size += info.Size()
+			count++
+		}
+		return nil
+	})
+	return fmt.Sprintf("%d-%d-%d", latest.UnixNano(), size, count)
+}
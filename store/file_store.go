@@ -0,0 +1,235 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"blog-api/persist"
+)
+
+// FileStore keeps posts as .md files in a directory tree on disk, arranged
+// as described by its Layout. New posts are written as <slug>.md at the top
+// level.
+type FileStore struct {
+	dir          string
+	pollInterval time.Duration
+	scanner      *scanner
+}
+
+// NewFileStore creates a FileStore rooted at dir, creating it if missing
+func NewFileStore(dir string) *FileStore {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		os.Mkdir(dir, 0755)
+	}
+	return &FileStore{
+		dir:          dir,
+		pollInterval: 2 * time.Second,
+		scanner:      newScanner(DefaultLayout),
+	}
+}
+
+// SetLayout changes how posts are found in the directory
+func (s *FileStore) SetLayout(layout Layout) {
+	s.scanner.setLayout(layout)
+}
+
+// List returns every post in the directory tree
+func (s *FileStore) List() ([]Entry, error) {
+	fsys := os.DirFS(s.dir)
+	posts, err := s.scanner.scan(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, file := range posts {
+		info, err := os.Stat(s.filePath(file))
+		if err != nil {
+			continue
+		}
+		entries = append(entries, s.entry(fsys, file, info))
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Slug < entries[j].Slug
+	})
+
+	return entries, nil
+}
+
+// Get reads a single post file
+func (s *FileStore) Get(slug string) (Document, error) {
+	fsys := os.DirFS(s.dir)
+	file, ok := s.scanner.lookup(fsys, slug)
+	if !ok {
+		return Document{}, ErrNotFound
+	}
+	path := s.filePath(file)
+
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return Document{}, ErrNotFound
+	} else if err != nil {
+		return Document{}, err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Document{}, err
+	}
+
+	return Document{Entry: s.entry(fsys, file, info), Content: content}, nil
+}
+
+// Asset reads a file from a post's own directory or the shared assets
+// directory
+func (s *FileStore) Asset(slug, name string) (Asset, error) {
+	fsys := os.DirFS(s.dir)
+	file, ok := s.scanner.lookup(fsys, slug)
+	if !ok {
+		return Asset{}, ErrNotFound
+	}
+	return readAsset(fsys, file.assetDir(), name)
+}
+
+// Put atomically writes a post file, in place for an existing post or as
+// <slug>.md for a new one
+func (s *FileStore) Put(slug string, content []byte) error {
+	path, err := s.path(slug)
+	if err != nil {
+		return err
+	}
+	return persist.WriteFileAtomic(path, content)
+}
+
+// Delete removes a post file. A bundle's whole directory is removed, since
+// its other markdown files would otherwise become posts of their own.
+func (s *FileStore) Delete(slug string) error {
+	if !validSlug(slug) {
+		return fmt.Errorf("%w: invalid slug %q", ErrNotFound, slug)
+	}
+	file, ok := s.scanner.lookup(os.DirFS(s.dir), slug)
+	if !ok {
+		return ErrNotFound
+	}
+
+	path, remove := s.filePath(file), os.Remove
+	if file.bundle != "" {
+		path, remove = filepath.Join(s.dir, filepath.FromSlash(file.bundle)), os.RemoveAll
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return ErrNotFound
+	}
+	if err := remove(path); err != nil {
+		return err
+	}
+	s.scanner.forget(slug)
+	return nil
+}
+
+// Rename moves a post to newSlug beside where it is now. A bundle's whole
+// directory is moved, as is a flat file's directory of assets, so nothing
+// is left behind at the old slug.
+func (s *FileStore) Rename(oldSlug, newSlug string, content []byte) error {
+	for _, slug := range []string{oldSlug, newSlug} {
+		if !validSlug(slug) {
+			return fmt.Errorf("%w: invalid slug %q", ErrNotFound, slug)
+		}
+	}
+	file, ok := s.scanner.lookup(os.DirFS(s.dir), oldSlug)
+	if !ok {
+		return ErrNotFound
+	}
+
+	if file.bundle != "" {
+		from := filepath.Join(s.dir, filepath.FromSlash(file.bundle))
+		to := filepath.Join(filepath.Dir(from), newSlug)
+		if err := s.move(from, to); err != nil {
+			return err
+		}
+		if err := persist.WriteFileAtomic(filepath.Join(to, bundleIndex), content); err != nil {
+			os.Rename(to, from)
+			return err
+		}
+		s.scanner.forget(oldSlug)
+		return nil
+	}
+
+	from := s.filePath(file)
+	to := filepath.Join(filepath.Dir(from), newSlug+".md")
+	if _, err := os.Stat(to); err == nil {
+		return fmt.Errorf("%s: %w", to, fs.ErrExist)
+	}
+	if err := persist.WriteFileAtomic(to, content); err != nil {
+		return err
+	}
+	assets := filepath.Join(s.dir, filepath.FromSlash(file.assetDir()))
+	if info, err := os.Stat(assets); err == nil && info.IsDir() {
+		if err := s.move(assets, strings.TrimSuffix(to, ".md")); err != nil {
+			os.Remove(to)
+			return err
+		}
+	}
+	if err := os.Remove(from); err != nil {
+		return err
+	}
+	s.scanner.forget(oldSlug)
+	return nil
+}
+
+// move renames a directory, refusing to replace anything already at to
+func (s *FileStore) move(from, to string) error {
+	if _, err := os.Stat(to); err == nil {
+		return fmt.Errorf("%s: %w", to, fs.ErrExist)
+	}
+	return os.Rename(from, to)
+}
+
+// Watch polls the directory for changes
+func (s *FileStore) Watch(ctx context.Context) (<-chan Event, error) {
+	return pollWatch(ctx, s.pollInterval, s.List)
+}
+
+// path returns the file path for slug: the file of the existing post, or
+// <slug>.md at the top level. Slugs that would escape the store directory
+// are refused.
+func (s *FileStore) path(slug string) (string, error) {
+	if !validSlug(slug) {
+		return "", fmt.Errorf("%w: invalid slug %q", ErrNotFound, slug)
+	}
+	if file, ok := s.scanner.lookup(os.DirFS(s.dir), slug); ok {
+		return s.filePath(file), nil
+	}
+	return filepath.Join(s.dir, slug+".md"), nil
+}
+
+// filePath returns the path on disk of a post file
+func (s *FileStore) filePath(file postFile) string {
+	return filepath.Join(s.dir, filepath.FromSlash(file.path))
+}
+
+// entry builds the Entry for a post file. A bundle's version covers its
+// assets as well as its index.md.
+func (s *FileStore) entry(fsys fs.FS, file postFile, info os.FileInfo) Entry {
+	entry := fileEntry(file.slug, info)
+	entry.Section = file.section
+	if file.bundle != "" {
+		entry.Version = bundleVersion(fsys, file.bundle)
+	}
+	return entry
+}
+
+// fileEntry builds an Entry from file info
+func fileEntry(slug string, info os.FileInfo) Entry {
+	return Entry{
+		Slug:    slug,
+		Version: fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size()),
+		ModTime: info.ModTime(),
+	}
+}
@@ -0,0 +1,132 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"sort"
+	"sync"
+)
+
+// FSStore serves documents read-only from an fs.FS, such as an embed.FS.
+// The contents cannot change, so the posts are listed once up front.
+type FSStore struct {
+	posts   fs.FS
+	scanner *scanner
+
+	mu         sync.Mutex
+	entries    []Entry
+	entriesErr error
+}
+
+// NewFSStore creates an FSStore reading the posts under dir in fsys
+func NewFSStore(fsys fs.FS, dir string) *FSStore {
+	posts := fsys
+	if dir != "" && dir != "." {
+		if sub, err := fs.Sub(fsys, dir); err == nil {
+			posts = sub
+		}
+	}
+
+	fss := &FSStore{
+		posts:   posts,
+		scanner: newScanner(DefaultLayout),
+	}
+	fss.index()
+	return fss
+}
+
+// SetLayout changes how posts are found in the directory
+func (fss *FSStore) SetLayout(layout Layout) {
+	fss.scanner.setLayout(layout)
+	fss.index()
+}
+
+// index lists the posts and their versions
+func (fss *FSStore) index() {
+	var entries []Entry
+	posts, err := fss.scanner.scan(fss.posts)
+	for _, file := range posts {
+		doc, err := fss.read(file)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, doc.Entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Slug < entries[j].Slug
+	})
+
+	fss.mu.Lock()
+	defer fss.mu.Unlock()
+	fss.entries, fss.entriesErr = entries, err
+}
+
+// List returns every post in the directory tree
+func (fss *FSStore) List() ([]Entry, error) {
+	fss.mu.Lock()
+	defer fss.mu.Unlock()
+
+	if fss.entriesErr != nil {
+		return nil, fss.entriesErr
+	}
+	return append([]Entry(nil), fss.entries...), nil
+}
+
+// Get reads a single document
+func (fss *FSStore) Get(slug string) (Document, error) {
+	file, ok := fss.scanner.lookup(fss.posts, slug)
+	if !ok {
+		return Document{}, ErrNotFound
+	}
+	return fss.read(file)
+}
+
+// read reads a post file. Embedded files carry no modification time, so
+// the version is derived from the content.
+func (fss *FSStore) read(file postFile) (Document, error) {
+	content, err := fs.ReadFile(fss.posts, file.path)
+	if err != nil {
+		return Document{}, ErrNotFound
+	}
+
+	entry := Entry{Slug: file.slug, Section: file.section}
+	if info, err := fs.Stat(fss.posts, file.path); err == nil {
+		entry.ModTime = info.ModTime()
+	}
+	sum := sha256.Sum256(content)
+	entry.Version = hex.EncodeToString(sum[:8])
+
+	return Document{Entry: entry, Content: content}, nil
+}
+
+// Asset reads a file from a post's own directory or the shared assets
+// directory
+func (fss *FSStore) Asset(slug, name string) (Asset, error) {
+	file, ok := fss.scanner.lookup(fss.posts, slug)
+	if !ok {
+		return Asset{}, ErrNotFound
+	}
+	return readAsset(fss.posts, file.assetDir(), name)
+}
+
+// Put always fails; the underlying filesystem is read-only
+func (fss *FSStore) Put(slug string, content []byte) error {
+	return ErrReadOnly
+}
+
+// Delete always fails; the underlying filesystem is read-only
+func (fss *FSStore) Delete(slug string) error {
+	return ErrReadOnly
+}
+
+// Watch never reports changes, since the contents cannot change
+func (fss *FSStore) Watch(ctx context.Context) (<-chan Event, error) {
+	events := make(chan Event)
+	go func() {
+		<-ctx.Done()
+		close(events)
+	}()
+	return events, nil
+}
@@ -0,0 +1,500 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// GitConfig configures a GitStore
+type GitConfig struct {
+	// Path is a bare repository or a working copy
+	Path string
+	// Dir is the directory within the repository holding posts; "" is the root
+	Dir string
+	// Branch to read; "" reads HEAD
+	Branch string
+	// Remote to fetch Branch from before each sync; "" disables fetching
+	Remote string
+	// Layout describes how posts are arranged in Dir; nil uses DefaultLayout
+	Layout *Layout
+}
+
+// GitStore serves posts from a commit in a git repository. Each sync builds
+// a complete snapshot of the posts and their history, which replaces the
+// current one only once it has been built successfully. The working tree is
+// never touched.
+type GitStore struct {
+	cfg  GitConfig
+	repo *git.Repository
+
+	syncMu sync.Mutex
+
+	mu       sync.RWMutex
+	snapshot *gitSnapshot
+	watchers map[chan Event]struct{}
+
+	// scanner reports duplicate slugs once across syncs
+	scanner *scanner
+}
+
+// gitSnapshot is the set of posts at a single commit
+type gitSnapshot struct {
+	commit   string
+	syncedAt time.Time
+	docs     map[string]Document
+	// tree is the posts directory, or nil if the commit has none
+	tree  *object.Tree
+	posts map[string]postFile
+}
+
+// gitHistory is what the commit log says about one post
+type gitHistory struct {
+	created time.Time
+	updated time.Time
+	authors []string
+}
+
+// NewGitStore opens the repository and performs an initial sync
+func NewGitStore(cfg GitConfig) (*GitStore, error) {
+	repo, err := git.PlainOpen(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("opening git repository %s: %w", cfg.Path, err)
+	}
+
+	layout := DefaultLayout
+	if cfg.Layout != nil {
+		layout = *cfg.Layout
+	}
+
+	gs := &GitStore{
+		cfg:      cfg,
+		repo:     repo,
+		watchers: make(map[chan Event]struct{}),
+		scanner:  newScanner(layout),
+	}
+
+	if _, err := gs.Sync(); err != nil {
+		return nil, err
+	}
+
+	return gs, nil
+}
+
+// Revision returns the commit currently served and when it was loaded
+func (gs *GitStore) Revision() (string, time.Time) {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.snapshot.commit, gs.snapshot.syncedAt
+}
+
+// Sync fetches the configured remote, if any, and loads the latest commit.
+// It reports whether the served commit changed.
+func (gs *GitStore) Sync() (bool, error) {
+	gs.syncMu.Lock()
+	defer gs.syncMu.Unlock()
+
+	if gs.cfg.Remote != "" {
+		if err := gs.fetch(); err != nil {
+			return false, err
+		}
+	}
+
+	hash, err := gs.resolve()
+	if err != nil {
+		return false, err
+	}
+
+	gs.mu.RLock()
+	previous := gs.snapshot
+	gs.mu.RUnlock()
+
+	if previous != nil && previous.commit == hash.String() {
+		gs.mu.Lock()
+		gs.snapshot.syncedAt = time.Now()
+		gs.mu.Unlock()
+		return false, nil
+	}
+
+	snapshot, err := gs.load(hash)
+	if err != nil {
+		return false, err
+	}
+
+	gs.mu.Lock()
+	gs.snapshot = snapshot
+	if previous != nil {
+		for _, event := range diffSnapshots(previous, snapshot) {
+			gs.notify(event)
+		}
+	}
+	gs.mu.Unlock()
+
+	return true, nil
+}
+
+// Run syncs every interval until ctx is cancelled
+func (gs *GitStore) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := gs.Sync(); err != nil {
+				fmt.Printf("Error syncing git content: %v\n", err)
+			}
+		}
+	}
+}
+
+// fetch updates the remote-tracking ref for the configured branch
+func (gs *GitStore) fetch() error {
+	branch := gs.cfg.Branch
+	if branch == "" {
+		branch = "main"
+	}
+
+	refSpec := gitconfig.RefSpec(fmt.Sprintf("+refs/heads/%s:refs/remotes/%s/%s", branch, gs.cfg.Remote, branch))
+	err := gs.repo.Fetch(&git.FetchOptions{
+		RemoteName: gs.cfg.Remote,
+		RefSpecs:   []gitconfig.RefSpec{refSpec},
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("fetching %s: %w", gs.cfg.Remote, err)
+	}
+
+	return nil
+}
+
+// resolve returns the commit to serve
+func (gs *GitStore) resolve() (plumbing.Hash, error) {
+	var name plumbing.ReferenceName
+	switch {
+	case gs.cfg.Remote != "":
+		branch := gs.cfg.Branch
+		if branch == "" {
+			branch = "main"
+		}
+		name = plumbing.NewRemoteReferenceName(gs.cfg.Remote, branch)
+	case gs.cfg.Branch != "":
+		name = plumbing.NewBranchReferenceName(gs.cfg.Branch)
+	default:
+		name = plumbing.HEAD
+	}
+
+	ref, err := gs.repo.Reference(name, true)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("resolving %s: %w", name, err)
+	}
+	return ref.Hash(), nil
+}
+
+// load reads every post at commit hash, along with its history
+func (gs *GitStore) load(hash plumbing.Hash) (*gitSnapshot, error) {
+	commit, err := gs.repo.CommitObject(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &gitSnapshot{
+		commit:   hash.String(),
+		syncedAt: time.Now(),
+		docs:     make(map[string]Document),
+	}
+
+	if gs.cfg.Dir != "" {
+		tree, err = tree.Tree(gs.cfg.Dir)
+		if errors.Is(err, object.ErrDirectoryNotFound) {
+			return snapshot, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	snapshot.tree = tree
+
+	var files []string
+	blobs := make(map[string]plumbing.Hash)
+	err = tree.Files().ForEach(func(f *object.File) error {
+		files = append(files, f.Name)
+		blobs[f.Name] = f.Hash
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	readSlug := func(name string) string {
+		content, err := gs.readBlob(blobs[name])
+		if err != nil {
+			return ""
+		}
+		return frontmatterSlug(content)
+	}
+	posts, duplicates := gs.scanner.layout.discover(files, readSlug)
+	gs.scanner.mu.Lock()
+	gs.scanner.report(duplicates)
+	gs.scanner.mu.Unlock()
+	snapshot.posts = posts
+
+	slugs := make(map[string]string, len(posts))
+	for slug, file := range posts {
+		slugs[path.Join(gs.cfg.Dir, file.path)] = slug
+	}
+	history, err := gs.history(commit, slugs)
+	if err != nil {
+		return nil, err
+	}
+
+	for slug, file := range posts {
+		content, err := gs.readBlob(blobs[file.path])
+		if err != nil {
+			return nil, err
+		}
+
+		version := blobs[file.path].String()
+		if file.bundle != "" {
+			// The bundle's tree hash changes along with any of its assets
+			if entry, err := tree.FindEntry(file.bundle); err == nil {
+				version = entry.Hash.String()
+			}
+		}
+
+		h := history[slug]
+		snapshot.docs[slug] = Document{
+			Entry: Entry{
+				Slug:    slug,
+				Version: version,
+				ModTime: h.updated,
+				Section: file.section,
+				Created: h.created,
+				Updated: h.updated,
+				Authors: h.authors,
+			},
+			Content: content,
+		}
+	}
+
+	return snapshot, nil
+}
+
+// history walks the log from head and records, for each post, when it was
+// first added, when it last changed and who has changed it. Merge commits
+// are skipped; their changes are attributed to the commits being merged.
+func (gs *GitStore) history(head *object.Commit, slugs map[string]string) (map[string]*gitHistory, error) {
+	history := make(map[string]*gitHistory)
+
+	iter, err := gs.repo.Log(&git.LogOptions{From: head.Hash, Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, err
+	}
+
+	err = iter.ForEach(func(commit *object.Commit) error {
+		if commit.NumParents() > 1 {
+			return nil
+		}
+
+		tree, err := commit.Tree()
+		if err != nil {
+			return err
+		}
+
+		var parentTree *object.Tree
+		if commit.NumParents() == 1 {
+			parent, err := commit.Parent(0)
+			if err != nil {
+				return err
+			}
+			if parentTree, err = parent.Tree(); err != nil {
+				return err
+			}
+		}
+
+		changes, err := object.DiffTree(parentTree, tree)
+		if err != nil {
+			return err
+		}
+
+		for _, change := range changes {
+			name := change.To.Name
+			if name == "" {
+				name = change.From.Name
+			}
+
+			slug, ok := slugs[name]
+			if !ok {
+				continue
+			}
+
+			h, ok := history[slug]
+			if !ok {
+				h = &gitHistory{updated: commit.Author.When}
+				history[slug] = h
+			}
+			// The log runs newest first, so the last commit seen is the oldest
+			h.created = commit.Author.When
+			if !containsString(h.authors, commit.Author.Name) {
+				h.authors = append(h.authors, commit.Author.Name)
+			}
+		}
+
+		return nil
+	})
+
+	return history, err
+}
+
+// readBlob returns the contents of a blob
+func (gs *GitStore) readBlob(hash plumbing.Hash) ([]byte, error) {
+	blob, err := gs.repo.BlobObject(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := blob.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+// List returns every post at the current commit
+func (gs *GitStore) List() ([]Entry, error) {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	entries := make([]Entry, 0, len(gs.snapshot.docs))
+	for _, doc := range gs.snapshot.docs {
+		entries = append(entries, doc.Entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Slug < entries[j].Slug
+	})
+
+	return entries, nil
+}
+
+// Get returns a post at the current commit
+func (gs *GitStore) Get(slug string) (Document, error) {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	doc, ok := gs.snapshot.docs[slug]
+	if !ok {
+		return Document{}, ErrNotFound
+	}
+	return doc, nil
+}
+
+// Asset reads a file from a post's bundle or the shared assets directory
+// at the current commit
+func (gs *GitStore) Asset(slug, name string) (Asset, error) {
+	gs.mu.RLock()
+	snapshot := gs.snapshot
+	gs.mu.RUnlock()
+
+	file, ok := snapshot.posts[slug]
+	if snapshot.tree == nil || !ok {
+		return Asset{}, ErrNotFound
+	}
+
+	for _, p := range assetPaths(file.assetDir(), name) {
+		entry, err := snapshot.tree.FindEntry(p)
+		if err != nil || !entry.Mode.IsFile() {
+			continue
+		}
+
+		content, err := gs.readBlob(entry.Hash)
+		if err != nil {
+			return Asset{}, err
+		}
+		return Asset{Name: name, ModTime: snapshot.docs[slug].Updated, Content: content}, nil
+	}
+	return Asset{}, ErrNotFound
+}
+
+// Put always fails; content is published by pushing to the repository
+func (gs *GitStore) Put(slug string, content []byte) error {
+	return ErrReadOnly
+}
+
+// Delete always fails; content is published by pushing to the repository
+func (gs *GitStore) Delete(slug string) error {
+	return ErrReadOnly
+}
+
+// Watch reports the posts changed by each sync
+func (gs *GitStore) Watch(ctx context.Context) (<-chan Event, error) {
+	events := make(chan Event, 64)
+
+	gs.mu.Lock()
+	gs.watchers[events] = struct{}{}
+	gs.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		gs.mu.Lock()
+		delete(gs.watchers, events)
+		close(events)
+		gs.mu.Unlock()
+	}()
+
+	return events, nil
+}
+
+// notify sends event to every watcher without blocking. The caller must
+// hold gs.mu.
+func (gs *GitStore) notify(event Event) {
+	for watcher := range gs.watchers {
+		select {
+		case watcher <- event:
+		default:
+		}
+	}
+}
+
+// diffSnapshots returns the events that turn previous into current
+func diffSnapshots(previous, current *gitSnapshot) []Event {
+	var events []Event
+	for slug, doc := range current.docs {
+		if old, ok := previous.docs[slug]; !ok || old.Version != doc.Version {
+			events = append(events, Event{Type: EventPut, Slug: slug})
+		}
+	}
+	for slug := range previous.docs {
+		if _, ok := current.docs[slug]; !ok {
+			events = append(events, Event{Type: EventDelete, Slug: slug})
+		}
+	}
+	return events
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// commitFile writes a file into a working copy and commits it
+func commitFile(t *testing.T, repo *git.Repository, dir, name, content, author string, when time.Time) {
+	path := filepath.Join(dir, name)
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	if _, err := worktree.Add(name); err != nil {
+		t.Fatalf("Failed to add %s: %v", name, err)
+	}
+
+	signature := &object.Signature{Name: author, Email: author + "@example.com", When: when}
+	if _, err := worktree.Commit("update "+name, &git.CommitOptions{Author: signature, Committer: signature}); err != nil {
+		t.Fatalf("Failed to commit %s: %v", name, err)
+	}
+}
+
+func TestGitStore(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+
+	created := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	updated := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+	commitFile(t, repo, dir, "posts/first.md", "v1", "alice", created)
+	commitFile(t, repo, dir, "README.md", "not a post", "alice", created)
+	commitFile(t, repo, dir, "posts/first.md", "v2", "bob", updated)
+
+	gs, err := NewGitStore(GitConfig{Path: dir, Dir: "posts"})
+	if err != nil {
+		t.Fatalf("NewGitStore failed: %v", err)
+	}
+
+	entries, _ := gs.List()
+	if len(entries) != 1 || entries[0].Slug != "first" {
+		t.Fatalf("Expected only the post to be listed, got %+v", entries)
+	}
+
+	doc, err := gs.Get("first")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if string(doc.Content) != "v2" {
+		t.Errorf("Expected the latest content, got %q", doc.Content)
+	}
+	if !doc.Created.Equal(created) || !doc.Updated.Equal(updated) {
+		t.Errorf("Expected created %v and updated %v, got %v and %v", created, updated, doc.Created, doc.Updated)
+	}
+	if len(doc.Authors) != 2 || doc.Authors[0] != "bob" || doc.Authors[1] != "alice" {
+		t.Errorf("Expected authors [bob alice], got %v", doc.Authors)
+	}
+
+	if err := gs.Put("first", nil); err != ErrReadOnly {
+		t.Errorf("Expected ErrReadOnly, got %v", err)
+	}
+
+	firstRevision, _ := gs.Revision()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, _ := gs.Watch(ctx)
+
+	commitFile(t, repo, dir, "posts/second.md", "new", "carol", updated.Add(time.Hour))
+
+	changed, err := gs.Sync()
+	if err != nil || !changed {
+		t.Fatalf("Expected Sync to pick up a new commit, got %v, %v", changed, err)
+	}
+	if revision, _ := gs.Revision(); revision == firstRevision {
+		t.Error("Revision should change after sync")
+	}
+
+	select {
+	case event := <-events:
+		if event.Type != EventPut || event.Slug != "second" {
+			t.Errorf("Unexpected event %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for a sync event")
+	}
+
+	if changed, _ := gs.Sync(); changed {
+		t.Error("Sync without new commits should report no change")
+	}
+}
+
+func TestGitStore_FetchesRemote(t *testing.T) {
+	originDir := t.TempDir()
+	origin, err := git.PlainInit(originDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init origin: %v", err)
+	}
+	commitFile(t, origin, originDir, "posts/first.md", "v1", "alice", time.Now())
+
+	head, _ := origin.Head()
+	branch := head.Name().Short()
+
+	cloneDir := t.TempDir()
+	if _, err := git.PlainClone(cloneDir, true, &git.CloneOptions{URL: originDir}); err != nil {
+		t.Fatalf("Failed to clone: %v", err)
+	}
+
+	gs, err := NewGitStore(GitConfig{Path: cloneDir, Dir: "posts", Branch: branch, Remote: "origin"})
+	if err != nil {
+		t.Fatalf("NewGitStore failed: %v", err)
+	}
+
+	commitFile(t, origin, originDir, "posts/second.md", "v1", "alice", time.Now())
+
+	if _, err := gs.Get("second"); err != ErrNotFound {
+		t.Errorf("Expected the new post to be missing before sync, got %v", err)
+	}
+	if _, err := gs.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if _, err := gs.Get("second"); err != nil {
+		t.Errorf("Expected the new post after sync, got %v", err)
+	}
+}
+
+func TestGitStore_Bundles(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+
+	when := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	commitFile(t, repo, dir, "posts/trip/index.md", "bundle", "alice", when)
+	commitFile(t, repo, dir, "posts/trip/photo.jpg", "jpeg", "alice", when)
+	commitFile(t, repo, dir, "posts/assets/logo.png", "png", "alice", when)
+
+	gs, err := NewGitStore(GitConfig{Path: dir, Dir: "posts"})
+	if err != nil {
+		t.Fatalf("NewGitStore failed: %v", err)
+	}
+
+	doc, err := gs.Get("trip")
+	if err != nil || string(doc.Content) != "bundle" {
+		t.Fatalf("Get returned %q, %v", doc.Content, err)
+	}
+	if !doc.Created.Equal(when) {
+		t.Errorf("Expected the bundle's history, got created %v", doc.Created)
+	}
+
+	if asset, err := gs.Asset("trip", "photo.jpg"); err != nil || string(asset.Content) != "jpeg" {
+		t.Errorf("Asset returned %q, %v", asset.Content, err)
+	}
+	if asset, err := gs.Asset("trip", "logo.png"); err != nil || string(asset.Content) != "png" {
+		t.Errorf("Asset returned %q, %v", asset.Content, err)
+	}
+	if _, err := gs.Asset("trip", "missing.jpg"); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	// Changing an asset changes the bundle's version
+	commitFile(t, repo, dir, "posts/trip/photo.jpg", "new jpeg", "bob", when.Add(time.Hour))
+	gs.Sync()
+	if updated, _ := gs.Get("trip"); updated.Version == doc.Version {
+		t.Error("Version should change when an asset in the bundle changes")
+	}
+}
@@ -0,0 +1,401 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Slug sources, in the order a Layout may prefer them
+const (
+	// SlugFromFrontmatter takes the slug from a post's slug frontmatter key
+	SlugFromFrontmatter = "frontmatter"
+	// SlugFromFilename takes the slug from a post's file name, or a
+	// bundle's directory name
+	SlugFromFilename = "filename"
+	// SlugFromDirectory qualifies the file name with the directories
+	// leading to it, so posts/2025/my-post.md is 2025-my-post
+	SlugFromDirectory = "directory"
+)
+
+// Layout describes how posts are arranged in a posts directory. Posts are
+// the .md files at any depth. A directory holding an index.md is a bundle:
+// index.md is the post and everything else in the directory belongs to it.
+type Layout struct {
+	// SlugSources lists where slugs come from, most preferred first. A
+	// source that yields nothing falls through to the next; the file name
+	// is always the last resort.
+	SlugSources []string
+	// Ignore lists .gitignore-style patterns of paths that are not posts
+	Ignore []string
+}
+
+// DefaultLayout takes slugs from frontmatter or the file name and skips
+// drafts
+var DefaultLayout = Layout{
+	SlugSources: []string{SlugFromFrontmatter, SlugFromFilename},
+	Ignore:      []string{"_drafts/"},
+}
+
+// Validate checks that every slug source is known
+func (l Layout) Validate() error {
+	for _, source := range l.SlugSources {
+		switch source {
+		case SlugFromFrontmatter, SlugFromFilename, SlugFromDirectory:
+		default:
+			return fmt.Errorf("unknown slug source %q", source)
+		}
+	}
+	return nil
+}
+
+// postFile is a post found in a posts directory
+type postFile struct {
+	slug string
+	// path is the post's file, relative to the posts directory
+	path string
+	// section is the directory holding the post or its bundle
+	section string
+	// bundle is the post's bundle directory, or "" for a flat file
+	bundle string
+}
+
+// assetDir returns the directory whose files belong to the post: its
+// bundle, or for a flat file a directory of the same name beside it
+func (pf postFile) assetDir() string {
+	if pf.bundle != "" {
+		return pf.bundle
+	}
+	return strings.TrimSuffix(pf.path, ".md")
+}
+
+// discover assigns a slug to each post among files, the slash-separated
+// paths of every file in a posts directory. readSlug returns the slug in a
+// post's frontmatter, if any. When several posts claim one slug, bundles
+// win over flat files and then the first path in order; the others are
+// returned as duplicates.
+func (l Layout) discover(files []string, readSlug func(name string) string) (map[string]postFile, map[string][]string) {
+	ignore := parseIgnore(l.Ignore)
+
+	bundles := make(map[string]bool)
+	var candidates []string
+	for _, name := range files {
+		if path.Ext(name) != ".md" || hiddenPath(name) || ignore.matches(name) {
+			continue
+		}
+		if dir := path.Dir(name); path.Base(name) == bundleIndex && dir != "." {
+			bundles[dir] = true
+		}
+		candidates = append(candidates, name)
+	}
+
+	var found []postFile
+	for _, name := range candidates {
+		if top, _, _ := strings.Cut(name, "/"); top == SharedAssetsDir && strings.Contains(name, "/") {
+			continue
+		}
+
+		file := postFile{path: name}
+		if dir := path.Dir(name); path.Base(name) == bundleIndex && bundles[dir] {
+			file.bundle = dir
+		}
+		// Markdown files inside a bundle are its assets, not posts
+		if inBundle(name, file.bundle, bundles) {
+			continue
+		}
+
+		file.section = path.Dir(name)
+		if file.bundle != "" {
+			file.section = path.Dir(file.bundle)
+		}
+		if file.section == "." {
+			file.section = ""
+		}
+
+		file.slug = l.slug(file, readSlug)
+		if !validSlug(file.slug) {
+			continue
+		}
+		found = append(found, file)
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		if (found[i].bundle != "") != (found[j].bundle != "") {
+			return found[i].bundle != ""
+		}
+		return found[i].path < found[j].path
+	})
+
+	posts := make(map[string]postFile, len(found))
+	duplicates := make(map[string][]string)
+	for _, file := range found {
+		if first, ok := posts[file.slug]; ok {
+			if len(duplicates[file.slug]) == 0 {
+				duplicates[file.slug] = []string{first.path}
+			}
+			duplicates[file.slug] = append(duplicates[file.slug], file.path)
+			continue
+		}
+		posts[file.slug] = file
+	}
+
+	return posts, duplicates
+}
+
+// slug derives the slug of a post from the first source that yields one
+func (l Layout) slug(file postFile, readSlug func(name string) string) string {
+	name := strings.TrimSuffix(file.path, ".md")
+	if file.bundle != "" {
+		name = file.bundle
+	}
+
+	for _, source := range l.SlugSources {
+		var slug string
+		switch source {
+		case SlugFromFrontmatter:
+			if readSlug != nil {
+				slug = readSlug(file.path)
+			}
+		case SlugFromFilename:
+			slug = path.Base(name)
+		case SlugFromDirectory:
+			slug = strings.ReplaceAll(name, "/", "-")
+		}
+		if validSlug(slug) {
+			return slug
+		}
+	}
+	return path.Base(name)
+}
+
+// inBundle reports whether name lies inside a bundle other than its own
+func inBundle(name, own string, bundles map[string]bool) bool {
+	for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+		if bundles[dir] && dir != own {
+			return true
+		}
+	}
+	return false
+}
+
+// hiddenPath reports whether any element of name starts with a dot
+func hiddenPath(name string) bool {
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoreRule is a parsed .gitignore-style pattern
+type ignoreRule struct {
+	pattern string
+	negate  bool
+	dirOnly bool
+	// anchored patterns match from the root rather than any directory
+	anchored bool
+}
+
+// ignoreRules is a list of patterns in which the last match wins
+type ignoreRules []ignoreRule
+
+// parseIgnore parses .gitignore-style patterns. Blank lines and comments
+// are skipped.
+func parseIgnore(patterns []string) ignoreRules {
+	var rules ignoreRules
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+
+		var rule ignoreRule
+		if rule.negate = strings.HasPrefix(pattern, "!"); rule.negate {
+			pattern = pattern[1:]
+		}
+		if rule.dirOnly = strings.HasSuffix(pattern, "/"); rule.dirOnly {
+			pattern = strings.TrimSuffix(pattern, "/")
+		}
+		rule.anchored = strings.Contains(pattern, "/")
+		rule.pattern = strings.TrimPrefix(pattern, "/")
+		if rule.pattern != "" {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// matches reports whether the file at name, or a directory containing it,
+// is ignored
+func (rules ignoreRules) matches(name string) bool {
+	if len(rules) == 0 {
+		return false
+	}
+
+	parts := strings.Split(name, "/")
+	ignored := false
+	for _, rule := range rules {
+		for i := range parts {
+			isDir := i < len(parts)-1
+			if rule.dirOnly && !isDir {
+				continue
+			}
+			var matched bool
+			if rule.anchored {
+				matched, _ = path.Match(rule.pattern, strings.Join(parts[:i+1], "/"))
+			} else {
+				matched, _ = path.Match(rule.pattern, parts[i])
+			}
+			if matched {
+				ignored = !rule.negate
+				break
+			}
+		}
+	}
+	return ignored
+}
+
+// frontmatterSlug returns the value of the slug key in a document's
+// frontmatter, if it has one
+func frontmatterSlug(content []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != "---" {
+		return ""
+	}
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "---" {
+			break
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if ok && strings.TrimSpace(key) == "slug" {
+			return strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+	}
+	return ""
+}
+
+// scanner discovers the posts in a filesystem according to a Layout,
+// remembering what it found so single posts can be looked up between scans
+type scanner struct {
+	mu       sync.Mutex
+	layout   Layout
+	posts    map[string]postFile
+	slugs    map[string]cachedSlug
+	reported map[string]string
+}
+
+// cachedSlug is the frontmatter slug of a file at a version
+type cachedSlug struct {
+	version string
+	slug    string
+}
+
+// newScanner creates a scanner using layout
+func newScanner(layout Layout) *scanner {
+	return &scanner{layout: layout}
+}
+
+// setLayout changes the layout, forgetting what was found with the old one
+func (s *scanner) setLayout(layout Layout) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.layout = layout
+	s.posts = nil
+}
+
+// scan walks fsys and returns every post, keyed by slug
+func (s *scanner) scan(fsys fs.FS) (map[string]postFile, error) {
+	var files []string
+	versions := make(map[string]string)
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name != "." && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			files = append(files, name)
+			if info, err := d.Info(); err == nil {
+				versions[name] = fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	slugs := make(map[string]cachedSlug)
+	readSlug := func(name string) string {
+		cached, ok := s.slugs[name]
+		if !ok || cached.version != versions[name] {
+			content, err := fs.ReadFile(fsys, name)
+			if err != nil {
+				return ""
+			}
+			cached = cachedSlug{version: versions[name], slug: frontmatterSlug(content)}
+		}
+		slugs[name] = cached
+		return cached.slug
+	}
+
+	posts, duplicates := s.layout.discover(files, readSlug)
+	s.posts = posts
+	s.slugs = slugs
+	s.report(duplicates)
+
+	return posts, nil
+}
+
+// lookup returns the post with slug, scanning fsys if it has not been seen
+func (s *scanner) lookup(fsys fs.FS, slug string) (postFile, bool) {
+	s.mu.Lock()
+	file, ok := s.posts[slug]
+	s.mu.Unlock()
+
+	if ok || !validSlug(slug) {
+		return file, ok
+	}
+	posts, err := s.scan(fsys)
+	if err != nil {
+		return postFile{}, false
+	}
+	file, ok = posts[slug]
+	return file, ok
+}
+
+// forget drops a post, such as one just deleted
+func (s *scanner) forget(slug string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.posts, slug)
+}
+
+// report logs each set of posts sharing a slug once. The caller must hold
+// s.mu.
+func (s *scanner) report(duplicates map[string][]string) {
+	reported := make(map[string]string, len(duplicates))
+	for slug, paths := range duplicates {
+		summary := strings.Join(paths, ", ")
+		if s.reported[slug] != summary {
+			fmt.Printf("Warning: slug %q is claimed by %s; serving %s\n", slug, summary, paths[0])
+		}
+		reported[slug] = summary
+	}
+	s.reported = reported
+}
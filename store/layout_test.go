@@ -0,0 +1,155 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLayoutDiscover(t *testing.T) {
+	files := []string{
+		"hello.md",
+		"2025/my-post.md",
+		"2025/trip/index.md",
+		"2025/trip/photo.jpg",
+		"2025/trip/notes.md",
+		"guides/k8s/setup.md",
+		"_drafts/wip.md",
+		"guides/_drafts/later.md",
+		"assets/readme.md",
+		"custom.md",
+	}
+	frontmatter := map[string]string{"custom.md": "renamed"}
+	readSlug := func(name string) string { return frontmatter[name] }
+
+	tests := []struct {
+		name   string
+		layout Layout
+		want   map[string]postFile
+	}{
+		{
+			name:   "default",
+			layout: DefaultLayout,
+			want: map[string]postFile{
+				"hello":   {slug: "hello", path: "hello.md"},
+				"my-post": {slug: "my-post", path: "2025/my-post.md", section: "2025"},
+				"trip":    {slug: "trip", path: "2025/trip/index.md", section: "2025", bundle: "2025/trip"},
+				"setup":   {slug: "setup", path: "guides/k8s/setup.md", section: "guides/k8s"},
+				"renamed": {slug: "renamed", path: "custom.md"},
+			},
+		},
+		{
+			name:   "directory slugs",
+			layout: Layout{SlugSources: []string{SlugFromDirectory}, Ignore: []string{"_drafts/", "!guides/_drafts/"}},
+			want: map[string]postFile{
+				"hello":                {slug: "hello", path: "hello.md"},
+				"2025-my-post":         {slug: "2025-my-post", path: "2025/my-post.md", section: "2025"},
+				"2025-trip":            {slug: "2025-trip", path: "2025/trip/index.md", section: "2025", bundle: "2025/trip"},
+				"guides-k8s-setup":     {slug: "guides-k8s-setup", path: "guides/k8s/setup.md", section: "guides/k8s"},
+				"guides-_drafts-later": {slug: "guides-_drafts-later", path: "guides/_drafts/later.md", section: "guides/_drafts"},
+				"custom":               {slug: "custom", path: "custom.md"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posts, duplicates := tt.layout.discover(files, readSlug)
+			if !reflect.DeepEqual(posts, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, posts)
+			}
+			if len(duplicates) != 0 {
+				t.Errorf("Expected no duplicates, got %v", duplicates)
+			}
+		})
+	}
+}
+
+func TestLayoutDiscover_Duplicates(t *testing.T) {
+	files := []string{"a/post.md", "b/post.md", "post/index.md", "other.md"}
+	posts, duplicates := DefaultLayout.discover(files, func(name string) string {
+		if name == "other.md" {
+			return "post"
+		}
+		return ""
+	})
+
+	if posts["post"].path != "post/index.md" {
+		t.Errorf("Expected the bundle to win, got %+v", posts["post"])
+	}
+	want := []string{"post/index.md", "a/post.md", "b/post.md", "other.md"}
+	if !reflect.DeepEqual(duplicates["post"], want) {
+		t.Errorf("Expected duplicates %v, got %v", want, duplicates["post"])
+	}
+}
+
+func TestIgnoreRules(t *testing.T) {
+	rules := parseIgnore([]string{"# comment", "_drafts/", "/private", "*.tmp.md", "!keep.tmp.md"})
+	tests := map[string]bool{
+		"post.md":              false,
+		"_drafts/post.md":      true,
+		"2025/_drafts/post.md": true,
+		"_drafts.md":           false,
+		"private/post.md":      true,
+		"2025/private/post.md": false,
+		"notes.tmp.md":         true,
+		"keep.tmp.md":          false,
+	}
+	for name, want := range tests {
+		if got := rules.matches(name); got != want {
+			t.Errorf("matches(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestFrontmatterSlug(t *testing.T) {
+	tests := map[string]string{
+		"---\ntitle: \"Hi\"\nslug: \"custom\"\n---\n\nBody": "custom",
+		"---\nslug: bare\n---\n":                            "bare",
+		"---\ntitle: \"Hi\"\n---\n\nslug: body":             "",
+		"slug: no-frontmatter":                              "",
+	}
+	for content, want := range tests {
+		if got := frontmatterSlug([]byte(content)); got != want {
+			t.Errorf("frontmatterSlug(%q) = %q, want %q", content, got, want)
+		}
+	}
+}
+
+func TestFileStore_NestedPosts(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+	write("2025/nested.md", "nested")
+	write("2025/nested/photo.jpg", "jpeg")
+	write("_drafts/draft.md", "draft")
+
+	s := NewFileStore(dir)
+	entries, err := s.List()
+	if err != nil || len(entries) != 1 || entries[0].Slug != "nested" || entries[0].Section != "2025" {
+		t.Fatalf("Expected only the nested post, got %+v, %v", entries, err)
+	}
+
+	// Writes to an existing post go to its file
+	if err := s.Put("nested", []byte("updated")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "2025", "nested.md")); string(content) != "updated" {
+		t.Errorf("Expected the nested file to be updated, got %q", content)
+	}
+
+	if asset, err := s.Asset("nested", "photo.jpg"); err != nil || string(asset.Content) != "jpeg" {
+		t.Errorf("Asset returned %q, %v", asset.Content, err)
+	}
+
+	if err := s.Delete("nested"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := s.Get("nested"); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps documents in memory. It is intended for tests.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	docs     map[string]Document
+	assets   map[string]Asset
+	version  int
+	watchers map[chan Event]struct{}
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		docs:     make(map[string]Document),
+		assets:   make(map[string]Asset),
+		watchers: make(map[chan Event]struct{}),
+	}
+}
+
+// List returns every document, ordered by slug
+func (ms *MemoryStore) List() ([]Entry, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	entries := make([]Entry, 0, len(ms.docs))
+	for _, doc := range ms.docs {
+		entries = append(entries, doc.Entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Slug < entries[j].Slug
+	})
+
+	return entries, nil
+}
+
+// Get returns a copy of a document
+func (ms *MemoryStore) Get(slug string) (Document, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	doc, ok := ms.docs[slug]
+	if !ok {
+		return Document{}, ErrNotFound
+	}
+	doc.Content = append([]byte(nil), doc.Content...)
+	return doc, nil
+}
+
+// Put stores a copy of content
+func (ms *MemoryStore) Put(slug string, content []byte) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.version++
+	ms.docs[slug] = Document{
+		Entry: Entry{
+			Slug:    slug,
+			Version: strconv.Itoa(ms.version),
+			ModTime: time.Now(),
+		},
+		Content: append([]byte(nil), content...),
+	}
+	ms.notify(Event{Type: EventPut, Slug: slug})
+
+	return nil
+}
+
+// PutAsset stores a copy of an asset at p, a path relative to the posts
+// directory such as <slug>/photo.jpg or assets/logo.png
+func (ms *MemoryStore) PutAsset(p string, content []byte) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.assets[p] = Asset{ModTime: time.Now(), Content: append([]byte(nil), content...)}
+}
+
+// Asset returns a copy of an asset from a post's bundle or the shared
+// assets directory
+func (ms *MemoryStore) Asset(slug, name string) (Asset, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	for _, p := range assetPaths(slug, name) {
+		if asset, ok := ms.assets[p]; ok {
+			asset.Name = name
+			asset.Content = append([]byte(nil), asset.Content...)
+			return asset, nil
+		}
+	}
+	return Asset{}, ErrNotFound
+}
+
+// Delete removes a document
+func (ms *MemoryStore) Delete(slug string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, ok := ms.docs[slug]; !ok {
+		return ErrNotFound
+	}
+	delete(ms.docs, slug)
+	ms.notify(Event{Type: EventDelete, Slug: slug})
+
+	return nil
+}
+
+// Watch reports every Put and Delete. Events are dropped for watchers that
+// fall more than a small buffer behind.
+func (ms *MemoryStore) Watch(ctx context.Context) (<-chan Event, error) {
+	events := make(chan Event, 16)
+
+	ms.mu.Lock()
+	ms.watchers[events] = struct{}{}
+	ms.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		ms.mu.Lock()
+		delete(ms.watchers, events)
+		close(events)
+		ms.mu.Unlock()
+	}()
+
+	return events, nil
+}
+
+// notify sends event to every watcher without blocking. The caller must
+// hold ms.mu.
+func (ms *MemoryStore) notify(event Event) {
+	for watcher := range ms.watchers {
+		select {
+		case watcher <- event:
+		default:
+		}
+	}
+}
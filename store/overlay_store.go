@@ -0,0 +1,172 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// OverlayStore layers a writable store over a read-only base. Documents in
+// the upper store shadow base documents with the same slug. Writes always go
+// to the upper store, so deleting a shadowing document reveals the base one
+// again; base-only documents cannot be deleted.
+type OverlayStore struct {
+	upper PostStore
+	base  PostStore
+}
+
+// NewOverlayStore creates an OverlayStore
+func NewOverlayStore(upper, base PostStore) *OverlayStore {
+	return &OverlayStore{
+		upper: upper,
+		base:  base,
+	}
+}
+
+// List returns the union of both stores, preferring upper documents
+func (o *OverlayStore) List() ([]Entry, error) {
+	upper, err := o.upper.List()
+	if err != nil {
+		return nil, err
+	}
+	base, err := o.base.List()
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(map[string]Entry, len(upper)+len(base))
+	for _, entry := range base {
+		entry.Version = "base:" + entry.Version
+		merged[entry.Slug] = entry
+	}
+	for _, entry := range upper {
+		entry.Version = "upper:" + entry.Version
+		merged[entry.Slug] = entry
+	}
+
+	entries := make([]Entry, 0, len(merged))
+	for _, entry := range merged {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Slug < entries[j].Slug
+	})
+
+	return entries, nil
+}
+
+// Get returns the upper document if present, otherwise the base one
+func (o *OverlayStore) Get(slug string) (Document, error) {
+	doc, err := o.upper.Get(slug)
+	if err == nil {
+		doc.Version = "upper:" + doc.Version
+		return doc, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return Document{}, err
+	}
+
+	doc, err = o.base.Get(slug)
+	if err != nil {
+		return Document{}, err
+	}
+	doc.Version = "base:" + doc.Version
+	return doc, nil
+}
+
+// Asset returns the upper store's asset if present, otherwise the base
+// one's
+func (o *OverlayStore) Asset(slug, name string) (Asset, error) {
+	for _, s := range []PostStore{o.upper, o.base} {
+		assets, ok := s.(AssetStore)
+		if !ok {
+			continue
+		}
+		asset, err := assets.Asset(slug, name)
+		if !errors.Is(err, ErrNotFound) {
+			return asset, err
+		}
+	}
+	return Asset{}, ErrNotFound
+}
+
+// Put writes to the upper store
+func (o *OverlayStore) Put(slug string, content []byte) error {
+	return o.upper.Put(slug, content)
+}
+
+// Delete removes the upper document. Base-only documents are read-only.
+func (o *OverlayStore) Delete(slug string) error {
+	err := o.upper.Delete(slug)
+	if errors.Is(err, ErrNotFound) {
+		if _, baseErr := o.base.Get(slug); baseErr == nil {
+			return ErrReadOnly
+		}
+	}
+	return err
+}
+
+// Rename moves an upper document, using the upper store's Rename when it
+// has one. Base-only documents are read-only, so nothing is written for
+// them.
+func (o *OverlayStore) Rename(oldSlug, newSlug string, content []byte) error {
+	if _, err := o.upper.Get(oldSlug); errors.Is(err, ErrNotFound) {
+		if _, baseErr := o.base.Get(oldSlug); baseErr == nil {
+			return ErrReadOnly
+		}
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+
+	if renamer, ok := o.upper.(Renamer); ok {
+		return renamer.Rename(oldSlug, newSlug, content)
+	}
+	if err := o.upper.Put(newSlug, content); err != nil {
+		return err
+	}
+	return o.upper.Delete(oldSlug)
+}
+
+// Watch merges the change events of both stores
+func (o *OverlayStore) Watch(ctx context.Context) (<-chan Event, error) {
+	upper, err := o.upper.Watch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	base, err := o.base.Watch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan Event)
+	var wg sync.WaitGroup
+
+	forward := func(source <-chan Event) {
+		defer wg.Done()
+		for event := range source {
+			// A delete in one layer may leave the slug present in the other
+			if event.Type == EventDelete {
+				if _, err := o.Get(event.Slug); err == nil {
+					event.Type = EventPut
+				}
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+			}
+		}
+	}
+
+	wg.Add(2)
+	go forward(upper)
+	go forward(base)
+
+	go func() {
+		wg.Wait()
+		close(events)
+	}()
+
+	return events, nil
+}
@@ -0,0 +1,156 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when no document exists for a slug
+	ErrNotFound = errors.New("document not found")
+
+	// ErrReadOnly is returned by stores that cannot be written to
+	ErrReadOnly = errors.New("store is read-only")
+)
+
+// Entry describes a stored document without its content
+type Entry struct {
+	Slug string
+	// Version changes whenever the document's content changes
+	Version string
+	ModTime time.Time
+	// Section is the directory holding the post, "" at the top level
+	Section string
+	// Created, Updated and Authors are filled in by stores that keep
+	// history, such as GitStore
+	Created time.Time
+	Updated time.Time
+	Authors []string
+}
+
+// Document is a raw post: frontmatter followed by markdown
+type Document struct {
+	Entry
+	Content []byte
+}
+
+// EventType is the kind of change reported by Watch
+type EventType int
+
+const (
+	// EventPut is sent when a document is created or changed
+	EventPut EventType = iota
+	// EventDelete is sent when a document is removed
+	EventDelete
+)
+
+// Event is a change to a single document
+type Event struct {
+	Type EventType
+	Slug string
+}
+
+// PostStore stores raw post documents keyed by slug
+type PostStore interface {
+	// List returns every document in the store
+	List() ([]Entry, error)
+	// Get returns a single document, or ErrNotFound
+	Get(slug string) (Document, error)
+	// Put creates or replaces a document
+	Put(slug string, content []byte) error
+	// Delete removes a document, or returns ErrNotFound
+	Delete(slug string) error
+	// Watch reports changes until ctx is cancelled, then closes the channel
+	Watch(ctx context.Context) (<-chan Event, error)
+}
+
+// Revisioner is implemented by stores that serve a specific revision of
+// their content
+type Revisioner interface {
+	// Revision returns the revision served and when it was last synced
+	Revision() (string, time.Time)
+}
+
+// Renamer is implemented by stores that keep files belonging to a document
+// beside it, so a document that changes slug must be moved as a whole
+type Renamer interface {
+	// Rename moves the document at oldSlug, and everything belonging to it,
+	// to newSlug and replaces its content. It returns ErrNotFound if there
+	// is no document at oldSlug.
+	Rename(oldSlug, newSlug string, content []byte) error
+}
+
+// Syncer is implemented by stores that pull content from elsewhere
+type Syncer interface {
+	// Sync loads the latest content and reports whether it changed
+	Sync() (bool, error)
+}
+
+// pollWatch emits events for differences between successive listings taken
+// every interval. It suits stores without native change notification.
+func pollWatch(ctx context.Context, interval time.Duration, list func() ([]Entry, error)) (<-chan Event, error) {
+	previous, err := versions(list)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan Event)
+
+	go func() {
+		defer close(events)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			current, err := versions(list)
+			if err != nil {
+				continue
+			}
+
+			var changes []Event
+			for slug, version := range current {
+				if previous[slug] != version {
+					changes = append(changes, Event{Type: EventPut, Slug: slug})
+				}
+			}
+			for slug := range previous {
+				if _, ok := current[slug]; !ok {
+					changes = append(changes, Event{Type: EventDelete, Slug: slug})
+				}
+			}
+			previous = current
+
+			for _, event := range changes {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// versions maps each listed slug to its version
+func versions(list func() ([]Entry, error)) (map[string]string, error) {
+	entries, err := list()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		result[entry.Slug] = entry.Version
+	}
+	return result, nil
+}
@@ -0,0 +1,322 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// testStoreContract exercises the behaviour every writable store shares
+func testStoreContract(t *testing.T, s PostStore) {
+	if _, err := s.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	if err := s.Put("hello", []byte("one")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	first, err := s.Get("hello")
+	if err != nil || string(first.Content) != "one" {
+		t.Fatalf("Get returned %q, %v", first.Content, err)
+	}
+
+	s.Put("hello", []byte("two, and longer"))
+	second, _ := s.Get("hello")
+	if second.Version == first.Version {
+		t.Error("Version should change when content changes")
+	}
+
+	entries, err := s.List()
+	if err != nil || len(entries) != 1 || entries[0].Slug != "hello" {
+		t.Errorf("List returned %+v, %v", entries, err)
+	}
+
+	if err := s.Delete("hello"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := s.Delete("hello"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting twice, got %v", err)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	testStoreContract(t, NewFileStore(t.TempDir()))
+}
+
+func TestFileStore_RejectsPathTraversal(t *testing.T) {
+	s := NewFileStore(t.TempDir())
+	if err := s.Put("../escape", []byte("x")); err == nil {
+		t.Error("Expected a slug containing a path separator to be rejected")
+	}
+}
+
+func TestFileStore_Watch(t *testing.T) {
+	s := NewFileStore(t.TempDir())
+	s.pollInterval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := s.Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	s.Put("new-post", []byte("content"))
+
+	select {
+	case event := <-events:
+		if event.Type != EventPut || event.Slug != "new-post" {
+			t.Errorf("Unexpected event %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for a put event")
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStoreContract(t, NewMemoryStore())
+}
+
+func TestMemoryStore_Watch(t *testing.T) {
+	s := NewMemoryStore()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, _ := s.Watch(ctx)
+
+	s.Put("a", []byte("x"))
+	s.Delete("a")
+
+	if event := <-events; event.Type != EventPut {
+		t.Errorf("Expected a put event, got %+v", event)
+	}
+	if event := <-events; event.Type != EventDelete {
+		t.Errorf("Expected a delete event, got %+v", event)
+	}
+
+	cancel()
+	if _, ok := <-events; ok {
+		t.Error("Expected the channel to close after cancel")
+	}
+}
+
+func TestFSStore(t *testing.T) {
+	s := NewFSStore(fstest.MapFS{
+		"posts/embedded.md": {Data: []byte("---\ntitle: \"Embedded\"\n---\n\nHi")},
+		"posts/notes.txt":   {Data: []byte("ignored")},
+	}, "posts")
+
+	entries, err := s.List()
+	if err != nil || len(entries) != 1 || entries[0].Slug != "embedded" {
+		t.Errorf("List returned %+v, %v", entries, err)
+	}
+
+	if _, err := s.Get("embedded"); err != nil {
+		t.Errorf("Get failed: %v", err)
+	}
+	if err := s.Put("embedded", nil); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly, got %v", err)
+	}
+}
+
+func TestOverlayStore(t *testing.T) {
+	base := NewFSStore(fstest.MapFS{
+		"posts/shared.md":   {Data: []byte("embedded shared")},
+		"posts/embedded.md": {Data: []byte("embedded only")},
+	}, "posts")
+	upper := NewMemoryStore()
+	upper.Put("shared", []byte("on disk"))
+	upper.Put("disk", []byte("disk only"))
+
+	s := NewOverlayStore(upper, base)
+
+	entries, err := s.List()
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("Expected 3 merged entries, got %+v, %v", entries, err)
+	}
+
+	doc, _ := s.Get("shared")
+	if string(doc.Content) != "on disk" {
+		t.Errorf("Expected the upper document to shadow the base one, got %q", doc.Content)
+	}
+
+	if err := s.Delete("embedded"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly deleting a base document, got %v", err)
+	}
+
+	// Deleting the shadowing document reveals the embedded one
+	if err := s.Delete("shared"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	doc, _ = s.Get("shared")
+	if string(doc.Content) != "embedded shared" {
+		t.Errorf("Expected the base document after delete, got %q", doc.Content)
+	}
+
+	if err := s.Delete("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestFSStore_BundlesAndAssets(t *testing.T) {
+	s := NewFSStore(fstest.MapFS{
+		"posts/flat.md":             {Data: []byte("flat")},
+		"posts/bundle/index.md":     {Data: []byte("bundle")},
+		"posts/bundle/photo.jpg":    {Data: []byte("jpeg")},
+		"posts/bundle/.secret":      {Data: []byte("hidden")},
+		"posts/assets/logo.png":     {Data: []byte("png")},
+		"posts/assets/index.md":     {Data: []byte("not a post")},
+		"posts/other/index.md":      {Data: []byte("other")},
+		"posts/no-index/photo.jpg":  {Data: []byte("orphan")},
+		"posts/both.md":             {Data: []byte("flat both")},
+		"posts/both/index.md":       {Data: []byte("bundle both")},
+		"posts/bundle/img/a/b.webp": {Data: []byte("webp")},
+	}, "posts")
+
+	entries, err := s.List()
+	if err != nil || len(entries) != 4 {
+		t.Fatalf("Expected flat, bundle, both and other, got %+v, %v", entries, err)
+	}
+
+	if doc, _ := s.Get("both"); string(doc.Content) != "bundle both" {
+		t.Errorf("Expected the bundle to take the place of the flat file, got %q", doc.Content)
+	}
+
+	tests := []struct {
+		slug, name, content string
+	}{
+		{"bundle", "photo.jpg", "jpeg"},
+		{"bundle", "img/a/b.webp", "webp"},
+		{"bundle", "logo.png", "png"},
+		{"flat", "logo.png", "png"},
+		{"other", "index.md", "not a post"},
+		{"bundle", ".secret", ""},
+		{"bundle", "../flat.md", ""},
+		{"bundle", "/etc/passwd", ""},
+		{"../posts", "flat.md", ""},
+	}
+	for _, tt := range tests {
+		asset, err := s.Asset(tt.slug, tt.name)
+		if tt.content == "" {
+			if !errors.Is(err, ErrNotFound) {
+				t.Errorf("Asset(%q, %q): expected ErrNotFound, got %q, %v", tt.slug, tt.name, asset.Content, err)
+			}
+			continue
+		}
+		if err != nil || string(asset.Content) != tt.content {
+			t.Errorf("Asset(%q, %q) = %q, %v; want %q", tt.slug, tt.name, asset.Content, err, tt.content)
+		}
+	}
+}
+
+func TestFileStore_BundleVersionCoversAssets(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "bundle"), 0755)
+	os.WriteFile(filepath.Join(dir, "bundle", "index.md"), []byte("bundle"), 0644)
+	os.WriteFile(filepath.Join(dir, "bundle", "photo.jpg"), []byte("one"), 0644)
+	s := NewFileStore(dir)
+
+	first, err := s.Get("bundle")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "bundle", "photo.jpg"), []byte("two, and longer"), 0644)
+	second, _ := s.Get("bundle")
+	if second.Version == first.Version {
+		t.Error("Version should change when an asset in the bundle changes")
+	}
+
+	// Writes to a bundle go to its index.md
+	if err := s.Put("bundle", []byte("updated")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "bundle", "index.md")); string(content) != "updated" {
+		t.Errorf("Expected index.md to be updated, got %q", content)
+	}
+
+	if asset, err := s.Asset("bundle", "photo.jpg"); err != nil || string(asset.Content) != "two, and longer" {
+		t.Errorf("Asset returned %q, %v", asset.Content, err)
+	}
+}
+
+func TestFileStore_DeleteBundle(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "bundle"), 0755)
+	os.WriteFile(filepath.Join(dir, "bundle", "index.md"), []byte("bundle"), 0644)
+	os.WriteFile(filepath.Join(dir, "bundle", "notes.md"), []byte("notes"), 0644)
+	os.WriteFile(filepath.Join(dir, "bundle", "photo.jpg"), []byte("photo"), 0644)
+	s := NewFileStore(dir)
+
+	if err := s.Delete("bundle"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "bundle")); !os.IsNotExist(err) {
+		t.Errorf("Expected the bundle directory to be gone, got %v", err)
+	}
+	// The bundle's other markdown must not turn into a post
+	if entries, err := s.List(); err != nil || len(entries) != 0 {
+		t.Errorf("Expected no posts left, got %+v, %v", entries, err)
+	}
+	if err := s.Delete("bundle"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting twice, got %v", err)
+	}
+}
+
+func TestFileStore_Rename(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "travel", "trip"), 0755)
+	os.WriteFile(filepath.Join(dir, "travel", "trip", "index.md"), []byte("trip"), 0644)
+	os.WriteFile(filepath.Join(dir, "travel", "trip", "photo.jpg"), []byte("photo"), 0644)
+	os.MkdirAll(filepath.Join(dir, "flat"), 0755)
+	os.WriteFile(filepath.Join(dir, "flat.md"), []byte("flat"), 0644)
+	os.WriteFile(filepath.Join(dir, "flat", "chart.png"), []byte("chart"), 0644)
+	os.WriteFile(filepath.Join(dir, "taken.md"), []byte("taken"), 0644)
+	s := NewFileStore(dir)
+
+	// A bundle moves as a whole, staying in its section
+	if err := s.Rename("trip", "voyage", []byte("voyage")); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "travel", "trip")); !os.IsNotExist(err) {
+		t.Errorf("Expected the old bundle directory to be gone, got %v", err)
+	}
+	doc, err := s.Get("voyage")
+	if err != nil || string(doc.Content) != "voyage" || doc.Section != "travel" {
+		t.Errorf("Get returned %+v, %v", doc, err)
+	}
+	if asset, err := s.Asset("voyage", "photo.jpg"); err != nil || string(asset.Content) != "photo" {
+		t.Errorf("Expected the asset to move with the bundle, got %q, %v", asset.Content, err)
+	}
+	if _, err := s.Get("trip"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for the old slug, got %v", err)
+	}
+
+	// A flat file takes its directory of assets along
+	if err := s.Rename("flat", "level", []byte("level")); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if asset, err := s.Asset("level", "chart.png"); err != nil || string(asset.Content) != "chart" {
+		t.Errorf("Expected the asset to move with the post, got %q, %v", asset.Content, err)
+	}
+	for _, name := range []string{"flat.md", "flat"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be gone, got %v", name, err)
+		}
+	}
+
+	if err := s.Rename("level", "taken", []byte("clash")); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("Expected fs.ErrExist renaming onto a post, got %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "taken.md")); string(content) != "taken" {
+		t.Errorf("A refused rename should not touch the other post, got %q", content)
+	}
+	if err := s.Rename("missing", "other", nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,460 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"blog-api/metrics"
+	"blog-api/models"
+	"blog-api/persist"
+)
+
+// Delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// ErrDeliveryNotFound is returned when no delivery exists for an ID
+var ErrDeliveryNotFound = errors.New("delivery not found")
+
+// Subscription is an endpoint that receives post events
+type Subscription struct {
+	ID     string   `json:"id"`
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+}
+
+// wants reports whether the subscription receives eventType. A subscription
+// without events receives everything.
+func (s Subscription) wants(eventType string) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, e := range s.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Payload is the JSON body sent to subscribers
+type Payload struct {
+	ID        string              `json:"id"`
+	Event     string              `json:"event"`
+	CreatedAt string              `json:"created_at"`
+	Post      models.BlogPostMeta `json:"post"`
+}
+
+// Attempt records a single delivery attempt
+type Attempt struct {
+	At         string `json:"at"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Delivery is a payload sent, or being sent, to one subscription
+type Delivery struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscription_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Status         string          `json:"status"`
+	Attempts       []Attempt       `json:"attempts"`
+	NextAttemptAt  string          `json:"next_attempt_at,omitempty"`
+	RedeliveryOf   string          `json:"redelivery_of,omitempty"`
+	CreatedAt      string          `json:"created_at"`
+}
+
+// DispatcherConfig configures a Dispatcher
+type DispatcherConfig struct {
+	Subscriptions []Subscription
+	// LogFile persists the delivery log; "" keeps it in memory only
+	LogFile string
+	// MaxAttempts is how many times a delivery is tried before failing
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry; it doubles after
+	// each failed attempt
+	InitialBackoff time.Duration
+	// Timeout bounds each attempt
+	Timeout time.Duration
+	// MaxLog bounds how many deliveries are kept in the log
+	MaxLog int
+}
+
+// Dispatcher sends signed post events to subscribers, retrying failed
+// deliveries with exponential backoff
+type Dispatcher struct {
+	cfg      DispatcherConfig
+	client   *http.Client
+	registry *metrics.Registry
+	queue    chan string
+
+	mu         sync.Mutex
+	deliveries map[string]*Delivery
+	order      []string
+	active     map[string]bool
+}
+
+// NewDispatcher creates a Dispatcher and loads any persisted delivery log
+func NewDispatcher(cfg DispatcherConfig, registry *metrics.Registry) (*Dispatcher, error) {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = 10 * time.Second
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.MaxLog <= 0 {
+		cfg.MaxLog = 1000
+	}
+
+	for _, sub := range cfg.Subscriptions {
+		if sub.ID == "" || sub.URL == "" {
+			return nil, errors.New("webhook subscriptions need an id and a url")
+		}
+	}
+
+	d := &Dispatcher{
+		cfg:        cfg,
+		client:     &http.Client{Timeout: cfg.Timeout},
+		registry:   registry,
+		queue:      make(chan string, 256),
+		deliveries: make(map[string]*Delivery),
+		active:     make(map[string]bool),
+	}
+
+	if cfg.LogFile != "" {
+		var log []*Delivery
+		if err := persist.LoadJSON(cfg.LogFile, &log); err != nil {
+			return nil, fmt.Errorf("loading webhook delivery log: %w", err)
+		}
+		for _, delivery := range log {
+			d.deliveries[delivery.ID] = delivery
+			d.order = append(d.order, delivery.ID)
+		}
+	}
+
+	registry.Describe("blog_api_webhook_deliveries_total", "Outgoing webhook deliveries by final status")
+
+	return d, nil
+}
+
+// LoadSubscriptions reads a JSON array of subscriptions from path
+func LoadSubscriptions(path string) ([]Subscription, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var subs []Subscription
+	if err := json.Unmarshal(data, &subs); err != nil {
+		return nil, fmt.Errorf("parsing webhooks file: %w", err)
+	}
+	return subs, nil
+}
+
+// Run delivers queued events until ctx is cancelled. Deliveries left
+// pending by a previous run are resumed.
+func (d *Dispatcher) Run(ctx context.Context) {
+	d.mu.Lock()
+	var pending []string
+	for _, id := range d.order {
+		if d.deliveries[id].Status == DeliveryPending {
+			pending = append(pending, id)
+		}
+	}
+	d.mu.Unlock()
+
+	for _, id := range pending {
+		go d.deliver(ctx, id)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-d.queue:
+			go d.deliver(ctx, id)
+		}
+	}
+}
+
+// HandlePostEvent queues a delivery of event to every interested
+// subscription
+func (d *Dispatcher) HandlePostEvent(event models.PostEvent) {
+	for _, sub := range d.cfg.Subscriptions {
+		if !sub.wants(event.Type) {
+			continue
+		}
+
+		payload, err := json.Marshal(Payload{
+			ID:        persist.NewID(),
+			Event:     event.Type,
+			CreatedAt: time.Now().UTC().Format(time.RFC3339),
+			Post:      event.Post,
+		})
+		if err != nil {
+			fmt.Printf("Error encoding webhook payload: %v\n", err)
+			continue
+		}
+
+		d.enqueue(&Delivery{
+			ID:             persist.NewID(),
+			SubscriptionID: sub.ID,
+			Event:          event.Type,
+			Payload:        payload,
+		})
+	}
+}
+
+// Redeliver sends the payload of an earlier delivery again as a new
+// delivery and returns it
+func (d *Dispatcher) Redeliver(id string) (Delivery, error) {
+	d.mu.Lock()
+	original, ok := d.deliveries[id]
+	if !ok {
+		d.mu.Unlock()
+		return Delivery{}, ErrDeliveryNotFound
+	}
+	delivery := &Delivery{
+		ID:             persist.NewID(),
+		SubscriptionID: original.SubscriptionID,
+		Event:          original.Event,
+		Payload:        original.Payload,
+		RedeliveryOf:   original.ID,
+	}
+	d.mu.Unlock()
+
+	return d.enqueue(delivery), nil
+}
+
+// GetDelivery returns a delivery by ID
+func (d *Dispatcher) GetDelivery(id string) (Delivery, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delivery, ok := d.deliveries[id]
+	if !ok {
+		return Delivery{}, false
+	}
+	return copyDelivery(delivery), true
+}
+
+// ListDeliveries returns the delivery log, newest first
+func (d *Dispatcher) ListDeliveries() []Delivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	deliveries := make([]Delivery, 0, len(d.order))
+	for i := len(d.order) - 1; i >= 0; i-- {
+		deliveries = append(deliveries, copyDelivery(d.deliveries[d.order[i]]))
+	}
+	return deliveries
+}
+
+// enqueue records a new delivery and queues it for sending
+func (d *Dispatcher) enqueue(delivery *Delivery) Delivery {
+	delivery.Status = DeliveryPending
+	delivery.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	d.mu.Lock()
+	d.deliveries[delivery.ID] = delivery
+	d.order = append(d.order, delivery.ID)
+	d.prune()
+	d.save()
+	result := copyDelivery(delivery)
+	d.mu.Unlock()
+
+	select {
+	case d.queue <- delivery.ID:
+	default:
+		// Run picks up pending deliveries when it next starts
+		fmt.Printf("Webhook queue full; delivery %s deferred\n", delivery.ID)
+	}
+
+	return result
+}
+
+// deliver attempts a delivery until it succeeds, runs out of attempts or
+// ctx is cancelled
+func (d *Dispatcher) deliver(ctx context.Context, id string) {
+	d.mu.Lock()
+	delivery, ok := d.deliveries[id]
+	if !ok || d.active[id] || delivery.Status != DeliveryPending {
+		d.mu.Unlock()
+		return
+	}
+	d.active[id] = true
+	sub, found := d.subscription(delivery.SubscriptionID)
+	payload := delivery.Payload
+	attempts := len(delivery.Attempts)
+	d.mu.Unlock()
+
+	defer func() {
+		d.mu.Lock()
+		delete(d.active, id)
+		d.mu.Unlock()
+	}()
+
+	if !found {
+		d.finish(id, DeliveryFailed, Attempt{At: time.Now().UTC().Format(time.RFC3339), Error: "subscription no longer configured"})
+		return
+	}
+
+	for attempts < d.cfg.MaxAttempts {
+		if attempts > 0 {
+			backoff := d.cfg.InitialBackoff << (attempts - 1)
+			d.mu.Lock()
+			delivery.NextAttemptAt = time.Now().Add(backoff).UTC().Format(time.RFC3339)
+			d.save()
+			d.mu.Unlock()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+		}
+
+		attempt := d.send(ctx, sub, delivery.Event, id, payload)
+		attempts++
+
+		if attempt.Error == "" {
+			d.finish(id, DeliverySucceeded, attempt)
+			return
+		}
+		if attempts >= d.cfg.MaxAttempts {
+			d.finish(id, DeliveryFailed, attempt)
+			return
+		}
+
+		d.mu.Lock()
+		delivery.Attempts = append(delivery.Attempts, attempt)
+		d.mu.Unlock()
+	}
+}
+
+// send makes a single signed POST to the subscription
+func (d *Dispatcher) send(ctx context.Context, sub Subscription, event, id string, payload []byte) Attempt {
+	start := time.Now()
+	attempt := Attempt{At: start.UTC().Format(time.RFC3339)}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", sub.URL, bytes.NewReader(payload))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+
+	secret := []byte(sub.Secret)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "blog-api-webhooks/1.0")
+	req.Header.Set("X-Blog-Event", event)
+	req.Header.Set("X-Blog-Delivery", id)
+	req.Header.Set("X-Hub-Signature-256", Sign(secret, payload))
+	req.Header.Set("X-Signature-Timestamp", strconv.FormatInt(start.Unix(), 10))
+	req.Header.Set("X-Signature-256", SignWithTimestamp(secret, payload, start))
+
+	resp, err := d.client.Do(req)
+	attempt.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	attempt.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		attempt.Error = "unexpected status " + resp.Status
+	}
+
+	return attempt
+}
+
+// finish records the final attempt and status of a delivery
+func (d *Dispatcher) finish(id, status string, attempt Attempt) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delivery, ok := d.deliveries[id]
+	if !ok {
+		return
+	}
+	delivery.Attempts = append(delivery.Attempts, attempt)
+	delivery.Status = status
+	delivery.NextAttemptAt = ""
+	d.save()
+
+	d.registry.Inc("blog_api_webhook_deliveries_total", "subscription", delivery.SubscriptionID, "status", status)
+}
+
+// subscription returns a configured subscription by ID
+func (d *Dispatcher) subscription(id string) (Subscription, bool) {
+	for _, sub := range d.cfg.Subscriptions {
+		if sub.ID == id {
+			return sub, true
+		}
+	}
+	return Subscription{}, false
+}
+
+// prune drops the oldest finished deliveries beyond MaxLog. The caller must
+// hold d.mu.
+func (d *Dispatcher) prune() {
+	if len(d.order) <= d.cfg.MaxLog {
+		return
+	}
+
+	var kept []string
+	excess := len(d.order) - d.cfg.MaxLog
+	for _, id := range d.order {
+		if excess > 0 && d.deliveries[id].Status != DeliveryPending {
+			delete(d.deliveries, id)
+			excess--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	d.order = kept
+}
+
+// save persists the delivery log. The caller must hold d.mu.
+func (d *Dispatcher) save() {
+	if d.cfg.LogFile == "" {
+		return
+	}
+
+	log := make([]*Delivery, 0, len(d.order))
+	for _, id := range d.order {
+		log = append(log, d.deliveries[id])
+	}
+	sort.SliceStable(log, func(i, j int) bool { return log[i].CreatedAt < log[j].CreatedAt })
+
+	if err := persist.SaveJSON(d.cfg.LogFile, log); err != nil {
+		fmt.Printf("Error saving webhook delivery log: %v\n", err)
+	}
+}
+
+// copyDelivery returns a copy of delivery safe to use without d.mu
+func copyDelivery(delivery *Delivery) Delivery {
+	c := *delivery
+	c.Attempts = append([]Attempt(nil), delivery.Attempts...)
+	return c
+}
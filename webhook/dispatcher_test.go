@@ -0,0 +1,145 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"blog-api/metrics"
+	"blog-api/models"
+)
+
+// waitFor polls until the delivery reaches a final status
+func waitFor(t *testing.T, d *Dispatcher, id string) Delivery {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if delivery, ok := d.GetDelivery(id); ok && delivery.Status != DeliveryPending {
+			return delivery
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Delivery %s did not finish", id)
+	return Delivery{}
+}
+
+func TestDispatcher_DeliversSignedEvents(t *testing.T) {
+	var mu sync.Mutex
+	var received []Payload
+	failures := 1
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := Verify([]byte("secret"), body, r.Header.Get("X-Hub-Signature-256")); err != nil {
+			t.Errorf("Invalid signature: %v", err)
+		}
+		if r.Header.Get("X-Blog-Event") != models.PostPublished {
+			t.Errorf("Unexpected event header %q", r.Header.Get("X-Blog-Event"))
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		var payload Payload
+		json.Unmarshal(body, &payload)
+		received = append(received, payload)
+	}))
+	defer receiver.Close()
+
+	logFile := filepath.Join(t.TempDir(), "deliveries.json")
+	d, err := NewDispatcher(DispatcherConfig{
+		Subscriptions: []Subscription{
+			{ID: "site", URL: receiver.URL, Secret: "secret", Events: []string{models.PostPublished}},
+		},
+		LogFile:        logFile,
+		InitialBackoff: 10 * time.Millisecond,
+	}, metrics.NewRegistry())
+	if err != nil {
+		t.Fatalf("NewDispatcher failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+
+	d.HandlePostEvent(models.PostEvent{Type: models.PostDeleted, Post: models.BlogPostMeta{Slug: "old"}})
+	d.HandlePostEvent(models.PostEvent{Type: models.PostPublished, Post: models.BlogPostMeta{Slug: "hello", Title: "Hello"}})
+
+	deliveries := d.ListDeliveries()
+	if len(deliveries) != 1 {
+		t.Fatalf("Expected 1 delivery for the subscribed event, got %d", len(deliveries))
+	}
+
+	delivery := waitFor(t, d, deliveries[0].ID)
+	if delivery.Status != DeliverySucceeded || len(delivery.Attempts) != 2 {
+		t.Errorf("Expected success on the second attempt, got %s after %d attempts", delivery.Status, len(delivery.Attempts))
+	}
+
+	redelivery, err := d.Redeliver(delivery.ID)
+	if err != nil {
+		t.Fatalf("Redeliver failed: %v", err)
+	}
+	if redelivery.RedeliveryOf != delivery.ID {
+		t.Errorf("Expected redelivery of %s, got %q", delivery.ID, redelivery.RedeliveryOf)
+	}
+	waitFor(t, d, redelivery.ID)
+
+	mu.Lock()
+	if len(received) != 2 || received[0].Post.Slug != "hello" || received[0].ID != received[1].ID {
+		t.Errorf("Unexpected payloads received: %+v", received)
+	}
+	mu.Unlock()
+
+	if _, err := d.Redeliver("missing"); err != ErrDeliveryNotFound {
+		t.Errorf("Expected ErrDeliveryNotFound, got %v", err)
+	}
+
+	// The log survives a restart
+	reloaded, err := NewDispatcher(DispatcherConfig{LogFile: logFile}, metrics.NewRegistry())
+	if err != nil {
+		t.Fatalf("NewDispatcher failed: %v", err)
+	}
+	if len(reloaded.ListDeliveries()) != 2 {
+		t.Errorf("Expected 2 persisted deliveries, got %d", len(reloaded.ListDeliveries()))
+	}
+}
+
+func TestDispatcher_GivesUpAfterMaxAttempts(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer receiver.Close()
+
+	d, err := NewDispatcher(DispatcherConfig{
+		Subscriptions:  []Subscription{{ID: "site", URL: receiver.URL, Secret: "secret"}},
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+	}, metrics.NewRegistry())
+	if err != nil {
+		t.Fatalf("NewDispatcher failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+
+	d.HandlePostEvent(models.PostEvent{Type: models.PostUpdated, Post: models.BlogPostMeta{Slug: "hello"}})
+
+	delivery := waitFor(t, d, d.ListDeliveries()[0].ID)
+	if delivery.Status != DeliveryFailed || len(delivery.Attempts) != 3 {
+		t.Errorf("Expected failure after 3 attempts, got %s after %d", delivery.Status, len(delivery.Attempts))
+	}
+	if delivery.Attempts[2].StatusCode != http.StatusBadGateway {
+		t.Errorf("Expected the status code to be recorded, got %d", delivery.Attempts[2].StatusCode)
+	}
+}
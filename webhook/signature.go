@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const signaturePrefix = "sha256="
+
+var (
+	// ErrMissingSignature is returned when a request carries no signature
+	ErrMissingSignature = errors.New("missing signature")
+
+	// ErrInvalidSignature is returned when a signature does not match
+	ErrInvalidSignature = errors.New("invalid signature")
+
+	// ErrStaleTimestamp is returned when a signed timestamp is outside the
+	// allowed window
+	ErrStaleTimestamp = errors.New("signature timestamp outside allowed window")
+)
+
+// Sign returns the "sha256=<hex>" HMAC of payload, as sent in
+// X-Hub-Signature-256
+func Sign(secret, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignWithTimestamp signs "<timestamp>.<payload>", binding the signature to
+// the time it was made so it cannot be replayed later
+func SignWithTimestamp(secret, payload []byte, timestamp time.Time) string {
+	return Sign(secret, timestampedPayload(strconv.FormatInt(timestamp.Unix(), 10), payload))
+}
+
+// Verify checks a GitHub-style X-Hub-Signature-256 value against payload
+func Verify(secret, payload []byte, signature string) error {
+	if signature == "" {
+		return ErrMissingSignature
+	}
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(Sign(secret, payload)), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// VerifyWithTimestamp checks a signature made by SignWithTimestamp. The
+// timestamp, in Unix seconds, must be within tolerance of now.
+func VerifyWithTimestamp(secret, payload []byte, signature, timestamp string, tolerance time.Duration, now time.Time) error {
+	if signature == "" || timestamp == "" {
+		return ErrMissingSignature
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	skew := now.Sub(time.Unix(seconds, 0))
+	if skew > tolerance || skew < -tolerance {
+		return ErrStaleTimestamp
+	}
+
+	return Verify(secret, timestampedPayload(timestamp, payload), signature)
+}
+
+// timestampedPayload returns the bytes signed for a timestamped signature
+func timestampedPayload(timestamp string, payload []byte) []byte {
+	return append([]byte(timestamp+"."), payload...)
+}
@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	secret := []byte("secret")
+	payload := []byte(`{"ref":"refs/heads/main"}`)
+
+	signature := Sign(secret, payload)
+	if err := Verify(secret, payload, signature); err != nil {
+		t.Errorf("Expected a valid signature, got %v", err)
+	}
+
+	if err := Verify(secret, []byte("tampered"), signature); err != ErrInvalidSignature {
+		t.Errorf("Expected ErrInvalidSignature for a tampered payload, got %v", err)
+	}
+	if err := Verify([]byte("other"), payload, signature); err != ErrInvalidSignature {
+		t.Errorf("Expected ErrInvalidSignature for the wrong secret, got %v", err)
+	}
+	if err := Verify(secret, payload, ""); err != ErrMissingSignature {
+		t.Errorf("Expected ErrMissingSignature, got %v", err)
+	}
+}
+
+func TestVerifyWithTimestamp(t *testing.T) {
+	secret := []byte("secret")
+	payload := []byte("body")
+	now := time.Unix(1700000000, 0)
+
+	signature := SignWithTimestamp(secret, payload, now)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	if err := VerifyWithTimestamp(secret, payload, signature, timestamp, 5*time.Minute, now.Add(time.Minute)); err != nil {
+		t.Errorf("Expected a valid signature, got %v", err)
+	}
+	if err := VerifyWithTimestamp(secret, payload, signature, timestamp, 5*time.Minute, now.Add(time.Hour)); err != ErrStaleTimestamp {
+		t.Errorf("Expected ErrStaleTimestamp, got %v", err)
+	}
+
+	// The signature is bound to its timestamp
+	later := strconv.FormatInt(now.Unix()+1, 10)
+	if err := VerifyWithTimestamp(secret, payload, signature, later, 5*time.Minute, now); err != ErrInvalidSignature {
+		t.Errorf("Expected ErrInvalidSignature for a changed timestamp, got %v", err)
+	}
+}
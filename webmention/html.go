@@ -0,0 +1,135 @@
+package webmention
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// userAgent identifies requests made for Webmention
+const userAgent = "blog-api Webmention (+https://www.w3.org/TR/webmention/)"
+
+// page is what is read from an HTML page: its title, the URLs it links
+// to and the Webmention endpoint it advertises
+type page struct {
+	title    string
+	links    []string
+	endpoint string
+}
+
+// parsePage reads an HTML page found at base. Relative URLs are resolved
+// against base.
+func parsePage(body []byte, base *url.URL) page {
+	var p page
+	tokenizer := html.NewTokenizer(bytes.NewReader(body))
+	inTitle := false
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return p
+		case html.TextToken:
+			if inTitle {
+				p.title += string(tokenizer.Text())
+			}
+		case html.EndTagToken:
+			if name, _ := tokenizer.TagName(); string(name) == "title" {
+				inTitle = false
+				p.title = strings.TrimSpace(p.title)
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "title":
+				inTitle = p.title == ""
+			case "a", "link":
+				href, hasHref := attr(token, "href")
+				if !hasHref {
+					continue
+				}
+				// An empty href is allowed, and resolves to the page itself
+				if p.endpoint == "" && hasRel(attrValue(token, "rel"), "webmention") {
+					p.endpoint = resolve(base, href)
+				}
+				if token.Data == "a" {
+					p.links = append(p.links, resolve(base, href))
+				}
+			case "img", "audio", "video", "source":
+				if src, ok := attr(token, "src"); ok {
+					p.links = append(p.links, resolve(base, src))
+				}
+			}
+		}
+	}
+}
+
+// attr returns the value of a token's attribute and whether it has it
+func attr(token html.Token, name string) (string, bool) {
+	for _, a := range token.Attr {
+		if a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// attrValue returns the value of a token's attribute, or ""
+func attrValue(token html.Token, name string) string {
+	value, _ := attr(token, name)
+	return value
+}
+
+// hasRel reports whether a space separated rel value includes rel
+func hasRel(value, rel string) bool {
+	for _, r := range strings.Fields(value) {
+		if strings.EqualFold(r, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// linkHeaderEndpoint returns the first Webmention endpoint in a response's
+// Link headers, if any
+func linkHeaderEndpoint(header http.Header, base *url.URL) (string, bool) {
+	for _, value := range header.Values("Link") {
+		for _, link := range strings.Split(value, ",") {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range parts[1:] {
+				key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if ok && strings.EqualFold(strings.TrimSpace(key), "rel") && hasRel(strings.Trim(value, `"`), "webmention") {
+					return resolve(base, strings.Trim(target, "<>")), true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// resolve resolves ref against base, returning ref unchanged if it is not
+// a valid URL
+func resolve(base *url.URL, ref string) string {
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return ref
+	}
+	return base.ResolveReference(u).String()
+}
+
+// sameURL reports whether a and b are the same page, ignoring fragments
+// and a trailing slash
+func sameURL(a, b string) bool {
+	return normalizeURL(a) == normalizeURL(b)
+}
+
+// normalizeURL drops the fragment and trailing slash of a URL
+func normalizeURL(raw string) string {
+	raw, _, _ = strings.Cut(raw, "#")
+	return strings.TrimSuffix(raw, "/")
+}
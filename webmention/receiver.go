@@ -0,0 +1,385 @@
+// Package webmention receives and sends Webmentions
+// (https://www.w3.org/TR/webmention/), the notifications IndieWeb sites
+// send each other when one links to another
+package webmention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"blog-api/metrics"
+	"blog-api/netutil"
+	"blog-api/persist"
+)
+
+// Mention statuses
+const (
+	StatusPending  = "pending"
+	StatusVerified = "verified"
+	StatusRejected = "rejected"
+)
+
+// Webmention request errors
+var (
+	ErrInvalidSource = errors.New("source must be an absolute http or https URL")
+	ErrInvalidTarget = errors.New("target must be an absolute http or https URL")
+	ErrSameURL       = errors.New("source and target must be different")
+	ErrBusy          = errors.New("too many Webmentions are waiting to be verified; try again later")
+)
+
+// Mention is a Webmention received for a post: a page at Source that says
+// it links to Target
+type Mention struct {
+	ID     string `json:"id" example:"3f2a9c1d5e7b8a60"`
+	Source string `json:"source" example:"https://jane.example/replies/1"`
+	Target string `json:"target" example:"https://blog-api.murray.kiwi/posts/hello-world"`
+	Post   string `json:"post" example:"hello-world"`
+	Status string `json:"status" example:"verified"`
+	// Title is the title of the source page, once verified
+	Title      string `json:"title,omitempty" example:"Re: Hello World"`
+	Error      string `json:"error,omitempty" example:""`
+	CreatedAt  string `json:"created_at" example:"2025-06-01T10:00:00Z"`
+	VerifiedAt string `json:"verified_at,omitempty" example:"2025-06-01T10:00:02Z"`
+	// CheckedAt is when the source was last fetched, verified or not
+	CheckedAt string `json:"checked_at,omitempty" example:"2025-06-01T10:00:02Z"`
+}
+
+// MentionList is the verified mentions of a post
+type MentionList struct {
+	Mentions []Mention `json:"mentions"`
+	Count    int       `json:"count" example:"2"`
+}
+
+// ReceiverConfig configures a Receiver
+type ReceiverConfig struct {
+	// File persists mentions; "" keeps them in memory only
+	File string
+	// Timeout bounds each fetch of a source
+	Timeout time.Duration
+	// MaxBodySize bounds how much of a source is read
+	MaxBodySize int64
+	// MaxPending caps the mentions waiting to be verified; more are
+	// refused with ErrBusy
+	MaxPending int
+	// RejectedTTL is how long rejected mentions are kept, so senders can
+	// see why, before they are dropped
+	RejectedTTL time.Duration
+	// AllowPrivate lets sources be fetched from loopback and private
+	// addresses, which are refused by default so senders cannot use the
+	// receiver to reach internal services
+	AllowPrivate bool
+}
+
+// Receiver accepts Webmentions and verifies them in the background by
+// fetching each source and checking that it links to its target
+type Receiver struct {
+	cfg      ReceiverConfig
+	client   *http.Client
+	registry *metrics.Registry
+	now      func() time.Time
+	queue    chan string
+
+	mu       sync.Mutex
+	mentions map[string]*Mention
+	// bySource maps a source and target pair to its mention
+	bySource map[pair]string
+	pending  int
+}
+
+// pair is a source and the target it mentions
+type pair struct {
+	source, target string
+}
+
+// NewReceiver creates a Receiver and loads any persisted mentions
+func NewReceiver(cfg ReceiverConfig, registry *metrics.Registry) (*Receiver, error) {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = 1 << 20
+	}
+	if cfg.MaxPending <= 0 {
+		cfg.MaxPending = 64
+	}
+	if cfg.RejectedTTL <= 0 {
+		cfg.RejectedTTL = 24 * time.Hour
+	}
+
+	r := &Receiver{
+		cfg:      cfg,
+		client:   netutil.NewClient(cfg.Timeout, cfg.AllowPrivate),
+		registry: registry,
+		now:      time.Now,
+		mentions: make(map[string]*Mention),
+		bySource: make(map[pair]string),
+	}
+
+	var mentions []*Mention
+	if cfg.File != "" {
+		if err := persist.LoadJSON(cfg.File, &mentions); err != nil {
+			return nil, fmt.Errorf("loading webmentions: %w", err)
+		}
+	}
+	for _, mention := range mentions {
+		r.lockedAdd(mention)
+	}
+
+	// Every pending mention has one place in the queue, so it never fills.
+	// Those left pending by a previous run go first.
+	r.queue = make(chan string, max(cfg.MaxPending, r.pending))
+	for _, mention := range mentions {
+		if mention.Status == StatusPending {
+			r.queue <- mention.ID
+		}
+	}
+
+	registry.Describe("blog_api_webmentions_received_total", "Webmentions received, by the status verification gave them")
+
+	return r, nil
+}
+
+// Receive queues a mention of post, whose URL is target, by source for
+// verification. A source mentioning a target again, to say it was updated
+// or deleted, is verified again in place. While MaxPending mentions are
+// waiting, new ones are refused with ErrBusy. Pending mentions are saved
+// once they have been verified.
+func (r *Receiver) Receive(source, target, post string) (Mention, error) {
+	if !netutil.IsWebURL(source) {
+		return Mention{}, ErrInvalidSource
+	}
+	if !netutil.IsWebURL(target) {
+		return Mention{}, ErrInvalidTarget
+	}
+	if source == target {
+		return Mention{}, ErrSameURL
+	}
+
+	r.mu.Lock()
+	mention := r.mentions[r.bySource[pair{source, target}]]
+	if mention != nil && mention.Status == StatusPending {
+		// Already queued; it is verified once
+		mention.Post = post
+		received := *mention
+		r.mu.Unlock()
+		return received, nil
+	}
+	if r.pending >= r.cfg.MaxPending {
+		r.mu.Unlock()
+		return Mention{}, ErrBusy
+	}
+	if mention == nil {
+		mention = &Mention{
+			ID:        persist.NewID(),
+			Source:    source,
+			Target:    target,
+			CreatedAt: r.now().UTC().Format(time.RFC3339),
+		}
+		r.lockedAdd(mention)
+	}
+	mention.Post = post
+	mention.Status = StatusPending
+	mention.Error = ""
+	r.pending++
+	received := *mention
+	r.mu.Unlock()
+
+	r.queue <- received.ID
+
+	return received, nil
+}
+
+// Get returns a single mention
+func (r *Receiver) Get(id string) (Mention, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mention, ok := r.mentions[id]
+	if !ok {
+		return Mention{}, false
+	}
+	return *mention, true
+}
+
+// Mentions returns the verified mentions of a post, oldest first. A post
+// may be given several slugs, such as its aliases, to gather mentions of
+// its former URLs.
+func (r *Receiver) Mentions(posts ...string) MentionList {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	onPost := make(map[string]bool, len(posts))
+	for _, post := range posts {
+		onPost[post] = true
+	}
+
+	mentions := []Mention{}
+	for _, mention := range r.mentions {
+		if onPost[mention.Post] && mention.Status == StatusVerified {
+			mentions = append(mentions, *mention)
+		}
+	}
+	sort.Slice(mentions, func(i, j int) bool {
+		if mentions[i].CreatedAt != mentions[j].CreatedAt {
+			return mentions[i].CreatedAt < mentions[j].CreatedAt
+		}
+		return mentions[i].ID < mentions[j].ID
+	})
+
+	return MentionList{Mentions: mentions, Count: len(mentions)}
+}
+
+// Run verifies queued mentions until ctx is done, starting with any left
+// pending by a previous run. Rejected mentions past RejectedTTL are dropped
+// as it goes.
+func (r *Receiver) Run(ctx context.Context) {
+	r.prune()
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-r.queue:
+			r.verify(ctx, id)
+		case <-ticker.C:
+			r.prune()
+		}
+	}
+}
+
+// prune drops rejected mentions last checked longer than RejectedTTL ago
+func (r *Receiver) prune() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cutoff := r.now().Add(-r.cfg.RejectedTTL).UTC().Format(time.RFC3339)
+	pruned := false
+	for id, mention := range r.mentions {
+		if mention.Status == StatusRejected && mention.CheckedAt < cutoff {
+			delete(r.mentions, id)
+			delete(r.bySource, pair{mention.Source, mention.Target})
+			pruned = true
+		}
+	}
+	if pruned {
+		r.save()
+	}
+}
+
+// verify fetches a mention's source and records whether it links to the
+// target. A source that has gone, or no longer links, rejects the mention,
+// which removes it from the post's mentions.
+func (r *Receiver) verify(ctx context.Context, id string) {
+	mention, ok := r.Get(id)
+	if !ok || mention.Status != StatusPending {
+		return
+	}
+
+	title, err := r.fetch(ctx, mention.Source, mention.Target)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.mentions[id]
+	if !ok || current.Status != StatusPending {
+		return
+	}
+	r.pending--
+	current.CheckedAt = r.now().UTC().Format(time.RFC3339)
+	if err != nil {
+		current.Status = StatusRejected
+		current.Error = err.Error()
+	} else {
+		current.Status = StatusVerified
+		current.Title = title
+		current.Error = ""
+		current.VerifiedAt = current.CheckedAt
+	}
+	r.registry.Inc("blog_api_webmentions_received_total", "status", current.Status)
+	r.save()
+}
+
+// fetch reads source and checks that it links to target, returning the
+// source's title
+func (r *Receiver) fetch(ctx context.Context, source, target string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "text/html, text/plain;q=0.9, */*;q=0.1")
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetching source: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusGone {
+		return "", errors.New("source has been deleted")
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("fetching source: status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, r.cfg.MaxBodySize))
+	if err != nil {
+		return "", fmt.Errorf("reading source: %w", err)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "text/html" || mediaType == "application/xhtml+xml" {
+		page := parsePage(body, resp.Request.URL)
+		for _, link := range page.links {
+			if sameURL(link, target) {
+				return page.title, nil
+			}
+		}
+	} else if strings.Contains(string(body), target) {
+		return "", nil
+	}
+	return "", errors.New("source does not link to target")
+}
+
+// lockedAdd stores a mention and indexes it by source and target. The
+// caller must hold r.mu.
+func (r *Receiver) lockedAdd(mention *Mention) {
+	r.mentions[mention.ID] = mention
+	r.bySource[pair{mention.Source, mention.Target}] = mention.ID
+	if mention.Status == StatusPending {
+		r.pending++
+	}
+}
+
+// save persists the mentions. The caller must hold r.mu.
+func (r *Receiver) save() {
+	if r.cfg.File == "" {
+		return
+	}
+
+	mentions := make([]*Mention, 0, len(r.mentions))
+	for _, mention := range r.mentions {
+		mentions = append(mentions, mention)
+	}
+	sort.Slice(mentions, func(i, j int) bool {
+		if mentions[i].CreatedAt != mentions[j].CreatedAt {
+			return mentions[i].CreatedAt < mentions[j].CreatedAt
+		}
+		return mentions[i].ID < mentions[j].ID
+	})
+
+	if err := persist.SaveJSON(r.cfg.File, mentions); err != nil {
+		fmt.Printf("Error saving webmentions: %v\n", err)
+	}
+}
@@ -0,0 +1,183 @@
+package webmention
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"blog-api/metrics"
+	"blog-api/netutil"
+)
+
+const target = "https://blog.example/posts/hello"
+
+// waitFor polls until the mention is no longer pending
+func waitFor(t *testing.T, r *Receiver, id string) Mention {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if mention, ok := r.Get(id); ok && mention.Status != StatusPending {
+			return mention
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Mention %s was not verified", id)
+	return Mention{}
+}
+
+func TestReceiver_VerifiesSources(t *testing.T) {
+	var mu sync.Mutex
+	pages := map[string]string{
+		"/reply":     `<html><head><title>Re: Hello</title></head><body><a href="` + target + `/">Hello</a></body></html>`,
+		"/unrelated": `<html><body><a href="https://elsewhere.example/">Nope</a></body></html>`,
+	}
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		page, ok := pages[req.URL.Path]
+		mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(page))
+	}))
+	defer source.Close()
+
+	file := filepath.Join(t.TempDir(), "webmentions.json")
+	r, err := NewReceiver(ReceiverConfig{File: file, AllowPrivate: true}, metrics.NewRegistry())
+	if err != nil {
+		t.Fatalf("NewReceiver failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Run(ctx)
+
+	reply, err := r.Receive(source.URL+"/reply", target, "hello")
+	if err != nil {
+		t.Fatalf("Receive failed: %v", err)
+	}
+	if reply.Status != StatusPending {
+		t.Errorf("Expected a pending mention, got %+v", reply)
+	}
+	if mention := waitFor(t, r, reply.ID); mention.Status != StatusVerified || mention.Title != "Re: Hello" {
+		t.Errorf("Expected a verified mention titled from its source, got %+v", mention)
+	}
+
+	unrelated, _ := r.Receive(source.URL+"/unrelated", target, "hello")
+	if mention := waitFor(t, r, unrelated.ID); mention.Status != StatusRejected || !strings.Contains(mention.Error, "does not link") {
+		t.Errorf("Expected a source without the link to be rejected, got %+v", mention)
+	}
+
+	if list := r.Mentions("hello"); list.Count != 1 || list.Mentions[0].ID != reply.ID {
+		t.Errorf("Expected only the verified mention, got %+v", list)
+	}
+
+	// Mentions persist across restarts
+	reloaded, err := NewReceiver(ReceiverConfig{File: file}, metrics.NewRegistry())
+	if err != nil {
+		t.Fatalf("NewReceiver failed: %v", err)
+	}
+	if list := reloaded.Mentions("old-hello", "hello"); list.Count != 1 {
+		t.Errorf("Expected the mention after reloading, got %+v", list)
+	}
+
+	// Sending again after the source is deleted removes the mention
+	mu.Lock()
+	delete(pages, "/reply")
+	mu.Unlock()
+	again, _ := r.Receive(source.URL+"/reply", target, "hello")
+	if again.ID != reply.ID {
+		t.Errorf("Expected the same mention to be verified again, got %s and %s", reply.ID, again.ID)
+	}
+	if mention := waitFor(t, r, again.ID); mention.Status != StatusRejected {
+		t.Errorf("Expected a deleted source to be rejected, got %+v", mention)
+	}
+	if list := r.Mentions("hello"); list.Count != 0 {
+		t.Errorf("Expected no mentions, got %+v", list)
+	}
+}
+
+func TestReceiver_RefusesPrivateSources(t *testing.T) {
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<a href="` + target + `">Hello</a>`))
+	}))
+	defer source.Close()
+
+	r, _ := NewReceiver(ReceiverConfig{}, metrics.NewRegistry())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Run(ctx)
+
+	mention, _ := r.Receive(source.URL, target, "hello")
+	if mention := waitFor(t, r, mention.ID); mention.Status != StatusRejected || !strings.Contains(mention.Error, netutil.ErrPrivateAddress.Error()) {
+		t.Errorf("Expected a loopback source to be refused, got %+v", mention)
+	}
+}
+
+func TestReceiver_Validation(t *testing.T) {
+	r, _ := NewReceiver(ReceiverConfig{}, metrics.NewRegistry())
+	tests := []struct {
+		source, target string
+		want           error
+	}{
+		{"ftp://example.com/a", target, ErrInvalidSource},
+		{"https://example.com/a", "/posts/hello", ErrInvalidTarget},
+		{target, target, ErrSameURL},
+	}
+	for _, tt := range tests {
+		if _, err := r.Receive(tt.source, tt.target, "hello"); !errors.Is(err, tt.want) {
+			t.Errorf("Receive(%q, %q): expected %v, got %v", tt.source, tt.target, tt.want, err)
+		}
+	}
+}
+
+func TestReceiver_LimitsPending(t *testing.T) {
+	r, _ := NewReceiver(ReceiverConfig{MaxPending: 1}, metrics.NewRegistry())
+
+	first, err := r.Receive("https://jane.example/1", target, "hello")
+	if err != nil {
+		t.Fatalf("Receive failed: %v", err)
+	}
+	if _, err := r.Receive("https://jane.example/2", target, "hello"); !errors.Is(err, ErrBusy) {
+		t.Errorf("Expected ErrBusy past MaxPending, got %v", err)
+	}
+	// A mention already waiting is not queued twice
+	again, err := r.Receive("https://jane.example/1", target, "hello")
+	if err != nil || again.ID != first.ID {
+		t.Errorf("Expected the pending mention back, got %+v, %v", again, err)
+	}
+}
+
+func TestReceiver_PrunesRejected(t *testing.T) {
+	r, _ := NewReceiver(ReceiverConfig{RejectedTTL: time.Hour}, metrics.NewRegistry())
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+
+	// Loopback sources are refused, so the mention is rejected
+	mention, _ := r.Receive("http://127.0.0.1/reply", target, "hello")
+	r.verify(context.Background(), <-r.queue)
+	if rejected, _ := r.Get(mention.ID); rejected.Status != StatusRejected {
+		t.Fatalf("Expected a rejected mention, got %+v", rejected)
+	}
+
+	r.prune()
+	if _, ok := r.Get(mention.ID); !ok {
+		t.Error("A recently rejected mention should be kept")
+	}
+
+	now = now.Add(2 * time.Hour)
+	r.prune()
+	if _, ok := r.Get(mention.ID); ok {
+		t.Error("Expected the rejected mention to be dropped after RejectedTTL")
+	}
+	if again, err := r.Receive("http://127.0.0.1/reply", target, "hello"); err != nil || again.ID == mention.ID {
+		t.Errorf("Expected a new mention once the old one was dropped, got %+v, %v", again, err)
+	}
+}
@@ -0,0 +1,227 @@
+package webmention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"blog-api/metrics"
+	"blog-api/models"
+	"blog-api/netutil"
+)
+
+// ErrNoEndpoint is returned when a page does not advertise a Webmention
+// endpoint
+var ErrNoEndpoint = errors.New("no webmention endpoint")
+
+// PostSource looks up the posts whose links are sent Webmentions
+type PostSource interface {
+	GetPostBySlug(slug string) (models.BlogPost, error)
+}
+
+// SenderConfig configures a Sender
+type SenderConfig struct {
+	// SiteURL is the address of this site; links within it are not sent
+	// Webmentions
+	SiteURL string
+	// Timeout bounds each request
+	Timeout time.Duration
+	// MaxBodySize bounds how much of a linked page is read looking for its
+	// endpoint
+	MaxBodySize int64
+	// AllowPrivate lets linked pages and endpoints on loopback and private
+	// addresses be contacted
+	AllowPrivate bool
+}
+
+// Sender notifies the pages newly published posts link to, through the
+// Webmention endpoints those pages advertise
+type Sender struct {
+	cfg      SenderConfig
+	posts    PostSource
+	client   *http.Client
+	registry *metrics.Registry
+	queue    chan string
+}
+
+// NewSender creates a Sender for the posts in posts
+func NewSender(cfg SenderConfig, posts PostSource, registry *metrics.Registry) *Sender {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = 1 << 20
+	}
+
+	registry.Describe("blog_api_webmentions_sent_total", "Webmentions sent for links in published posts, by result")
+
+	return &Sender{
+		cfg:      cfg,
+		posts:    posts,
+		client:   netutil.NewClient(cfg.Timeout, cfg.AllowPrivate),
+		registry: registry,
+		queue:    make(chan string, 256),
+	}
+}
+
+// HandlePostEvent queues the links of a newly published post to be sent
+// Webmentions
+func (s *Sender) HandlePostEvent(event models.PostEvent) {
+	if event.Type != models.PostPublished {
+		return
+	}
+
+	select {
+	case s.queue <- event.Post.Slug:
+	default:
+		fmt.Printf("Webmention queue full; not sending for %s\n", event.Post.Slug)
+	}
+}
+
+// Run sends Webmentions for queued posts until ctx is done
+func (s *Sender) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case slug := <-s.queue:
+			if err := s.SendPost(ctx, slug); err != nil {
+				fmt.Printf("Error sending webmentions for %s: %v\n", slug, err)
+			}
+		}
+	}
+}
+
+// SendPost sends a Webmention to every page outside the site that a post
+// links to. Pages without an endpoint are skipped; other failures are
+// reported but do not stop the rest being sent.
+func (s *Sender) SendPost(ctx context.Context, slug string) error {
+	post, err := s.posts.GetPostBySlug(slug)
+	if err != nil {
+		return err
+	}
+
+	source := post.CanonicalURL
+	if source == "" {
+		source = strings.TrimRight(s.cfg.SiteURL, "/") + "/posts/" + slug
+	}
+	base, err := url.Parse(source)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range s.outboundLinks(post.HTML, base) {
+		err := s.Send(ctx, source, target)
+		switch {
+		case err == nil:
+			s.registry.Inc("blog_api_webmentions_sent_total", "result", "sent")
+			fmt.Printf("Sent webmention for %s to %s\n", slug, target)
+		case errors.Is(err, ErrNoEndpoint):
+			s.registry.Inc("blog_api_webmentions_sent_total", "result", "no_endpoint")
+		default:
+			s.registry.Inc("blog_api_webmentions_sent_total", "result", "failed")
+			fmt.Printf("Error sending webmention for %s to %s: %v\n", slug, target, err)
+		}
+	}
+	return nil
+}
+
+// Send notifies target that source links to it
+func (s *Sender) Send(ctx context.Context, source, target string) error {
+	endpoint, err := s.Discover(ctx, target)
+	if err != nil {
+		return err
+	}
+
+	form := url.Values{"source": {source}, "target": {target}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, s.cfg.MaxBodySize))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("endpoint %s returned status %d", endpoint, resp.StatusCode)
+	}
+	return nil
+}
+
+// Discover finds the Webmention endpoint of target: the first advertised
+// in its Link headers, or else the first <link> or <a> with
+// rel="webmention" in its HTML
+func (s *Sender) Discover(ctx context.Context, target string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "text/html, */*;q=0.1")
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("fetching %s: status %d", target, resp.StatusCode)
+	}
+
+	// Redirects are followed, so relative endpoints resolve against the
+	// page finally reached
+	endpoint, ok := linkHeaderEndpoint(resp.Header, resp.Request.URL)
+	if !ok {
+		mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		if mediaType == "text/html" || mediaType == "application/xhtml+xml" {
+			body, err := io.ReadAll(io.LimitReader(resp.Body, s.cfg.MaxBodySize))
+			if err != nil {
+				return "", err
+			}
+			endpoint = parsePage(body, resp.Request.URL).endpoint
+		}
+	}
+
+	if endpoint == "" {
+		return "", ErrNoEndpoint
+	}
+	if !netutil.IsWebURL(endpoint) {
+		return "", fmt.Errorf("endpoint %q is not an http or https URL", endpoint)
+	}
+	return endpoint, nil
+}
+
+// outboundLinks returns the distinct http and https URLs a post's HTML
+// links to outside the site, in the order they appear
+func (s *Sender) outboundLinks(postHTML string, base *url.URL) []string {
+	site := strings.TrimRight(s.cfg.SiteURL, "/")
+
+	var links []string
+	seen := make(map[string]bool)
+	for _, link := range parsePage([]byte(postHTML), base).links {
+		link, _, _ = strings.Cut(link, "#")
+		key := normalizeURL(link)
+		if !netutil.IsWebURL(link) || seen[key] {
+			continue
+		}
+		if site != "" && (key == site || strings.HasPrefix(key, site+"/")) {
+			continue
+		}
+		seen[key] = true
+		links = append(links, link)
+	}
+	return links
+}
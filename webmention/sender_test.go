@@ -0,0 +1,103 @@
+package webmention
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"blog-api/metrics"
+	"blog-api/models"
+	"blog-api/netutil"
+)
+
+// stubPosts serves a single post
+type stubPosts struct {
+	post models.BlogPost
+}
+
+func (s stubPosts) GetPostBySlug(slug string) (models.BlogPost, error) {
+	if slug != s.post.Slug {
+		return models.BlogPost{}, errors.New("not found")
+	}
+	return s.post, nil
+}
+
+func TestSender_DiscoversAndNotifies(t *testing.T) {
+	var mu sync.Mutex
+	received := make(map[string]string)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/header":
+			w.Header().Set("Link", `<https://other.example/>; rel="me", </endpoint?from=header>; rel="webmention"`)
+			w.Write([]byte("plain"))
+		case "/html":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><head><link rel="stylesheet" href="/s.css"><link rel="webmention" href="endpoint?from=html"></head></html>`))
+		case "/moved":
+			http.Redirect(w, req, "/html", http.StatusFound)
+		case "/none":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><body>No endpoint</body></html>`))
+		case "/endpoint":
+			req.ParseForm()
+			mu.Lock()
+			received[req.URL.Query().Get("from")+" "+req.PostForm.Get("target")] = req.PostForm.Get("source")
+			mu.Unlock()
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	defer server.Close()
+
+	post := models.BlogPost{
+		Slug:         "hello",
+		CanonicalURL: "https://blog.example/posts/hello",
+		HTML: `<p><a href="` + server.URL + `/header">One</a> <a href="` + server.URL + `/moved#part">Two</a>
+<a href="` + server.URL + `/none">Three</a> <a href="/posts/other">Own post</a> <a href="` + server.URL + `/header">Again</a></p>`,
+	}
+	sender := NewSender(SenderConfig{SiteURL: "https://blog.example", AllowPrivate: true}, stubPosts{post}, metrics.NewRegistry())
+
+	if err := sender.SendPost(context.Background(), "hello"); err != nil {
+		t.Fatalf("SendPost failed: %v", err)
+	}
+
+	want := map[string]string{
+		"header " + server.URL + "/header": post.CanonicalURL,
+		"html " + server.URL + "/moved":    post.CanonicalURL,
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != len(want) {
+		t.Fatalf("Expected %d webmentions, got %v", len(want), received)
+	}
+	for key, source := range want {
+		if received[key] != source {
+			t.Errorf("Expected %q from %s, got %v", key, source, received)
+		}
+	}
+}
+
+func TestSender_Discover(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<a rel="nofollow webmention" href="">Send here</a>`))
+	}))
+	defer server.Close()
+
+	sender := NewSender(SenderConfig{AllowPrivate: true}, stubPosts{}, metrics.NewRegistry())
+	endpoint, err := sender.Discover(context.Background(), server.URL+"/page?x=1")
+	if err != nil || endpoint != server.URL+"/page?x=1" {
+		t.Errorf("Expected an empty href to mean the page itself, got %q, %v", endpoint, err)
+	}
+
+	guarded := NewSender(SenderConfig{}, stubPosts{}, metrics.NewRegistry())
+	if _, err := guarded.Discover(context.Background(), server.URL); !errors.Is(err, netutil.ErrPrivateAddress) {
+		t.Errorf("Expected loopback pages to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,410 @@
+package websub
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"blog-api/metrics"
+	"blog-api/netutil"
+	"blog-api/persist"
+	"blog-api/webhook"
+)
+
+// Subscription request errors
+var (
+	ErrInvalidMode     = errors.New("hub.mode must be subscribe or unsubscribe")
+	ErrInvalidCallback = errors.New("hub.callback must be an absolute http or https URL")
+	ErrUnknownTopic    = errors.New("hub.topic is not published by this hub")
+	ErrInvalidSecret   = errors.New("hub.secret must be shorter than 200 bytes")
+	ErrBusy            = errors.New("too many subscription requests are being verified; try again later")
+)
+
+// maxSecretLength is the limit on hub.secret set by the WebSub spec
+const maxSecretLength = 200
+
+// ContentFunc renders the current content of a topic
+type ContentFunc func() (contentType string, body []byte, err error)
+
+// Request is a subscription or unsubscription request
+type Request struct {
+	Mode         string
+	Topic        string
+	Callback     string
+	Secret       string
+	LeaseSeconds int
+}
+
+// Subscription is a verified subscriber to a topic
+type Subscription struct {
+	Topic     string    `json:"topic"`
+	Callback  string    `json:"callback"`
+	Secret    string    `json:"secret,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HubConfig configures a Hub
+type HubConfig struct {
+	// URL is the public URL of the hub endpoint
+	URL string
+	// StateFile persists subscriptions; "" keeps them in memory only
+	StateFile string
+	// DefaultLease is used when a subscriber does not ask for a lease
+	DefaultLease time.Duration
+	// MaxLease caps the lease a subscriber may ask for
+	MaxLease time.Duration
+	// Timeout bounds verification and content delivery requests
+	Timeout time.Duration
+	// MaxPending caps the subscription requests being verified at once;
+	// more are refused with ErrBusy
+	MaxPending int
+	// AllowPrivate lets callbacks on loopback and private addresses be
+	// contacted, which are refused by default so subscribers cannot use the
+	// hub to reach internal services
+	AllowPrivate bool
+}
+
+// Hub is a WebSub hub for the feeds published by this API. Subscribers are
+// verified before being added, expire when their lease runs out, and are
+// sent the full feed whenever it changes.
+type Hub struct {
+	cfg       HubConfig
+	client    *http.Client
+	registry  *metrics.Registry
+	notify    chan struct{}
+	verifying chan struct{}
+
+	mu            sync.Mutex
+	topics        map[string]ContentFunc
+	subscriptions map[string]*Subscription
+}
+
+// NewHub creates a Hub and loads any persisted subscriptions
+func NewHub(cfg HubConfig, registry *metrics.Registry) (*Hub, error) {
+	if cfg.MaxLease <= 0 {
+		cfg.MaxLease = 30 * 24 * time.Hour
+	}
+	if cfg.DefaultLease <= 0 || cfg.DefaultLease > cfg.MaxLease {
+		cfg.DefaultLease = min(10*24*time.Hour, cfg.MaxLease)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.MaxPending <= 0 {
+		cfg.MaxPending = 32
+	}
+
+	h := &Hub{
+		cfg:           cfg,
+		client:        netutil.NewClient(cfg.Timeout, cfg.AllowPrivate),
+		registry:      registry,
+		notify:        make(chan struct{}, 1),
+		verifying:     make(chan struct{}, cfg.MaxPending),
+		topics:        make(map[string]ContentFunc),
+		subscriptions: make(map[string]*Subscription),
+	}
+
+	if cfg.StateFile != "" {
+		var subs []*Subscription
+		if err := persist.LoadJSON(cfg.StateFile, &subs); err != nil {
+			return nil, fmt.Errorf("loading WebSub subscriptions: %w", err)
+		}
+		for _, sub := range subs {
+			h.subscriptions[subscriptionKey(sub.Topic, sub.Callback)] = sub
+		}
+	}
+
+	registry.Describe("blog_api_websub_deliveries_total", "WebSub content distributions by status")
+
+	return h, nil
+}
+
+// URL returns the public URL of the hub
+func (h *Hub) URL() string {
+	return h.cfg.URL
+}
+
+// AddTopic publishes topic through the hub, rendered by content
+func (h *Hub) AddTopic(topic string, content ContentFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.topics[topic] = content
+}
+
+// Subscriptions returns the active subscriptions
+func (h *Hub) Subscriptions() []Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	subs := make([]Subscription, 0, len(h.subscriptions))
+	for _, sub := range h.subscriptions {
+		if sub.ExpiresAt.After(now) {
+			subs = append(subs, *sub)
+		}
+	}
+	return subs
+}
+
+// HandleRequest validates a subscription request and verifies the
+// subscriber's intent in the background. While MaxPending requests are
+// being verified, others are refused with ErrBusy.
+func (h *Hub) HandleRequest(req Request) error {
+	if req.Mode != "subscribe" && req.Mode != "unsubscribe" {
+		return ErrInvalidMode
+	}
+
+	if !netutil.IsWebURL(req.Callback) {
+		return ErrInvalidCallback
+	}
+
+	h.mu.Lock()
+	_, known := h.topics[req.Topic]
+	h.mu.Unlock()
+	if !known {
+		return ErrUnknownTopic
+	}
+
+	if len(req.Secret) >= maxSecretLength {
+		return ErrInvalidSecret
+	}
+
+	lease := h.cfg.DefaultLease
+	if req.LeaseSeconds > 0 {
+		lease = time.Duration(req.LeaseSeconds) * time.Second
+	}
+	if lease > h.cfg.MaxLease {
+		lease = h.cfg.MaxLease
+	}
+
+	select {
+	case h.verifying <- struct{}{}:
+	default:
+		return ErrBusy
+	}
+	go func() {
+		defer func() { <-h.verifying }()
+		h.verify(req, lease)
+	}()
+
+	return nil
+}
+
+// Notify schedules the content of every topic to be sent to its
+// subscribers. Notifications made while one is pending are coalesced.
+func (h *Hub) Notify() {
+	select {
+	case h.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Run distributes content after each Notify and drops expired
+// subscriptions until ctx is cancelled
+func (h *Hub) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.pruneExpired()
+		case <-h.notify:
+			h.Publish(ctx)
+		}
+	}
+}
+
+// Publish sends the current content of every topic to its subscribers
+func (h *Hub) Publish(ctx context.Context) {
+	h.pruneExpired()
+
+	h.mu.Lock()
+	topics := make(map[string]ContentFunc, len(h.topics))
+	for topic, content := range h.topics {
+		topics[topic] = content
+	}
+	subs := make([]Subscription, 0, len(h.subscriptions))
+	for _, sub := range h.subscriptions {
+		subs = append(subs, *sub)
+	}
+	h.mu.Unlock()
+
+	for topic, content := range topics {
+		var contentType string
+		var body []byte
+		rendered := false
+
+		for _, sub := range subs {
+			if sub.Topic != topic {
+				continue
+			}
+
+			if !rendered {
+				var err error
+				if contentType, body, err = content(); err != nil {
+					fmt.Printf("Error rendering WebSub topic %s: %v\n", topic, err)
+					break
+				}
+				rendered = true
+			}
+
+			status := "succeeded"
+			if err := h.distribute(ctx, sub, contentType, body); err != nil {
+				fmt.Printf("Error delivering %s to %s: %v\n", topic, sub.Callback, err)
+				status = "failed"
+			}
+			h.registry.Inc("blog_api_websub_deliveries_total", "status", status)
+		}
+	}
+}
+
+// verify confirms the subscriber's intent by echoing a challenge through
+// its callback, and applies the request once it is confirmed
+func (h *Hub) verify(req Request, lease time.Duration) {
+	challenge := newChallenge()
+
+	query := url.Values{}
+	query.Set("hub.mode", req.Mode)
+	query.Set("hub.topic", req.Topic)
+	query.Set("hub.challenge", challenge)
+	if req.Mode == "subscribe" {
+		query.Set("hub.lease_seconds", strconv.Itoa(int(lease.Seconds())))
+	}
+
+	callback, _ := url.Parse(req.Callback)
+	if callback.RawQuery != "" {
+		callback.RawQuery += "&" + query.Encode()
+	} else {
+		callback.RawQuery = query.Encode()
+	}
+
+	resp, err := h.client.Get(callback.String())
+	if err != nil {
+		fmt.Printf("Error verifying WebSub %s for %s: %v\n", req.Mode, req.Callback, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || string(body) != challenge {
+		fmt.Printf("WebSub %s for %s was not confirmed\n", req.Mode, req.Callback)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := subscriptionKey(req.Topic, req.Callback)
+	if req.Mode == "unsubscribe" {
+		delete(h.subscriptions, key)
+	} else {
+		now := time.Now().UTC()
+		created := now
+		if existing, ok := h.subscriptions[key]; ok {
+			created = existing.CreatedAt
+		}
+		h.subscriptions[key] = &Subscription{
+			Topic:     req.Topic,
+			Callback:  req.Callback,
+			Secret:    req.Secret,
+			ExpiresAt: now.Add(lease),
+			CreatedAt: created,
+		}
+	}
+	h.save()
+}
+
+// distribute POSTs content to a single subscriber
+func (h *Hub) distribute(ctx context.Context, sub Subscription, contentType string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", sub.Callback, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Add("Link", fmt.Sprintf(`<%s>; rel="hub"`, h.cfg.URL))
+	req.Header.Add("Link", fmt.Sprintf(`<%s>; rel="self"`, sub.Topic))
+	if sub.Secret != "" {
+		req.Header.Set("X-Hub-Signature", webhook.Sign([]byte(sub.Secret), body))
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode == http.StatusGone {
+		// The subscriber has gone away for good
+		h.mu.Lock()
+		delete(h.subscriptions, subscriptionKey(sub.Topic, sub.Callback))
+		h.save()
+		h.mu.Unlock()
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
+
+// pruneExpired drops subscriptions whose lease has run out
+func (h *Hub) pruneExpired() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	removed := false
+	for key, sub := range h.subscriptions {
+		if !sub.ExpiresAt.After(now) {
+			delete(h.subscriptions, key)
+			removed = true
+		}
+	}
+	if removed {
+		h.save()
+	}
+}
+
+// save persists the subscriptions. The caller must hold h.mu.
+func (h *Hub) save() {
+	if h.cfg.StateFile == "" {
+		return
+	}
+
+	subs := make([]*Subscription, 0, len(h.subscriptions))
+	for _, sub := range h.subscriptions {
+		subs = append(subs, sub)
+	}
+
+	if err := persist.SaveJSON(h.cfg.StateFile, subs); err != nil {
+		fmt.Printf("Error saving WebSub subscriptions: %v\n", err)
+	}
+}
+
+// subscriptionKey identifies a subscription; a callback may subscribe to
+// several topics
+func subscriptionKey(topic, callback string) string {
+	return topic + " " + callback
+}
+
+// newChallenge returns a random verification challenge
+func newChallenge() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,185 @@
+package websub
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"blog-api/metrics"
+	"blog-api/webhook"
+)
+
+const topic = "https://blog.example/rss"
+
+// subscriber is a WebSub callback that confirms intent and records pushes
+type subscriber struct {
+	mu      sync.Mutex
+	confirm bool
+	pushes  []string
+}
+
+func (s *subscriber) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Method == "GET" {
+		if !s.confirm || r.URL.Query().Get("hub.topic") != topic {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, r.URL.Query().Get("hub.challenge"))
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	if err := webhook.Verify([]byte("secret"), body, r.Header.Get("X-Hub-Signature")); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.pushes = append(s.pushes, string(body))
+}
+
+// waitForSubscriptions polls until the hub has n active subscriptions
+func waitForSubscriptions(t *testing.T, h *Hub, n int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if len(h.Subscriptions()) == n {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Expected %d subscriptions, got %d", n, len(h.Subscriptions()))
+}
+
+func newTestHub(t *testing.T, stateFile string) *Hub {
+	return newTestHubWithConfig(t, HubConfig{StateFile: stateFile, AllowPrivate: true})
+}
+
+func newTestHubWithConfig(t *testing.T, cfg HubConfig) *Hub {
+	cfg.URL = "https://blog.example/hub"
+	cfg.MaxLease = time.Hour
+	h, err := NewHub(cfg, metrics.NewRegistry())
+	if err != nil {
+		t.Fatalf("NewHub failed: %v", err)
+	}
+	h.AddTopic(topic, func() (string, []byte, error) {
+		return "application/rss+xml", []byte("<rss/>"), nil
+	})
+	return h
+}
+
+func TestHub_SubscribeAndPublish(t *testing.T) {
+	sub := &subscriber{confirm: true}
+	server := httptest.NewServer(sub)
+	defer server.Close()
+
+	stateFile := filepath.Join(t.TempDir(), "subscriptions.json")
+	h := newTestHub(t, stateFile)
+
+	invalid := map[string]Request{
+		"mode":     {Mode: "watch", Topic: topic, Callback: server.URL},
+		"topic":    {Mode: "subscribe", Topic: "https://other.example/rss", Callback: server.URL},
+		"callback": {Mode: "subscribe", Topic: topic, Callback: "ftp://example.com"},
+	}
+	for name, req := range invalid {
+		if err := h.HandleRequest(req); err == nil {
+			t.Errorf("%s: expected the request to be rejected", name)
+		}
+	}
+
+	if err := h.HandleRequest(Request{Mode: "subscribe", Topic: topic, Callback: server.URL, Secret: "secret", LeaseSeconds: 86400}); err != nil {
+		t.Fatalf("HandleRequest failed: %v", err)
+	}
+	waitForSubscriptions(t, h, 1)
+
+	if lease := time.Until(h.Subscriptions()[0].ExpiresAt); lease > time.Hour {
+		t.Errorf("Expected the lease to be capped at an hour, got %v", lease)
+	}
+
+	h.Publish(context.Background())
+	sub.mu.Lock()
+	if len(sub.pushes) != 1 || sub.pushes[0] != "<rss/>" {
+		t.Errorf("Unexpected pushes %v", sub.pushes)
+	}
+	sub.mu.Unlock()
+
+	// Subscriptions survive a restart
+	if n := len(newTestHub(t, stateFile).Subscriptions()); n != 1 {
+		t.Errorf("Expected 1 persisted subscription, got %d", n)
+	}
+
+	h.HandleRequest(Request{Mode: "unsubscribe", Topic: topic, Callback: server.URL})
+	waitForSubscriptions(t, h, 0)
+}
+
+func TestHub_UnconfirmedAndExpired(t *testing.T) {
+	sub := &subscriber{}
+	server := httptest.NewServer(sub)
+	defer server.Close()
+
+	h := newTestHub(t, "")
+
+	h.HandleRequest(Request{Mode: "subscribe", Topic: topic, Callback: server.URL})
+	time.Sleep(100 * time.Millisecond)
+	if n := len(h.Subscriptions()); n != 0 {
+		t.Errorf("Expected an unconfirmed subscription to be ignored, got %d", n)
+	}
+
+	h.subscriptions[subscriptionKey(topic, server.URL)] = &Subscription{
+		Topic: topic, Callback: server.URL, ExpiresAt: time.Now().Add(-time.Minute),
+	}
+	h.Publish(context.Background())
+	if len(sub.pushes) != 0 || len(h.subscriptions) != 0 {
+		t.Error("Expected an expired subscription to be dropped without a push")
+	}
+}
+
+func TestHub_RefusesPrivateCallbacks(t *testing.T) {
+	sub := &subscriber{confirm: true}
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sub.mu.Lock()
+		requests++
+		sub.mu.Unlock()
+		sub.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	h := newTestHubWithConfig(t, HubConfig{})
+
+	if err := h.HandleRequest(Request{Mode: "subscribe", Topic: topic, Callback: server.URL}); err != nil {
+		t.Fatalf("HandleRequest failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if requests != 0 || len(h.Subscriptions()) != 0 {
+		t.Errorf("Expected a loopback callback not to be contacted, got %d requests", requests)
+	}
+}
+
+func TestHub_LimitsPendingVerifications(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		io.WriteString(w, r.URL.Query().Get("hub.challenge"))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	h := newTestHubWithConfig(t, HubConfig{MaxPending: 1, AllowPrivate: true})
+
+	if err := h.HandleRequest(Request{Mode: "subscribe", Topic: topic, Callback: server.URL + "/a"}); err != nil {
+		t.Fatalf("HandleRequest failed: %v", err)
+	}
+	if err := h.HandleRequest(Request{Mode: "subscribe", Topic: topic, Callback: server.URL + "/b"}); !errors.Is(err, ErrBusy) {
+		t.Errorf("Expected ErrBusy while a verification is pending, got %v", err)
+	}
+}